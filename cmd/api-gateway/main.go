@@ -1,16 +1,21 @@
 package main
 
 import (
+	"bufio"
 	"context"
+	"encoding/json"
 	"fmt"
 	"log"
+	"net"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
 
+	"learning/internal/auth"
 	"learning/internal/common"
 	graphqlserver "learning/internal/graphql"
 	"learning/internal/order"
@@ -30,33 +35,61 @@ func main() {
 	config := common.LoadGatewayConfig()
 	log.Printf("Starting API Gateway on %s", config.GetHTTPAddress())
 
+	// Load the keys tokens are verified with, for GraphQL's @auth and the
+	// JWK Set other services can fetch them from
+	verifier, err := common.LoadVerifier(config)
+	if err != nil {
+		log.Fatalf("Failed to load auth keys: %v", err)
+	}
+
 	// Create context
 	ctx := context.Background()
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
-	// Create gRPC-Gateway mux
-	mux := runtime.NewServeMux()
+	// Create gRPC-Gateway mux, passing idempotency keys through to the services
+	mux := runtime.NewServeMux(
+		runtime.WithIncomingHeaderMatcher(incomingHeaderMatcher),
+		runtime.WithOutgoingHeaderMatcher(outgoingHeaderMatcher),
+	)
 
-	// Setup service connections
+	// Setup service connections shared by the REST proxy and GraphQL
 	opts := []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}
 
+	userConn, err := grpc.Dial(config.UserServiceAddress, opts...)
+	if err != nil {
+		log.Fatalf("Failed to connect to user service: %v", err)
+	}
+	defer userConn.Close()
+
+	productConn, err := grpc.Dial(config.ProductServiceAddress, opts...)
+	if err != nil {
+		log.Fatalf("Failed to connect to product service: %v", err)
+	}
+	defer productConn.Close()
+
+	orderConn, err := grpc.Dial(config.OrderServiceAddress, opts...)
+	if err != nil {
+		log.Fatalf("Failed to connect to order service: %v", err)
+	}
+	defer orderConn.Close()
+
 	// Register User Service
-	err := userpb.RegisterUserServiceHandlerFromEndpoint(ctx, mux, config.UserServiceAddress, opts)
+	err = userpb.RegisterUserServiceHandler(ctx, mux, userConn)
 	if err != nil {
 		log.Fatalf("Failed to register user service handler: %v", err)
 	}
 	log.Printf("Registered User  Service proxy to %s", config.UserServiceAddress)
 
 	// Register Product Service
-	err = productpb.RegisterProductServiceHandlerFromEndpoint(ctx, mux, config.ProductServiceAddress, opts)
+	err = productpb.RegisterProductServiceHandler(ctx, mux, productConn)
 	if err != nil {
 		log.Fatalf("Failed to register product service handler: %v", err)
 	}
 	log.Printf("Registered Product Service proxy to %s", config.ProductServiceAddress)
 
 	// Register Order Service
-	err = orderpb.RegisterOrderServiceHandlerFromEndpoint(ctx, mux, config.OrderServiceAddress, opts)
+	err = orderpb.RegisterOrderServiceHandler(ctx, mux, orderConn)
 	if err != nil {
 		log.Fatalf("Failed to register order service handler: %v", err)
 	}
@@ -68,22 +101,53 @@ func main() {
 	// Add health check endpoint
 	mainMux.HandleFunc("/health", healthCheckHandler)
 
+	// Publish the public keys tokens are verified with
+	mainMux.Handle("/.well-known/jwks.json", jwksHandler(verifier))
+
 	// Add gRPC-Gateway routes under /api/
 	mainMux.Handle("/api/", mux)
 
 	// Setup GraphQL if enabled
 	if config.GraphQLEnabled {
-		// Create repositories (using in-memory for demo)
-		userRepo := user.NewInMemoryRepository()
-		productRepo := product.NewInMemoryRepository()
-		orderRepo := order.NewInMemoryRepository()
+		// Create repositories backed by the gRPC services, so GraphQL and REST share data
+		userRepo := user.NewGRPCRepository(userConn)
+		productRepo := product.NewGRPCRepository(productConn)
+		orderRepo := order.NewGRPCRepository(orderConn)
+		orderService := order.NewGRPCService(orderConn)
 
 		// Create GraphQL server
 		gqlConfig := &graphqlserver.Config{
-			PlaygroundEnabled:    config.GraphQLPlaygroundEnabled,
-			IntrospectionEnabled: true,
+			PlaygroundEnabled:      config.GraphQLPlaygroundEnabled,
+			IntrospectionEnabled:   true,
+			SubscriptionBufferSize: config.GraphQLSubscriptionBuffer,
+			KeepAliveInterval:      config.GraphQLKeepAliveInterval,
+			WriteTimeout:           config.GraphQLWriteTimeout,
+			MaxDepth:               config.GraphQLMaxDepth,
+			MaxComplexity:          config.GraphQLMaxComplexity,
+			OperationTimeout:       config.GraphQLOperationTimeout,
+
+			PersistedQueryCacheSize: config.GraphQLPersistedQueryCache,
+		}
+		if config.GraphQLTrustedDocuments != "" {
+			documents, err := graphqlserver.LoadTrustedDocuments(config.GraphQLTrustedDocuments)
+			if err != nil {
+				log.Fatalf("Failed to load trusted documents: %v", err)
+			}
+			gqlConfig.TrustedDocuments = documents
+			log.Printf("GraphQL limited to %d trusted documents", len(documents))
 		}
-		gqlServer := graphqlserver.NewServer(gqlConfig, userRepo, productRepo, orderRepo)
+
+		// Verify callers' tokens for @auth, and sign the gateway's own
+		// watches of every order
+		credentials, err := common.LoadServiceCredentials(config, "api-gateway", verifier)
+		if err != nil {
+			log.Fatalf("Failed to load auth keys: %v", err)
+		}
+		gqlConfig.Verifier = verifier
+		gqlConfig.Credentials = credentials
+
+		gqlServer := graphqlserver.NewServer(gqlConfig, userRepo, productRepo, orderRepo, orderService)
+		defer gqlServer.Close()
 
 		// Add GraphQL endpoints
 		mainMux.Handle("/graphql", gqlServer.Handler())
@@ -116,6 +180,7 @@ func main() {
 	if config.GraphQLEnabled {
 		log.Printf("  GraphQL:")
 		log.Printf("    Endpoint: %s/graphql", config.GetHTTPAddress())
+		log.Printf("    Subscriptions: ws://%s/graphql", config.GetHTTPAddress())
 		if config.GraphQLPlaygroundEnabled {
 			log.Printf("    Playground: %s/playground", config.GetHTTPAddress())
 		}
@@ -126,12 +191,30 @@ func main() {
 	}
 }
 
+// incomingHeaderMatcher forwards the Idempotency-Key header as gRPC metadata
+// in addition to the headers grpc-gateway forwards by default
+func incomingHeaderMatcher(key string) (string, bool) {
+	if strings.EqualFold(key, common.IdempotencyKeyHeader) {
+		return common.IdempotencyKeyHeader, true
+	}
+	return runtime.DefaultHeaderMatcher(key)
+}
+
+// outgoingHeaderMatcher tells clients when a response was replayed for an
+// idempotency key
+func outgoingHeaderMatcher(key string) (string, bool) {
+	if key == common.IdempotentReplayHeader {
+		return "Idempotent-Replayed", true
+	}
+	return fmt.Sprintf("%s%s", runtime.MetadataHeaderPrefix, key), true
+}
+
 // corsMiddleware adds CORS headers
 func corsMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, Idempotency-Key")
 
 		// Handle preflight requests
 		if r.Method == "OPTIONS" {
@@ -168,9 +251,47 @@ func (rw *responseWriter) WriteHeader(code int) {
 	rw.ResponseWriter.WriteHeader(code)
 }
 
+// Hijack lets WebSocket upgrades take over the connection
+func (rw *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, brw, err := http.NewResponseController(rw.ResponseWriter).Hijack()
+	if err == nil {
+		rw.statusCode = http.StatusSwitchingProtocols
+	}
+	return conn, brw, err
+}
+
+// Unwrap returns the wrapped ResponseWriter, for http.ResponseController
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
 // healthCheckHandler handles health check requests
 func healthCheckHandler(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	fmt.Fprintf(w, `{"status":"healthy","timestamp":"%s"}`, time.Now().Format(time.RFC3339))
 }
+
+// jwksHandler serves the public keys tokens are verified with as a JWK Set,
+// empty when authorization is disabled
+func jwksHandler(verifier *auth.Verifier) http.Handler {
+	set := auth.JWKSet{Keys: []auth.JWK{}}
+	if verifier != nil {
+		set = verifier.JWKS()
+	}
+	body, err := json.Marshal(set)
+	if err != nil {
+		log.Fatalf("Failed to encode JWK set: %v", err)
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "application/jwk-set+json")
+		w.Header().Set("Cache-Control", "public, max-age=300")
+		w.Write(body)
+	})
+}
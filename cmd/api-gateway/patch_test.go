@@ -0,0 +1,147 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+
+	"learning/internal/product"
+	"learning/internal/user"
+	productpb "learning/pkg/product/pb"
+	userpb "learning/pkg/user/pb"
+)
+
+// newTestGateway serves the user and product services, backed by in-memory
+// repositories, through the REST gateway
+func newTestGateway(t *testing.T) (http.Handler, *user.Service, *product.Service) {
+	t.Helper()
+
+	users := user.NewService(user.NewInMemoryRepository())
+	products := product.NewService(product.NewInMemoryRepository())
+
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer()
+	userpb.RegisterUserServiceServer(server, user.NewHandler(users, nil))
+	productpb.RegisterProductServiceServer(server, product.NewHandler(products))
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	mux := runtime.NewServeMux(runtime.WithIncomingHeaderMatcher(incomingHeaderMatcher))
+	if err := userpb.RegisterUserServiceHandler(context.Background(), mux, conn); err != nil {
+		t.Fatalf("register user handler: %v", err)
+	}
+	if err := productpb.RegisterProductServiceHandler(context.Background(), mux, conn); err != nil {
+		t.Fatalf("register product handler: %v", err)
+	}
+	return mux, users, products
+}
+
+// send makes a request to the gateway and decodes its JSON response
+func send(t *testing.T, handler http.Handler, method, path, body string, resp any) int {
+	t.Helper()
+
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+
+	if resp != nil && recorder.Code == http.StatusOK {
+		if err := json.Unmarshal(recorder.Body.Bytes(), resp); err != nil {
+			t.Fatalf("decode %s %s response: %v", method, path, err)
+		}
+	}
+	return recorder.Code
+}
+
+func TestPatchProductChangesOnlyTheFieldsSent(t *testing.T) {
+	gateway, _, products := newTestGateway(t)
+	created, err := products.CreateProduct(context.Background(), "Lamp", "A desk lamp", "home", 20, 5)
+	if err != nil {
+		t.Fatalf("create product: %v", err)
+	}
+
+	var resp struct {
+		Product struct {
+			Name        string  `json:"name"`
+			Description string  `json:"description"`
+			Category    string  `json:"category"`
+			Price       float64 `json:"price"`
+			Stock       int32   `json:"stock"`
+		} `json:"product"`
+	}
+	if code := send(t, gateway, http.MethodPatch, "/api/v1/products/"+created.ID, `{"price": 25.5}`, &resp); code != http.StatusOK {
+		t.Fatalf("PATCH returned %d", code)
+	}
+
+	got := resp.Product
+	if got.Price != 25.5 {
+		t.Errorf("price = %v, want 25.5", got.Price)
+	}
+	if got.Name != "Lamp" || got.Description != "A desk lamp" || got.Category != "home" || got.Stock != 5 {
+		t.Errorf("PATCH changed fields it didn't send: %+v", got)
+	}
+}
+
+func TestPatchUserChangesOnlyTheFieldsSent(t *testing.T) {
+	gateway, users, _ := newTestGateway(t)
+	created, err := users.CreateUser(context.Background(), "Ann", "ann@example.com", "555-0100")
+	if err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+
+	var resp struct {
+		User struct {
+			Name  string `json:"name"`
+			Email string `json:"email"`
+			Phone string `json:"phone"`
+		} `json:"user"`
+	}
+	if code := send(t, gateway, http.MethodPatch, "/api/v1/users/"+created.ID, `{"phone": "555-0199"}`, &resp); code != http.StatusOK {
+		t.Fatalf("PATCH returned %d", code)
+	}
+
+	got := resp.User
+	if got.Phone != "555-0199" || got.Name != "Ann" || got.Email != "ann@example.com" {
+		t.Errorf("PATCH result = %+v, want only the phone changed", got)
+	}
+}
+
+func TestPatchWithoutFieldsIsRefused(t *testing.T) {
+	gateway, _, products := newTestGateway(t)
+	created, err := products.CreateProduct(context.Background(), "Lamp", "A desk lamp", "home", 20, 5)
+	if err != nil {
+		t.Fatalf("create product: %v", err)
+	}
+
+	if code := send(t, gateway, http.MethodPatch, "/api/v1/products/"+created.ID, `{}`, nil); code != http.StatusBadRequest {
+		t.Errorf("empty PATCH returned %d, want %d", code, http.StatusBadRequest)
+	}
+
+	product, err := products.GetProduct(context.Background(), created.ID)
+	if err != nil {
+		t.Fatalf("get product: %v", err)
+	}
+	if product.Name != "Lamp" || product.Price != 20 {
+		t.Errorf("empty PATCH changed the product: %+v", product)
+	}
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+
+	"learning/internal/common"
+	"learning/internal/migrations"
+)
+
+const usage = `Usage: migrate [flags] <command>
+
+Commands:
+  up            apply all pending migrations
+  down N        roll back the N most recent migrations
+  status        show applied and pending migrations
+  create NAME   create a new empty migration pair (requires -service)
+
+Flags:
+`
+
+func main() {
+	service := flag.String("service", "all", "service whose schema to migrate: user, product, order or all")
+	databaseURL := flag.String("database", "", "database URL (defaults to the service's configured URL)")
+	dir := flag.String("dir", "internal/migrations", "migrations source directory, used by create")
+	flag.Usage = func() {
+		fmt.Fprint(flag.CommandLine.Output(), usage)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	services := migrations.Services
+	if *service != "all" {
+		services = []string{*service}
+	}
+
+	command, args := flag.Arg(0), flag.Args()[1:]
+
+	if command == "create" {
+		if len(args) != 1 || *service == "all" {
+			log.Fatalf("create needs a NAME and a single -service")
+		}
+		up, down, err := migrations.Create(*dir, *service, args[0])
+		if err != nil {
+			log.Fatalf("Failed to create migration: %v", err)
+		}
+		fmt.Printf("Created %s\nCreated %s\n", up, down)
+		return
+	}
+
+	ctx := context.Background()
+	for _, svc := range services {
+		if err := run(ctx, svc, databaseFor(svc, *databaseURL), command, args); err != nil {
+			log.Fatalf("%s: %v", svc, err)
+		}
+	}
+}
+
+// run executes a migration command against one service's database
+func run(ctx context.Context, service, databaseURL, command string, args []string) error {
+	if databaseURL == "" {
+		return fmt.Errorf("no database configured, set DATABASE_URL or -database")
+	}
+
+	db, err := common.OpenDatabase(databaseURL)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	migrator, err := migrations.NewMigrator(db, service)
+	if err != nil {
+		return err
+	}
+
+	switch command {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		for _, m := range applied {
+			fmt.Printf("%s: applied %04d_%s\n", service, m.Version, m.Name)
+		}
+		if err == nil && len(applied) == 0 {
+			fmt.Printf("%s: up to date\n", service)
+		}
+		return err
+
+	case "down":
+		if len(args) != 1 {
+			return fmt.Errorf("down needs the number of migrations to roll back")
+		}
+		n, err := strconv.Atoi(args[0])
+		if err != nil || n <= 0 {
+			return fmt.Errorf("invalid migration count %q", args[0])
+		}
+		reverted, err := migrator.Down(ctx, n)
+		for _, m := range reverted {
+			fmt.Printf("%s: rolled back %04d_%s\n", service, m.Version, m.Name)
+		}
+		return err
+
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		for _, s := range statuses {
+			state := "pending"
+			if s.Applied {
+				state = "applied " + s.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%s: %04d_%-30s %s\n", service, s.Version, s.Name, state)
+		}
+		return nil
+
+	default:
+		return fmt.Errorf("unknown command %q", command)
+	}
+}
+
+// databaseFor returns the database URL a service is configured with
+func databaseFor(service, override string) string {
+	if override != "" {
+		return override
+	}
+
+	switch service {
+	case "user":
+		return common.LoadUserServiceConfig().DatabaseURL
+	case "product":
+		return common.LoadProductServiceConfig().DatabaseURL
+	case "order":
+		return common.LoadOrderServiceConfig().DatabaseURL
+	default:
+		return common.LoadConfig().DatabaseURL
+	}
+}
@@ -1,9 +1,15 @@
 package main
 
 import (
+	"context"
 	"log"
+	"path/filepath"
 
+	"google.golang.org/grpc"
+
+	"learning/internal/auth"
 	"learning/internal/common"
+	"learning/internal/migrations"
 	"learning/internal/order"
 	pb "learning/pkg/order/pb"
 )
@@ -17,35 +23,72 @@ func main() {
 	config := common.LoadOrderServiceConfig()
 	log.Printf("Starting Order Service on %s", config.GetGRPCAddress())
 
-	// Initialize external service clients
-	userClient, err := order.NewUserServiceClient(config.UserServiceAddress)
+	// Load the keys tokens are verified with
+	verifier, err := common.LoadVerifier(config)
+	if err != nil {
+		log.Fatalf("Failed to load auth keys: %v", err)
+	}
+
+	// Initialize external service clients, which call as the order service
+	var dialOpts []grpc.DialOption
+	credentials, err := common.LoadServiceCredentials(config, order.ServiceName, verifier)
+	if err != nil {
+		log.Fatalf("Failed to load auth keys: %v", err)
+	}
+	if credentials != nil {
+		dialOpts = append(dialOpts, grpc.WithPerRPCCredentials(credentials))
+	}
+
+	userClient, err := order.NewUserServiceClient(config.UserServiceAddress, dialOpts...)
 	if err != nil {
 		log.Fatalf("Failed to connect to user service: %v", err)
 	}
 	defer userClient.Close()
 
-	productClient, err := order.NewProductServiceClient(config.ProductServiceAddress)
+	productClient, err := order.NewProductServiceClient(config.ProductServiceAddress, dialOpts...)
 	if err != nil {
 		log.Fatalf("Failed to connect to product service: %v", err)
 	}
 	defer productClient.Close()
 
-	// Initialize repository
-	repo := order.NewInMemoryRepository()
+	// Initialize repository and saga store
+	repo, sagas, closeRepo, err := newRepository(config)
+	if err != nil {
+		log.Fatalf("Failed to initialize repository: %v", err)
+	}
+	defer closeRepo()
+
+	// Announce order changes to order watchers
+	notifier := order.NewNotifyingRepository(repo, order.ChangeBufferSize)
+	repo = notifier
 
 	// Initialize service with external clients
-	service := order.NewService(repo, userClient, productClient)
+	service := order.NewService(repo, sagas, userClient, productClient)
 	defer service.Close()
 
+	// Finish order sagas interrupted by a previous shutdown before serving
+	if err := service.RecoverSagas(context.Background()); err != nil {
+		log.Fatalf("Failed to recover order sagas: %v", err)
+	}
+
 	// Initialize gRPC handler
 	handler := order.NewHandler(service)
 
 	// Create gRPC server
-	server := common.NewGRPCServer(config.GetGRPCAddress())
+	// Check callers against the service's role policy
+	authorizer := auth.NewAuthorizer(verifier, order.Policy)
+	// Replay responses to retried requests that carry an idempotency key
+	idempotency := common.NewIdempotencyStore(config.IdempotencyTTL, config.IdempotencyMaxKeys)
+	server := common.NewGRPCServer(config.GetGRPCAddress(), authorizer,
+		idempotency.UnaryInterceptor(pb.OrderService_CreateOrder_FullMethodName),
+	)
 
 	// Register service
 	pb.RegisterOrderServiceServer(server.GetServer(), handler)
 
+	// End order watches so shutdown doesn't wait on them
+	server.OnShutdown(notifier.Close)
+
 	// Set service as healthy
 	server.SetHealthy("order")
 
@@ -54,3 +97,46 @@ func main() {
 		log.Fatalf("Failed to start server: %v", err)
 	}
 }
+
+// newRepository picks the storage backend from the database URL scheme,
+// falling back to in-memory storage (file-backed when a data directory is
+// configured) when no URL is configured. Orders and sagas share the backend.
+func newRepository(config *common.Config) (order.Repository, order.SagaStore, func(), error) {
+	if config.DatabaseURL == "" && config.DataDir != "" {
+		dir := filepath.Join(config.DataDir, "order")
+		repo, err := order.NewFileBackedRepository(dir)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		sagas, err := order.NewFileBackedSagaStore(dir)
+		if err != nil {
+			repo.Close()
+			return nil, nil, nil, err
+		}
+		log.Printf("Using in-memory storage persisted to %s", config.DataDir)
+		return repo, sagas, func() { repo.Close(); sagas.Close() }, nil
+	}
+
+	if config.DatabaseURL == "" {
+		log.Printf("Using in-memory storage")
+		return order.NewInMemoryRepository(), order.NewInMemorySagaStore(), func() {}, nil
+	}
+
+	db, err := common.OpenDatabase(config.DatabaseURL)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	// Refuse to start on an outdated schema unless auto-migrate is enabled
+	migrator, err := migrations.NewMigrator(db, "order")
+	if err == nil {
+		err = migrator.EnsureCurrent(context.Background(), config.AutoMigrate)
+	}
+	if err != nil {
+		db.Close()
+		return nil, nil, nil, err
+	}
+
+	log.Printf("Using %s storage", db.Dialect)
+	return order.NewSQLRepository(db), order.NewSQLSagaStore(db), func() { db.Close() }, nil
+}
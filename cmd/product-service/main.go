@@ -1,9 +1,13 @@
 package main
 
 import (
+	"context"
 	"log"
+	"path/filepath"
 
+	"learning/internal/auth"
 	"learning/internal/common"
+	"learning/internal/migrations"
 	"learning/internal/product"
 	pb "learning/pkg/product/pb"
 )
@@ -17,8 +21,29 @@ func main() {
 	config := common.LoadProductServiceConfig()
 	log.Printf("Starting Product Service on %s", config.GetGRPCAddress())
 
+	// Load the keys tokens are verified with
+	verifier, err := common.LoadVerifier(config)
+	if err != nil {
+		log.Fatalf("Failed to load auth keys: %v", err)
+	}
+
 	// Initialize repository
-	repo := product.NewInMemoryRepository()
+	repo, closeRepo, err := newRepository(config)
+	if err != nil {
+		log.Fatalf("Failed to initialize repository: %v", err)
+	}
+	defer closeRepo()
+
+	// Announce product changes to stock watchers
+	notifier := product.NewNotifyingRepository(repo, product.ChangeBufferSize)
+	repo = notifier
+
+	// Release stock held by expired reservations in the background
+	sweepCtx, stopSweeper := context.WithCancel(context.Background())
+	defer stopSweeper()
+	if expirer, ok := repo.(product.ReservationExpirer); ok {
+		go product.RunReservationSweeper(sweepCtx, expirer, product.ReservationSweepInterval)
+	}
 
 	// Initialize service
 	service := product.NewService(repo)
@@ -27,11 +52,20 @@ func main() {
 	handler := product.NewHandler(service)
 
 	// Create gRPC server
-	server := common.NewGRPCServer(config.GetGRPCAddress())
+	// Check callers against the service's role policy
+	authorizer := auth.NewAuthorizer(verifier, product.Policy)
+	// Replay responses to retried requests that carry an idempotency key
+	idempotency := common.NewIdempotencyStore(config.IdempotencyTTL, config.IdempotencyMaxKeys)
+	server := common.NewGRPCServer(config.GetGRPCAddress(), authorizer,
+		idempotency.UnaryInterceptor(pb.ProductService_UpdateStock_FullMethodName),
+	)
 
 	// Register service
 	pb.RegisterProductServiceServer(server.GetServer(), handler)
 
+	// End stock watches so shutdown doesn't wait on them
+	server.OnShutdown(notifier.Close)
+
 	// Set service as healthy
 	server.SetHealthy("product")
 
@@ -40,3 +74,40 @@ func main() {
 		log.Fatalf("Failed to start server: %v", err)
 	}
 }
+
+// newRepository picks the storage backend from the database URL scheme,
+// falling back to in-memory storage (file-backed when a data directory is
+// configured) when no URL is configured
+func newRepository(config *common.Config) (product.Repository, func(), error) {
+	if config.DatabaseURL == "" && config.DataDir != "" {
+		repo, err := product.NewFileBackedRepository(filepath.Join(config.DataDir, "product"))
+		if err != nil {
+			return nil, nil, err
+		}
+		log.Printf("Using in-memory storage persisted to %s", config.DataDir)
+		return repo, func() { repo.Close() }, nil
+	}
+
+	if config.DatabaseURL == "" {
+		log.Printf("Using in-memory storage")
+		return product.NewInMemoryRepository(), func() {}, nil
+	}
+
+	db, err := common.OpenDatabase(config.DatabaseURL)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Refuse to start on an outdated schema unless auto-migrate is enabled
+	migrator, err := migrations.NewMigrator(db, "product")
+	if err == nil {
+		err = migrator.EnsureCurrent(context.Background(), config.AutoMigrate)
+	}
+	if err != nil {
+		db.Close()
+		return nil, nil, err
+	}
+
+	log.Printf("Using %s storage", db.Dialect)
+	return product.NewSQLRepository(db), func() { db.Close() }, nil
+}
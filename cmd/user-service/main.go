@@ -1,9 +1,13 @@
 package main
 
 import (
+	"context"
 	"log"
+	"path/filepath"
 
+	"learning/internal/auth"
 	"learning/internal/common"
+	"learning/internal/migrations"
 	"learning/internal/user"
 	pb "learning/pkg/user/pb"
 )
@@ -17,17 +21,44 @@ func main() {
 	config := common.LoadUserServiceConfig()
 	log.Printf("Starting User Service on %s", config.GetGRPCAddress())
 
+	// Load the keys tokens are verified with
+	verifier, err := common.LoadVerifier(config)
+	if err != nil {
+		log.Fatalf("Failed to load auth keys: %v", err)
+	}
+
+	// Load the key access tokens are signed with; with authorization on,
+	// logins are the only way to get a token, so one is required
+	signer, err := common.LoadSigner(config, verifier)
+	if err != nil {
+		log.Fatalf("Failed to load auth keys: %v", err)
+	}
+	if signer == nil && verifier != nil {
+		log.Fatalf("user-service needs AUTH_PRIVATE_KEY_FILE to issue access tokens")
+	}
+
 	// Initialize repository
-	repo := user.NewInMemoryRepository()
+	repo, sessions, closeRepo, err := newRepository(config)
+	if err != nil {
+		log.Fatalf("Failed to initialize repository: %v", err)
+	}
+	defer closeRepo()
 
 	// Initialize service
 	service := user.NewService(repo)
+	authenticator := user.NewAuthenticator(service, repo, sessions, signer, config.AuthAccessTokenTTL, config.AuthRefreshTokenTTL)
 
 	// Initialize gRPC handler
-	handler := user.NewHandler(service)
+	handler := user.NewHandler(service, authenticator)
 
 	// Create gRPC server
-	server := common.NewGRPCServer(config.GetGRPCAddress())
+	// Check callers against the service's role policy
+	authorizer := auth.NewAuthorizer(verifier, user.Policy)
+	// Replay responses to retried requests that carry an idempotency key
+	idempotency := common.NewIdempotencyStore(config.IdempotencyTTL, config.IdempotencyMaxKeys)
+	server := common.NewGRPCServer(config.GetGRPCAddress(), authorizer,
+		idempotency.UnaryInterceptor(pb.UserService_CreateUser_FullMethodName),
+	)
 
 	// Register service
 	pb.RegisterUserServiceServer(server.GetServer(), handler)
@@ -40,3 +71,46 @@ func main() {
 		log.Fatalf("Failed to start server: %v", err)
 	}
 }
+
+// newRepository picks the storage backend from the database URL scheme,
+// falling back to in-memory storage (file-backed when a data directory is
+// configured) when no URL is configured
+func newRepository(config *common.Config) (user.Repository, user.SessionStore, func(), error) {
+	if config.DatabaseURL == "" && config.DataDir != "" {
+		dir := filepath.Join(config.DataDir, "user")
+		repo, err := user.NewFileBackedRepository(dir)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		sessions, err := user.NewFileBackedSessionStore(dir)
+		if err != nil {
+			repo.Close()
+			return nil, nil, nil, err
+		}
+		log.Printf("Using in-memory storage persisted to %s", config.DataDir)
+		return repo, sessions, func() { repo.Close(); sessions.Close() }, nil
+	}
+
+	if config.DatabaseURL == "" {
+		log.Printf("Using in-memory storage")
+		return user.NewInMemoryRepository(), user.NewInMemorySessionStore(), func() {}, nil
+	}
+
+	db, err := common.OpenDatabase(config.DatabaseURL)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	// Refuse to start on an outdated schema unless auto-migrate is enabled
+	migrator, err := migrations.NewMigrator(db, "user")
+	if err == nil {
+		err = migrator.EnsureCurrent(context.Background(), config.AutoMigrate)
+	}
+	if err != nil {
+		db.Close()
+		return nil, nil, nil, err
+	}
+
+	log.Printf("Using %s storage", db.Dialect)
+	return user.NewSQLRepository(db), user.NewSQLSessionStore(db), func() { db.Close() }, nil
+}
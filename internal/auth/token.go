@@ -0,0 +1,308 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Tokens are JWTs signed with Ed25519 keys (the EdDSA algorithm of RFC 8037)
+const algorithm = "EdDSA"
+
+// header is the JOSE header of a token
+type header struct {
+	Algorithm string `json:"alg"`
+	Type      string `json:"typ"`
+	KeyID     string `json:"kid"`
+}
+
+// payload is the claims of a token as they are encoded
+type payload struct {
+	Issuer    string `json:"iss"`
+	Subject   string `json:"sub"`
+	Roles     []Role `json:"roles"`
+	IssuedAt  int64  `json:"iat"`
+	ExpiresAt int64  `json:"exp"`
+}
+
+// KeyID identifies a public key by its JWK thumbprint (RFC 7638), which
+// tokens name in their header
+func KeyID(key ed25519.PublicKey) string {
+	// The members are in lexicographic order, as the thumbprint requires
+	jwk := fmt.Sprintf(`{"crv":"Ed25519","kty":"OKP","x":"%s"}`, base64.RawURLEncoding.EncodeToString(key))
+	sum := sha256.Sum256([]byte(jwk))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// Signer issues tokens
+type Signer struct {
+	key    ed25519.PrivateKey
+	keyID  string
+	issuer string
+}
+
+// NewSigner creates a signer issuing tokens from issuer, signed with key
+func NewSigner(key ed25519.PrivateKey, issuer string) *Signer {
+	return &Signer{
+		key:    key,
+		keyID:  KeyID(key.Public().(ed25519.PublicKey)),
+		issuer: issuer,
+	}
+}
+
+// LoadSigner creates a signer with the Ed25519 private key of a PEM file,
+// as written by `openssl genpkey -algorithm ed25519`
+func LoadSigner(path, issuer string) (*Signer, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read private key: %w", err)
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != "PRIVATE KEY" {
+		return nil, fmt.Errorf("%s holds no PEM private key", path)
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse private key %s: %w", path, err)
+	}
+	edKey, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("private key %s is not an Ed25519 key", path)
+	}
+
+	return NewSigner(edKey, issuer), nil
+}
+
+// KeyID returns the ID of the key tokens are signed with
+func (s *Signer) KeyID() string {
+	return s.keyID
+}
+
+// Sign issues a token for subject with the given roles, valid for ttl
+func (s *Signer) Sign(subject string, roles []Role, ttl time.Duration) (string, *Claims, error) {
+	now := time.Now().Truncate(time.Second)
+	claims := &Claims{
+		Subject:   subject,
+		Roles:     roles,
+		IssuedAt:  now,
+		ExpiresAt: now.Add(ttl),
+	}
+
+	head, err := json.Marshal(header{Algorithm: algorithm, Type: "JWT", KeyID: s.keyID})
+	if err != nil {
+		return "", nil, err
+	}
+	body, err := json.Marshal(payload{
+		Issuer:    s.issuer,
+		Subject:   subject,
+		Roles:     roles,
+		IssuedAt:  claims.IssuedAt.Unix(),
+		ExpiresAt: claims.ExpiresAt.Unix(),
+	})
+	if err != nil {
+		return "", nil, err
+	}
+
+	signingInput := base64.RawURLEncoding.EncodeToString(head) + "." + base64.RawURLEncoding.EncodeToString(body)
+	signature := ed25519.Sign(s.key, []byte(signingInput))
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), claims, nil
+}
+
+// Verifier checks tokens against a set of public keys, so keys can be
+// rotated by trusting the new key before signing with it
+type Verifier struct {
+	keys   map[string]ed25519.PublicKey // by key ID
+	issuer string
+}
+
+// NewVerifier creates a verifier accepting tokens from issuer signed with
+// any of keys
+func NewVerifier(issuer string, keys ...ed25519.PublicKey) *Verifier {
+	v := &Verifier{
+		keys:   make(map[string]ed25519.PublicKey, len(keys)),
+		issuer: issuer,
+	}
+	for _, key := range keys {
+		v.keys[KeyID(key)] = key
+	}
+	return v
+}
+
+// LoadVerifier creates a verifier with the Ed25519 public keys of a PEM
+// file, as written by `openssl pkey -pubout`, or of a JWK Set file, as
+// served by the gateway. It returns nil when path is empty, which turns
+// authorization off.
+func LoadVerifier(path, issuer string) (*Verifier, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read public keys: %w", err)
+	}
+
+	if trimmed := strings.TrimSpace(string(data)); strings.HasPrefix(trimmed, "{") {
+		var set JWKSet
+		if err := json.Unmarshal([]byte(trimmed), &set); err != nil {
+			return nil, fmt.Errorf("failed to parse JWK set %s: %w", path, err)
+		}
+		keys, err := set.publicKeys()
+		if err != nil {
+			return nil, fmt.Errorf("invalid JWK set %s: %w", path, err)
+		}
+		return NewVerifier(issuer, keys...), nil
+	}
+
+	var keys []ed25519.PublicKey
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type != "PUBLIC KEY" {
+			continue
+		}
+		key, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse public key in %s: %w", path, err)
+		}
+		edKey, ok := key.(ed25519.PublicKey)
+		if !ok {
+			return nil, fmt.Errorf("public key in %s is not an Ed25519 key", path)
+		}
+		keys = append(keys, edKey)
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("%s holds no PEM public key", path)
+	}
+
+	return NewVerifier(issuer, keys...), nil
+}
+
+// JWK is a public key in JSON Web Key form (RFC 8037)
+type JWK struct {
+	KeyType   string `json:"kty"`
+	Curve     string `json:"crv"`
+	X         string `json:"x"`
+	KeyID     string `json:"kid,omitempty"`
+	Algorithm string `json:"alg,omitempty"`
+	Use       string `json:"use,omitempty"`
+}
+
+// JWKSet is a JSON Web Key Set (RFC 7517)
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// publicKeys returns the Ed25519 keys of the set. Keys for other purposes
+// are skipped; a set without any Ed25519 signing key is an error.
+func (s JWKSet) publicKeys() ([]ed25519.PublicKey, error) {
+	var keys []ed25519.PublicKey
+	for _, jwk := range s.Keys {
+		if jwk.KeyType != "OKP" || jwk.Curve != "Ed25519" || (jwk.Use != "" && jwk.Use != "sig") {
+			continue
+		}
+		key, err := base64.RawURLEncoding.DecodeString(jwk.X)
+		if err != nil || len(key) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("key %q is not a valid Ed25519 key", jwk.KeyID)
+		}
+		keys = append(keys, ed25519.PublicKey(key))
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("no Ed25519 signing key")
+	}
+	return keys, nil
+}
+
+// JWKS returns the keys tokens are verified with as a JWK Set, sorted by
+// key ID, for others to verify tokens with
+func (v *Verifier) JWKS() JWKSet {
+	set := JWKSet{Keys: make([]JWK, 0, len(v.keys))}
+	for keyID, key := range v.keys {
+		set.Keys = append(set.Keys, JWK{
+			KeyType:   "OKP",
+			Curve:     "Ed25519",
+			X:         base64.RawURLEncoding.EncodeToString(key),
+			KeyID:     keyID,
+			Algorithm: algorithm,
+			Use:       "sig",
+		})
+	}
+	slices.SortFunc(set.Keys, func(a, b JWK) int {
+		return strings.Compare(a.KeyID, b.KeyID)
+	})
+	return set
+}
+
+// Trusts checks if tokens signed by signer pass verification
+func (v *Verifier) Trusts(signer *Signer) bool {
+	_, ok := v.keys[signer.keyID]
+	return ok
+}
+
+// Verify checks the signature, issuer and expiry of a token and returns its
+// claims
+func (v *Verifier) Verify(token string) (*Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrInvalidToken
+	}
+
+	var head header
+	if err := decodeSegment(parts[0], &head); err != nil {
+		return nil, ErrInvalidToken
+	}
+	// Only the algorithm the keys are for is accepted, whatever the
+	// token asks for
+	if head.Algorithm != algorithm {
+		return nil, ErrInvalidToken
+	}
+	key, ok := v.keys[head.KeyID]
+	if !ok {
+		return nil, ErrInvalidToken
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil || !ed25519.Verify(key, []byte(parts[0]+"."+parts[1]), signature) {
+		return nil, ErrInvalidToken
+	}
+
+	var body payload
+	if err := decodeSegment(parts[1], &body); err != nil {
+		return nil, ErrInvalidToken
+	}
+	if body.Issuer != v.issuer || body.Subject == "" {
+		return nil, ErrInvalidToken
+	}
+	expiresAt := time.Unix(body.ExpiresAt, 0)
+	if !time.Now().Before(expiresAt) {
+		return nil, ErrInvalidToken
+	}
+
+	return &Claims{
+		Subject:   body.Subject,
+		Roles:     body.Roles,
+		IssuedAt:  time.Unix(body.IssuedAt, 0),
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+// decodeSegment decodes a base64url encoded JSON segment of a token
+func decodeSegment(segment string, v any) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
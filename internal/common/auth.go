@@ -0,0 +1,56 @@
+package common
+
+import (
+	"fmt"
+	"log"
+
+	"learning/internal/auth"
+)
+
+// LoadVerifier loads the keys tokens are verified with. It returns nil, and
+// authorization is off, when no public key file is configured.
+func LoadVerifier(config *Config) (*auth.Verifier, error) {
+	verifier, err := auth.LoadVerifier(config.AuthPublicKeyFile, config.AuthIssuer)
+	if err != nil {
+		return nil, err
+	}
+	if verifier == nil {
+		log.Printf("AUTH_PUBLIC_KEY_FILE is not set, authorization is disabled")
+	}
+	return verifier, nil
+}
+
+// LoadServiceCredentials loads the key a service signs the tokens for its
+// own calls with. It returns nil when authorization is off.
+func LoadServiceCredentials(config *Config, name string, verifier *auth.Verifier) (*auth.ServiceCredentials, error) {
+	if verifier == nil {
+		return nil, nil
+	}
+	if config.AuthPrivateKeyFile == "" {
+		return nil, fmt.Errorf("%s needs AUTH_PRIVATE_KEY_FILE to sign its own calls", name)
+	}
+
+	signer, err := LoadSigner(config, verifier)
+	if err != nil {
+		return nil, err
+	}
+	return auth.NewServiceCredentials(signer, name), nil
+}
+
+// LoadSigner loads the private key tokens are signed with, which must be
+// one of the keys verifier trusts. It returns nil when no private key file
+// is configured.
+func LoadSigner(config *Config, verifier *auth.Verifier) (*auth.Signer, error) {
+	if config.AuthPrivateKeyFile == "" {
+		return nil, nil
+	}
+
+	signer, err := auth.LoadSigner(config.AuthPrivateKeyFile, config.AuthIssuer)
+	if err != nil {
+		return nil, err
+	}
+	if verifier != nil && !verifier.Trusts(signer) {
+		return nil, fmt.Errorf("the key in %s is not one of the public keys in %s", config.AuthPrivateKeyFile, config.AuthPublicKeyFile)
+	}
+	return signer, nil
+}
@@ -0,0 +1,112 @@
+package common
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// Broadcaster hands published values to its subscribers without ever waiting
+// for them. Each subscriber has a buffer of its own; one that falls further
+// behind than its buffer allows is dropped, so a slow subscriber can't hold up
+// the publisher or the other subscribers.
+type Broadcaster[T any] struct {
+	subscribers map[*Subscription[T]]struct{}
+	bufferSize  int
+	mutex       sync.Mutex
+}
+
+// NewBroadcaster creates a broadcaster whose subscribers buffer up to
+// bufferSize values
+func NewBroadcaster[T any](bufferSize int) *Broadcaster[T] {
+	if bufferSize < 1 {
+		bufferSize = 1
+	}
+	return &Broadcaster[T]{
+		subscribers: make(map[*Subscription[T]]struct{}),
+		bufferSize:  bufferSize,
+	}
+}
+
+// Subscribe registers a subscriber for the values match accepts; a nil match
+// accepts every value
+func (b *Broadcaster[T]) Subscribe(match func(T) bool) *Subscription[T] {
+	s := &Subscription[T]{
+		events:      make(chan T, b.bufferSize),
+		match:       match,
+		broadcaster: b,
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.subscribers[s] = struct{}{}
+
+	return s
+}
+
+// Publish hands v to every subscriber that accepts it, dropping those whose
+// buffer is full
+func (b *Broadcaster[T]) Publish(v T) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	for s := range b.subscribers {
+		if s.match != nil && !s.match(v) {
+			continue
+		}
+		select {
+		case s.events <- v:
+		default:
+			s.dropped.Store(true)
+			b.removeLocked(s)
+		}
+	}
+}
+
+// Close ends every subscription
+func (b *Broadcaster[T]) Close() {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	for s := range b.subscribers {
+		b.removeLocked(s)
+	}
+}
+
+// remove ends a subscription if it is still registered
+func (b *Broadcaster[T]) remove(s *Subscription[T]) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.removeLocked(s)
+}
+
+// removeLocked ends a subscription. The caller must hold the lock.
+func (b *Broadcaster[T]) removeLocked(s *Subscription[T]) {
+	if _, exists := b.subscribers[s]; exists {
+		delete(b.subscribers, s)
+		close(s.events)
+	}
+}
+
+// Subscription is one subscriber of a Broadcaster
+type Subscription[T any] struct {
+	events      chan T
+	match       func(T) bool
+	broadcaster *Broadcaster[T]
+	dropped     atomic.Bool
+}
+
+// Events returns the channel the subscription's values arrive on. It is
+// closed when the subscription ends.
+func (s *Subscription[T]) Events() <-chan T {
+	return s.events
+}
+
+// Dropped reports whether the subscription ended because it fell behind
+func (s *Subscription[T]) Dropped() bool {
+	return s.dropped.Load()
+}
+
+// Close ends the subscription
+func (s *Subscription[T]) Close() {
+	s.broadcaster.remove(s)
+}
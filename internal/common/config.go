@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"strconv"
+	"time"
 )
 
 // Config holds all configuration for the services
@@ -17,15 +19,60 @@ type Config struct {
 	ProductServiceAddress string
 	OrderServiceAddress   string
 
-	// Database config (for this example, we'll use in-memory storage)
+	// Database config; empty means in-memory storage, otherwise the URL
+	// scheme (sqlite://, postgres://) selects the SQL backend
 	DatabaseURL string
 
+	// DataDir persists in-memory storage to disk (snapshot plus append-only
+	// log) when set; ignored when DatabaseURL is set
+	DataDir string
+
+	// AutoMigrate applies pending schema migrations at startup instead of
+	// refusing to start
+	AutoMigrate bool
+
+	// IdempotencyTTL is how long responses to requests made with an
+	// idempotency key are kept; IdempotencyMaxKeys bounds how many are kept
+	IdempotencyTTL     time.Duration
+	IdempotencyMaxKeys int
+
 	// Logging
 	LogLevel string
 
+	// Auth config: the PEM file of the public keys tokens are verified with,
+	// which turns authorization on when set, the PEM file of the private key
+	// services sign their own tokens with, and the issuer tokens name
+	AuthPublicKeyFile  string
+	AuthPrivateKeyFile string
+	AuthIssuer         string
+
+	// Login sessions: how long the access tokens the user service issues
+	// last, and how long a refresh token may go unused before its session
+	// expires
+	AuthAccessTokenTTL  time.Duration
+	AuthRefreshTokenTTL time.Duration
+
 	// GraphQL config
 	GraphQLEnabled           bool
 	GraphQLPlaygroundEnabled bool
+
+	// GraphQL subscriptions: how many updates a subscriber may fall behind
+	// before it is dropped, how often idle connections are pinged, and how
+	// long a write to a client may take before the connection is closed
+	GraphQLSubscriptionBuffer int
+	GraphQLKeepAliveInterval  time.Duration
+	GraphQLWriteTimeout       time.Duration
+
+	// GraphQL operation limits; 0 disables a limit
+	GraphQLMaxDepth         int
+	GraphQLMaxComplexity    int
+	GraphQLOperationTimeout time.Duration
+
+	// GraphQL persisted queries: how many automatic persisted queries are
+	// cached, and the trusted documents manifest that, when set, is the only
+	// source of operations
+	GraphQLPersistedQueryCache int
+	GraphQLTrustedDocuments    string
 }
 
 // LoadConfig loads configuration from environment variables with defaults
@@ -37,7 +84,14 @@ func LoadConfig() *Config {
 		ProductServiceAddress: getEnv("PRODUCT_SERVICE_ADDRESS", "localhost:50052"),
 		OrderServiceAddress:   getEnv("ORDER_SERVICE_ADDRESS", "localhost:50053"),
 		DatabaseURL:           getEnv("DATABASE_URL", ""),
+		DataDir:               getEnv("DATA_DIR", ""),
+		AutoMigrate:           getEnv("AUTO_MIGRATE", "false") == "true",
+		IdempotencyTTL:        getEnvDuration("IDEMPOTENCY_TTL", 24*time.Hour),
+		IdempotencyMaxKeys:    getEnvInt("IDEMPOTENCY_MAX_KEYS", 10000),
 		LogLevel:              getEnv("LOG_LEVEL", "info"),
+		AuthPublicKeyFile:     getEnv("AUTH_PUBLIC_KEY_FILE", ""),
+		AuthPrivateKeyFile:    getEnv("AUTH_PRIVATE_KEY_FILE", ""),
+		AuthIssuer:            getEnv("AUTH_ISSUER", "learning"),
 	}
 
 	log.Printf("Configuration loaded: %+v", config)
@@ -48,6 +102,9 @@ func LoadConfig() *Config {
 func LoadUserServiceConfig() *Config {
 	config := LoadConfig()
 	config.Port = getEnv("USER_SERVICE_PORT", "50051")
+	config.DatabaseURL = getEnv("USER_DATABASE_URL", config.DatabaseURL)
+	config.AuthAccessTokenTTL = getEnvDuration("AUTH_ACCESS_TOKEN_TTL", 15*time.Minute)
+	config.AuthRefreshTokenTTL = getEnvDuration("AUTH_REFRESH_TOKEN_TTL", 30*24*time.Hour)
 	return config
 }
 
@@ -55,6 +112,7 @@ func LoadUserServiceConfig() *Config {
 func LoadProductServiceConfig() *Config {
 	config := LoadConfig()
 	config.Port = getEnv("PRODUCT_SERVICE_PORT", "50052")
+	config.DatabaseURL = getEnv("PRODUCT_DATABASE_URL", config.DatabaseURL)
 	return config
 }
 
@@ -62,6 +120,7 @@ func LoadProductServiceConfig() *Config {
 func LoadOrderServiceConfig() *Config {
 	config := LoadConfig()
 	config.Port = getEnv("ORDER_SERVICE_PORT", "50053")
+	config.DatabaseURL = getEnv("ORDER_DATABASE_URL", config.DatabaseURL)
 	return config
 }
 
@@ -73,6 +132,14 @@ func LoadGatewayConfig() *Config {
 	// GraphQL specific configs
 	config.GraphQLEnabled = getEnv("GRAPHQL_ENABLED", "true") == "true"
 	config.GraphQLPlaygroundEnabled = getEnv("GRAPHQL_PLAYGROUND_ENABLED", "true") == "true"
+	config.GraphQLSubscriptionBuffer = getEnvInt("GRAPHQL_SUBSCRIPTION_BUFFER", 64)
+	config.GraphQLKeepAliveInterval = getEnvDuration("GRAPHQL_KEEPALIVE_INTERVAL", 15*time.Second)
+	config.GraphQLWriteTimeout = getEnvDuration("GRAPHQL_WRITE_TIMEOUT", 10*time.Second)
+	config.GraphQLMaxDepth = getEnvLimit("GRAPHQL_MAX_DEPTH", 12)
+	config.GraphQLMaxComplexity = getEnvLimit("GRAPHQL_MAX_COMPLEXITY", 10000)
+	config.GraphQLOperationTimeout = getEnvTimeout("GRAPHQL_OPERATION_TIMEOUT", 10*time.Second)
+	config.GraphQLPersistedQueryCache = getEnvLimit("GRAPHQL_PERSISTED_QUERY_CACHE", 1000)
+	config.GraphQLTrustedDocuments = getEnv("GRAPHQL_TRUSTED_DOCUMENTS", "")
 
 	return config
 }
@@ -94,3 +161,61 @@ func getEnv(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+// getEnvDuration gets a duration environment variable with default value
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration <= 0 {
+		log.Printf("Invalid %s %q, using %v", key, value, defaultValue)
+		return defaultValue
+	}
+	return duration
+}
+
+// getEnvInt gets a positive integer environment variable with default value
+func getEnvInt(key string, defaultValue int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	number, err := strconv.Atoi(value)
+	if err != nil || number <= 0 {
+		log.Printf("Invalid %s %q, using %d", key, value, defaultValue)
+		return defaultValue
+	}
+	return number
+}
+
+// getEnvLimit gets a non-negative integer environment variable with default
+// value, where 0 disables the limit it sets
+func getEnvLimit(key string, defaultValue int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	number, err := strconv.Atoi(value)
+	if err != nil || number < 0 {
+		log.Printf("Invalid %s %q, using %d", key, value, defaultValue)
+		return defaultValue
+	}
+	return number
+}
+
+// getEnvTimeout gets a non-negative duration environment variable with
+// default value, where 0 disables the timeout it sets
+func getEnvTimeout(key string, defaultValue time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration < 0 {
+		log.Printf("Invalid %s %q, using %v", key, value, defaultValue)
+		return defaultValue
+	}
+	return duration
+}
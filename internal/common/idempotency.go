@@ -0,0 +1,220 @@
+package common
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	"learning/internal/auth"
+)
+
+const (
+	// IdempotencyKeyHeader is the gRPC metadata key, and HTTP header, that
+	// carries a client-chosen idempotency key
+	IdempotencyKeyHeader = "idempotency-key"
+	// IdempotentReplayHeader is set on responses replayed from the store
+	IdempotentReplayHeader = "idempotent-replayed"
+
+	maxIdempotencyKeyLength = 255
+)
+
+// idempotencyEntry is the outcome of the first request made with a key
+type idempotencyEntry struct {
+	id        string
+	hash      [sha256.Size]byte
+	response  proto.Message // nil until the first request succeeded
+	done      chan struct{} // closed once the first request finished
+	expiresAt time.Time
+	element   *list.Element
+}
+
+// IdempotencyStore remembers the responses of requests made with an
+// idempotency key, so a retried request gets the original response instead
+// of being executed again. Keys are kept in memory for a TTL, and the oldest
+// are dropped once the store holds maxKeys of them.
+type IdempotencyStore struct {
+	mutex   sync.Mutex
+	entries map[string]*idempotencyEntry
+	order   *list.List // entries oldest first
+	ttl     time.Duration
+	maxKeys int
+}
+
+// NewIdempotencyStore creates a store keeping at most maxKeys keys for ttl
+func NewIdempotencyStore(ttl time.Duration, maxKeys int) *IdempotencyStore {
+	return &IdempotencyStore{
+		entries: make(map[string]*idempotencyEntry),
+		order:   list.New(),
+		ttl:     ttl,
+		maxKeys: maxKeys,
+	}
+}
+
+// UnaryInterceptor makes the given methods idempotent for requests carrying
+// an idempotency key. A repeat of a successful request by the same caller
+// gets the stored response; reusing a key with a different request fails with
+// FailedPrecondition. Failed requests are not stored, so they can be retried
+// with the same key.
+func (s *IdempotencyStore) UnaryInterceptor(methods ...string) grpc.UnaryServerInterceptor {
+	idempotent := make(map[string]bool, len(methods))
+	for _, method := range methods {
+		idempotent[method] = true
+	}
+
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if !idempotent[info.FullMethod] {
+			return handler(ctx, req)
+		}
+
+		key := idempotencyKey(ctx)
+		if key == "" {
+			return handler(ctx, req)
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			return nil, status.Error(codes.InvalidArgument, "idempotency key is too long")
+		}
+
+		message, ok := req.(proto.Message)
+		if !ok {
+			return handler(ctx, req)
+		}
+		hash, err := hashRequest(message)
+		if err != nil {
+			return nil, status.Error(codes.Internal, "failed to hash request")
+		}
+
+		// Keys are scoped to the method they were first used with, and to
+		// the caller, so nobody can replay another caller's response
+		id := info.FullMethod + " " + key
+		if claims, ok := auth.FromContext(ctx); ok {
+			id = claims.Subject + " " + id
+		}
+		entry, err := s.acquire(ctx, id, hash)
+		if err != nil {
+			return nil, err
+		}
+		if entry.response != nil {
+			grpc.SetHeader(ctx, metadata.Pairs(IdempotentReplayHeader, "true"))
+			return proto.Clone(entry.response), nil
+		}
+
+		resp, err := handler(ctx, req)
+		s.finish(entry, resp, err)
+		return resp, err
+	}
+}
+
+// acquire returns the finished entry for id, waiting for a request with the
+// same key that is still running, or a new entry the caller must finish
+func (s *IdempotencyStore) acquire(ctx context.Context, id string, hash [sha256.Size]byte) (*idempotencyEntry, error) {
+	for {
+		s.mutex.Lock()
+		s.evict(time.Now())
+
+		entry, exists := s.entries[id]
+		if !exists {
+			entry = &idempotencyEntry{
+				id:        id,
+				hash:      hash,
+				done:      make(chan struct{}),
+				expiresAt: time.Now().Add(s.ttl),
+			}
+			entry.element = s.order.PushBack(entry)
+			s.entries[id] = entry
+			s.mutex.Unlock()
+			return entry, nil
+		}
+		s.mutex.Unlock()
+
+		if entry.hash != hash {
+			return nil, status.Error(codes.FailedPrecondition,
+				"idempotency key was already used with a different request")
+		}
+
+		select {
+		case <-entry.done:
+		case <-ctx.Done():
+			return nil, status.FromContextError(ctx.Err()).Err()
+		}
+
+		if entry.response != nil {
+			return entry, nil
+		}
+		// The first request failed and gave the key up; try again
+	}
+}
+
+// finish records the outcome of the request that owns an entry
+func (s *IdempotencyStore) finish(entry *idempotencyEntry, resp interface{}, err error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	message, ok := resp.(proto.Message)
+	if err == nil && ok {
+		entry.response = proto.Clone(message)
+		entry.expiresAt = time.Now().Add(s.ttl)
+		s.order.MoveToBack(entry.element)
+	} else {
+		s.remove(entry)
+	}
+	close(entry.done)
+}
+
+// evict drops finished entries past their TTL and the oldest entries beyond
+// maxKeys. The caller must hold the lock.
+func (s *IdempotencyStore) evict(now time.Time) {
+	// Finished entries are moved to the back, so they are ordered by expiry
+	for element := s.order.Front(); element != nil; {
+		next := element.Next()
+		entry := element.Value.(*idempotencyEntry)
+		if entry.response != nil {
+			if !now.After(entry.expiresAt) {
+				break
+			}
+			s.remove(entry)
+		}
+		element = next
+	}
+
+	for s.order.Len() >= s.maxKeys && s.order.Len() > 0 {
+		s.remove(s.order.Front().Value.(*idempotencyEntry))
+	}
+}
+
+// remove forgets an entry if it is still stored. The caller must hold the lock.
+func (s *IdempotencyStore) remove(entry *idempotencyEntry) {
+	if s.entries[entry.id] != entry {
+		return
+	}
+	delete(s.entries, entry.id)
+	s.order.Remove(entry.element)
+}
+
+// idempotencyKey returns the idempotency key sent with a request, if any
+func idempotencyKey(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	if values := md.Get(IdempotencyKeyHeader); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// hashRequest fingerprints a request so reuse of a key can be detected
+func hashRequest(message proto.Message) ([sha256.Size]byte, error) {
+	data, err := proto.MarshalOptions{Deterministic: true}.Marshal(message)
+	if err != nil {
+		return [sha256.Size]byte{}, err
+	}
+	return sha256.Sum256(data), nil
+}
@@ -0,0 +1,290 @@
+package common
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// DefaultCompactEvery is the number of log records after which a journal
+// writes a fresh snapshot and truncates its log
+const DefaultCompactEvery = 1000
+
+// recordHeaderSize is the length prefix plus the CRC of every log record
+const recordHeaderSize = 8
+
+var crcTable = crc32.MakeTable(crc32.Castagnoli)
+
+// Journal persists a data set as a snapshot plus an append-only log of
+// mutations. Every record is fsync'd before Append returns, and the log is
+// folded into a new snapshot every compactEvery records.
+//
+// Records are framed as [length][crc32c][json payload]. A torn or corrupt
+// record at the end of the log (e.g. after a crash mid-write) is dropped and
+// truncated away on replay; a corrupt record anywhere else fails the replay,
+// as the records after it can't be dropped safely.
+//
+// Replaying a record twice must be harmless: a crash between writing a
+// snapshot and truncating the log replays records the snapshot already holds.
+type Journal struct {
+	snapshotPath string
+	logPath      string
+	compactEvery int
+
+	mutex   sync.Mutex
+	log     *os.File
+	size    int64
+	records int
+}
+
+// OpenJournal opens (creating if needed) the journal called name inside dir
+func OpenJournal(dir, name string, compactEvery int) (*Journal, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create data directory: %w", err)
+	}
+
+	if compactEvery <= 0 {
+		compactEvery = DefaultCompactEvery
+	}
+
+	return &Journal{
+		snapshotPath: filepath.Join(dir, name+".snapshot"),
+		logPath:      filepath.Join(dir, name+".log"),
+		compactEvery: compactEvery,
+	}, nil
+}
+
+// Replay feeds the snapshot (if any) and then every intact log record to the
+// given callbacks, and opens the log for appending. It must be called once
+// before Append.
+func (j *Journal) Replay(loadSnapshot func(data []byte) error, applyRecord func(data []byte) error) error {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	snapshot, err := os.ReadFile(j.snapshotPath)
+	switch {
+	case err == nil:
+		if err := loadSnapshot(snapshot); err != nil {
+			return fmt.Errorf("failed to load snapshot %s: %w", j.snapshotPath, err)
+		}
+	case !errors.Is(err, os.ErrNotExist):
+		return fmt.Errorf("failed to read snapshot: %w", err)
+	}
+
+	file, err := os.OpenFile(j.logPath, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open log: %w", err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("failed to stat log: %w", err)
+	}
+
+	valid, records, err := replayLog(file, info.Size(), applyRecord)
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	// Drop a torn tail so new records are appended after the last good one
+	if info.Size() > valid {
+		log.Printf("Journal %s: discarding %d bytes of torn log tail", j.logPath, info.Size()-valid)
+		if err := file.Truncate(valid); err != nil {
+			file.Close()
+			return fmt.Errorf("failed to truncate torn log: %w", err)
+		}
+		if err := file.Sync(); err != nil {
+			file.Close()
+			return fmt.Errorf("failed to sync log: %w", err)
+		}
+	}
+
+	if _, err := file.Seek(valid, io.SeekStart); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to seek log: %w", err)
+	}
+
+	j.log = file
+	j.size = valid
+	j.records = records
+	return nil
+}
+
+// Append writes a record to the log and fsyncs it
+func (j *Journal) Append(record any) error {
+	payload, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to encode journal record: %w", err)
+	}
+
+	frame := make([]byte, recordHeaderSize+len(payload))
+	binary.LittleEndian.PutUint32(frame[0:4], uint32(len(payload)))
+	binary.LittleEndian.PutUint32(frame[4:8], crc32.Checksum(payload, crcTable))
+	copy(frame[recordHeaderSize:], payload)
+
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	if j.log == nil {
+		return errors.New("journal is not open")
+	}
+
+	if _, err := j.log.Write(frame); err != nil {
+		j.rewind()
+		return fmt.Errorf("failed to write journal record: %w", err)
+	}
+	if err := j.log.Sync(); err != nil {
+		j.rewind()
+		return fmt.Errorf("failed to sync journal: %w", err)
+	}
+
+	j.size += int64(len(frame))
+	j.records++
+	return nil
+}
+
+// NeedsCompaction reports whether enough records accumulated to snapshot
+func (j *Journal) NeedsCompaction() bool {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+	return j.records >= j.compactEvery
+}
+
+// Compact writes state as the new snapshot and empties the log.
+// The caller must make sure no Append runs concurrently with the state
+// being captured.
+func (j *Journal) Compact(state any) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("failed to encode snapshot: %w", err)
+	}
+
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	if err := writeFileAtomic(j.snapshotPath, data); err != nil {
+		return err
+	}
+
+	if err := j.log.Truncate(0); err != nil {
+		return fmt.Errorf("failed to truncate log: %w", err)
+	}
+	if _, err := j.log.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to seek log: %w", err)
+	}
+	if err := j.log.Sync(); err != nil {
+		return fmt.Errorf("failed to sync log: %w", err)
+	}
+
+	j.size = 0
+	j.records = 0
+	return nil
+}
+
+// rewind drops a partially written record so later appends stay readable
+func (j *Journal) rewind() {
+	if err := j.log.Truncate(j.size); err != nil {
+		log.Printf("Journal %s: failed to drop partial record: %v", j.logPath, err)
+	}
+	j.log.Seek(j.size, io.SeekStart)
+}
+
+// Close closes the log file
+func (j *Journal) Close() error {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	if j.log == nil {
+		return nil
+	}
+
+	err := j.log.Close()
+	j.log = nil
+	return err
+}
+
+// replayLog applies every intact record and returns the offset just past the
+// last one together with the number of records applied. Only the last record
+// may be torn or corrupt.
+func replayLog(file *os.File, fileSize int64, applyRecord func(data []byte) error) (int64, int, error) {
+	reader := bufio.NewReader(file)
+	header := make([]byte, recordHeaderSize)
+
+	var offset int64
+	records := 0
+	for {
+		if _, err := io.ReadFull(reader, header); err != nil {
+			// EOF or a header cut short by a crash
+			return offset, records, nil
+		}
+
+		size := binary.LittleEndian.Uint32(header[0:4])
+		sum := binary.LittleEndian.Uint32(header[4:8])
+		if int64(size) > fileSize-offset-recordHeaderSize {
+			// A length running past the end of the file is a torn header
+			return offset, records, nil
+		}
+
+		payload := make([]byte, size)
+		if _, err := io.ReadFull(reader, payload); err != nil {
+			return offset, records, nil
+		}
+		if crc32.Checksum(payload, crcTable) != sum {
+			if end := offset + recordHeaderSize + int64(size); end < fileSize {
+				return 0, 0, fmt.Errorf("corrupt journal record at offset %d, followed by %d more bytes", offset, fileSize-end)
+			}
+			return offset, records, nil
+		}
+
+		if err := applyRecord(payload); err != nil {
+			return 0, 0, fmt.Errorf("failed to apply journal record at offset %d: %w", offset, err)
+		}
+
+		offset += int64(recordHeaderSize) + int64(size)
+		records++
+	}
+}
+
+// writeFileAtomic replaces path with data via an fsync'd temporary file
+func writeFileAtomic(path string, data []byte) error {
+	tmp := path + ".tmp"
+
+	file, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to create snapshot: %w", err)
+	}
+
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to sync snapshot: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to close snapshot: %w", err)
+	}
+
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to install snapshot: %w", err)
+	}
+
+	// Make the rename itself durable
+	if dir, err := os.Open(filepath.Dir(path)); err == nil {
+		dir.Sync()
+		dir.Close()
+	}
+
+	return nil
+}
@@ -0,0 +1,316 @@
+package common
+
+import (
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultPageSize is the page size used when none is requested
+	DefaultPageSize = 10
+	// MaxPageSize caps the page size of every list
+	MaxPageSize = 100
+)
+
+var (
+	ErrInvalidCursor    = errors.New("invalid cursor")
+	ErrInvalidPageToken = errors.New("invalid page token")
+)
+
+// PageSize applies the default and maximum to a requested page size
+func PageSize(size int) int {
+	if size <= 0 {
+		return DefaultPageSize
+	}
+	return min(size, MaxPageSize)
+}
+
+// Cursor is the position of a row in a list ordered by creation time, then ID
+type Cursor struct {
+	CreatedAt time.Time `json:"t"`
+	ID        string    `json:"i"`
+}
+
+// IsZero reports whether the cursor is unset
+func (c Cursor) IsZero() bool {
+	return c.ID == ""
+}
+
+// Compare returns -1, 0 or +1 as c comes before, at or after other
+func (c Cursor) Compare(other Cursor) int {
+	if cmp := c.CreatedAt.Compare(other.CreatedAt); cmp != 0 {
+		return cmp
+	}
+	return strings.Compare(c.ID, other.ID)
+}
+
+// EncodeCursor turns a cursor into an opaque string
+func EncodeCursor(c Cursor) string {
+	c.CreatedAt = c.CreatedAt.UTC()
+	return encodeOpaque(c)
+}
+
+// DecodeCursor parses a string made by EncodeCursor
+func DecodeCursor(s string) (Cursor, error) {
+	var c Cursor
+	if err := decodeOpaque(s, &c); err != nil || c.IsZero() {
+		return Cursor{}, ErrInvalidCursor
+	}
+	return c, nil
+}
+
+// PageQuery selects one page of a list ordered by creation time, then ID.
+// The page is taken from the range of rows after After and before Before,
+// skipping Offset rows, or from the end of that range when FromEnd is set.
+type PageQuery struct {
+	Offset    int
+	Limit     int
+	After     Cursor // only rows after this one, if set
+	Before    Cursor // only rows before this one, if set
+	FromEnd   bool   // take the last Limit rows of the range
+	SkipTotal bool   // leave PageInfo.Total at 0 rather than count the list
+}
+
+// pageToken is the keyset part of a PageQuery carried by a page token
+type pageToken struct {
+	After   *Cursor `json:"a,omitempty"`
+	Before  *Cursor `json:"b,omitempty"`
+	FromEnd bool    `json:"e,omitempty"`
+}
+
+// NewPageQuery builds the query for a list request, which asks either for
+// a page number or for the page a page token points to
+func NewPageQuery(page, pageSize int, token string) (PageQuery, error) {
+	query := PageQuery{Limit: PageSize(pageSize)}
+
+	if token == "" {
+		// Set default page if not provided
+		if page <= 0 {
+			page = 1
+		}
+		query.Offset = (page - 1) * query.Limit
+		return query, nil
+	}
+
+	var t pageToken
+	if err := decodeOpaque(token, &t); err != nil {
+		return PageQuery{}, ErrInvalidPageToken
+	}
+	if t.After != nil {
+		query.After = *t.After
+	}
+	if t.Before != nil {
+		query.Before = *t.Before
+	}
+	query.FromEnd = t.FromEnd
+	if !query.IsKeyset() {
+		return PageQuery{}, ErrInvalidPageToken
+	}
+	return query, nil
+}
+
+// IsKeyset reports whether the query is positioned by cursors rather than
+// by an offset, so it has to be sent on as a page token
+func (q PageQuery) IsKeyset() bool {
+	return !q.After.IsZero() || !q.Before.IsZero() || q.FromEnd
+}
+
+// PageToken returns the page token for the keyset part of the query
+func (q PageQuery) PageToken() string {
+	t := pageToken{FromEnd: q.FromEnd}
+	if !q.After.IsZero() {
+		after := q.After
+		t.After = &after
+	}
+	if !q.Before.IsZero() {
+		before := q.Before
+		t.Before = &before
+	}
+	return encodeOpaque(t)
+}
+
+// Window returns the bounds of the page among the total rows of a list,
+// given the positions where the query's range starts and ends
+func (q PageQuery) Window(total, rangeStart, rangeEnd int) (start, end int) {
+	rangeStart = min(rangeStart, total)
+	rangeEnd = min(max(rangeEnd, rangeStart), total)
+
+	if q.FromEnd {
+		return max(rangeStart, rangeEnd-q.Limit), rangeEnd
+	}
+	start = min(rangeStart+q.Offset, rangeEnd)
+	return start, min(start+q.Limit, rangeEnd)
+}
+
+// RangeConditions returns the SQL conditions limiting rows to the query's range
+func (q PageQuery) RangeConditions() ([]string, []any) {
+	var conditions []string
+	var args []any
+	if !q.After.IsZero() {
+		conditions = append(conditions, keysetCondition(">"))
+		args = append(args, q.After.args()...)
+	}
+	if !q.Before.IsZero() {
+		conditions = append(conditions, keysetCondition("<"))
+		args = append(args, q.Before.args()...)
+	}
+	return conditions, args
+}
+
+// keysetCondition compares (created_at, id) with a cursor's three arguments
+func keysetCondition(op string) string {
+	return "(created_at " + op[:1] + " ? OR (created_at = ? AND id " + op + " ?))"
+}
+
+// args returns the cursor's values for a keysetCondition; timestamps are
+// stored in UTC
+func (c Cursor) args() []any {
+	createdAt := c.CreatedAt.UTC()
+	return []any{createdAt, createdAt, c.ID}
+}
+
+// PageInfo describes where a page sits in the list it was taken from
+type PageInfo struct {
+	Total       int // rows in the whole list
+	HasPrevious bool
+	HasNext     bool
+}
+
+// NewPageInfo describes the page spanning rows start to end of total
+func NewPageInfo(total, start, end int) PageInfo {
+	return PageInfo{Total: total, HasPrevious: start > 0, HasNext: end < total}
+}
+
+// PageInfo describes a page of the query taken without counting the list.
+// more tells whether the range goes on past the page, on the side the page
+// was taken from; rows on the other side are taken to exist when the query
+// skips an offset or starts at a cursor, whose row did when it was handed out.
+func (q PageQuery) PageInfo(total int, more bool) PageInfo {
+	if q.FromEnd {
+		return PageInfo{Total: total, HasPrevious: more, HasNext: !q.Before.IsZero()}
+	}
+	return PageInfo{Total: total, HasPrevious: q.Offset > 0 || !q.After.IsZero(), HasNext: more}
+}
+
+// Tokens returns the page tokens of the pages before and after one whose
+// rows run from first to last; a token is empty when there is no such page
+func (p PageInfo) Tokens(first, last Cursor) (previous, next string) {
+	if p.HasPrevious && !first.IsZero() {
+		previous = PageQuery{Before: first, FromEnd: true}.PageToken()
+	}
+	if p.HasNext && !last.IsZero() {
+		next = PageQuery{After: last}.PageToken()
+	}
+	return previous, next
+}
+
+// Paginate sorts items by creation time, then ID, and returns the page of
+// them the query selects
+func Paginate[T any](items []T, cursor func(T) Cursor, query PageQuery) ([]T, PageInfo) {
+	sort.Slice(items, func(i, j int) bool {
+		return cursor(items[i]).Compare(cursor(items[j])) < 0
+	})
+
+	rangeStart, rangeEnd := 0, len(items)
+	if !query.After.IsZero() {
+		rangeStart = sort.Search(len(items), func(i int) bool {
+			return cursor(items[i]).Compare(query.After) > 0
+		})
+	}
+	if !query.Before.IsZero() {
+		rangeEnd = sort.Search(len(items), func(i int) bool {
+			return cursor(items[i]).Compare(query.Before) >= 0
+		})
+	}
+
+	start, end := query.Window(len(items), rangeStart, rangeEnd)
+	return items[start:end], NewPageInfo(len(items), start, end)
+}
+
+// ListPage selects the page of rows from table, narrowed by where, that the
+// query selects, ordered by created_at, then id. scan is called for each row.
+// The list is only counted when the query asks for its total.
+func (d *Database) ListPage(ctx context.Context, table, columns, where string, args []any, query PageQuery, scan func(*sql.Rows) error) (PageInfo, error) {
+	var total int
+	if !query.SkipTotal {
+		err := d.QueryRowContext(ctx, d.Rebind("SELECT COUNT(*) FROM "+table+where), args...).Scan(&total)
+		if err != nil {
+			return PageInfo{}, fmt.Errorf("failed to count %s: %w", table, err)
+		}
+	}
+
+	conditions, rangeArgs := query.RangeConditions()
+	if len(conditions) > 0 {
+		if where == "" {
+			where = " WHERE "
+		} else {
+			where += " AND "
+		}
+		where += strings.Join(conditions, " AND ")
+	}
+	args = append(append([]any{}, args...), rangeArgs...)
+
+	// Look for a row past the page, walking the index no further than it
+	order, offset := "created_at, id", query.Offset
+	if query.FromEnd {
+		order, offset = "created_at DESC, id DESC", 0
+	}
+	var exists int
+	err := d.QueryRowContext(ctx, d.Rebind("SELECT 1 FROM "+table+where+" ORDER BY "+order+" LIMIT 1 OFFSET ?"),
+		append(args, offset+query.Limit)...,
+	).Scan(&exists)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return PageInfo{}, fmt.Errorf("failed to list %s: %w", table, err)
+	}
+	info := query.PageInfo(total, err == nil)
+
+	var q string
+	if query.FromEnd {
+		// Take the last rows of the range, then put them back in order
+		q = "SELECT " + columns + " FROM (SELECT " + columns + " FROM " + table + where +
+			" ORDER BY created_at DESC, id DESC LIMIT ?) last_rows ORDER BY created_at, id"
+		args = append(args, query.Limit)
+	} else {
+		q = "SELECT " + columns + " FROM " + table + where + " ORDER BY created_at, id LIMIT ? OFFSET ?"
+		args = append(args, query.Limit, query.Offset)
+	}
+
+	rows, err := d.QueryContext(ctx, d.Rebind(q), args...)
+	if err != nil {
+		return PageInfo{}, fmt.Errorf("failed to list %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		if err := scan(rows); err != nil {
+			return PageInfo{}, err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return PageInfo{}, fmt.Errorf("failed to list %s: %w", table, err)
+	}
+
+	return info, nil
+}
+
+// encodeOpaque encodes v as URL-safe base64 JSON
+func encodeOpaque(v any) string {
+	data, _ := json.Marshal(v)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeOpaque parses a string made by encodeOpaque into v
+func decodeOpaque(s string, v any) error {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
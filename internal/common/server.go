@@ -13,6 +13,8 @@ import (
 	"google.golang.org/grpc/health"
 	"google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/reflection"
+
+	"learning/internal/auth"
 )
 
 // GRPCServer wraps grpc.Server with additional functionality
@@ -20,12 +22,19 @@ type GRPCServer struct {
 	server       *grpc.Server
 	healthServer *health.Server
 	address      string
+
+	// shutdownHooks run when shutdown begins
+	shutdownHooks []func()
 }
 
-// NewGRPCServer creates a new gRPC server with health checks and reflection
-func NewGRPCServer(address string) *GRPCServer {
+// NewGRPCServer creates a new gRPC server with health checks and reflection.
+// Calls are authorized after request logging; the given interceptors run
+// after that, in order.
+func NewGRPCServer(address string, authorizer *auth.Authorizer, interceptors ...grpc.UnaryServerInterceptor) *GRPCServer {
+	unary := []grpc.UnaryServerInterceptor{loggingInterceptor, authorizer.UnaryInterceptor()}
 	server := grpc.NewServer(
-		grpc.UnaryInterceptor(loggingInterceptor),
+		grpc.ChainUnaryInterceptor(append(unary, interceptors...)...),
+		grpc.ChainStreamInterceptor(authorizer.StreamInterceptor()),
 	)
 
 	healthServer := health.NewServer()
@@ -56,6 +65,12 @@ func (s *GRPCServer) SetUnhealthy(service string) {
 	s.healthServer.SetServingStatus(service, grpc_health_v1.HealthCheckResponse_NOT_SERVING)
 }
 
+// OnShutdown registers a function to run when shutdown begins, before
+// in-flight calls are waited for; long-lived streams use it to end
+func (s *GRPCServer) OnShutdown(hook func()) {
+	s.shutdownHooks = append(s.shutdownHooks, hook)
+}
+
 // Start starts the gRPC server with graceful shutdown
 func (s *GRPCServer) Start() error {
 	listener, err := net.Listen("tcp", s.address)
@@ -79,6 +94,9 @@ func (s *GRPCServer) Start() error {
 	// Wait for signal
 	<-sigChan
 	log.Println("Shutting down gRPC server...")
+	for _, hook := range s.shutdownHooks {
+		hook()
+	}
 
 	// Graceful shutdown with timeout
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
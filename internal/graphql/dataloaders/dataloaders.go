@@ -2,7 +2,9 @@ package dataloaders
 
 import (
 	"context"
+	"log"
 	"net/http"
+	"sort"
 
 	"learning/internal/order"
 	"learning/internal/product"
@@ -13,45 +15,102 @@ type contextKey string
 
 const loadersKey = contextKey("dataloaders")
 
-// Loaders holds all DataLoaders
+// Loaders holds all DataLoaders of one request. They cache what they load
+// for the request's lifetime only, so each request must get its own set.
 type Loaders struct {
-	UserLoader    *UserLoader
-	ProductLoader *ProductLoader
-	// OrderLoader could be added here if needed
+	UserLoader      *UserLoader
+	ProductLoader   *ProductLoader
+	OrderLoader     *OrderLoader
+	OrderByIDLoader *OrderByIDLoader
+	HistoryLoader   *HistoryLoader
 }
 
-// NewLoaders creates a new set of DataLoaders
+// NewLoaders creates a new set of DataLoaders for the request behind ctx;
+// batches are fetched with ctx and stop once it is cancelled
 func NewLoaders(
+	ctx context.Context,
 	userRepo user.Repository,
 	productRepo product.Repository,
 	orderRepo order.Repository,
 ) *Loaders {
 	return &Loaders{
-		UserLoader:    NewUserLoader(userRepo),
-		ProductLoader: NewProductLoader(productRepo),
+		UserLoader:      NewUserLoader(ctx, userRepo),
+		ProductLoader:   NewProductLoader(ctx, productRepo),
+		OrderLoader:     NewOrderLoader(ctx, orderRepo),
+		OrderByIDLoader: NewOrderByIDLoader(ctx, orderRepo),
+		HistoryLoader:   NewHistoryLoader(ctx, orderRepo),
 	}
 }
 
-// Middleware adds DataLoaders to the request context
-func Middleware(loaders *Loaders) func(http.Handler) http.Handler {
+// Stats reports how each loader's loads were served, keyed by loader name
+func (l *Loaders) Stats() map[string]LoaderStats {
+	return map[string]LoaderStats{
+		"user":      l.UserLoader.Stats(),
+		"product":   l.ProductLoader.Stats(),
+		"order":     l.OrderLoader.Stats(),
+		"orderById": l.OrderByIDLoader.Stats(),
+		"history":   l.HistoryLoader.Stats(),
+	}
+}
+
+// Middleware adds a fresh set of DataLoaders to each request context and
+// logs their stats once the request is done
+func Middleware(
+	userRepo user.Repository,
+	productRepo product.Repository,
+	orderRepo order.Repository,
+) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			loaders := NewLoaders(r.Context(), userRepo, productRepo, orderRepo)
 			ctx := context.WithValue(r.Context(), loadersKey, loaders)
 			r = r.WithContext(ctx)
 			next.ServeHTTP(w, r)
+
+			loaders.logStats()
 		})
 	}
 }
 
+// logStats logs the stats of the loaders that were used
+func (l *Loaders) logStats() {
+	stats := l.Stats()
+	names := make([]string, 0, len(stats))
+	for name := range stats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if s := stats[name]; s.Hits+s.Misses > 0 {
+			log.Printf("DataLoader %s: %s", name, s)
+		}
+	}
+}
+
 // FromContext retrieves DataLoaders from context
 func FromContext(ctx context.Context) *Loaders {
 	loaders, ok := ctx.Value(loadersKey).(*Loaders)
-	if !ok {
+	if !ok || loaders == nil {
 		panic("dataloaders not found in context")
 	}
 	return loaders
 }
 
+// For retrieves DataLoaders from context, or nil outside a request that
+// went through Middleware
+func For(ctx context.Context) *Loaders {
+	loaders, _ := ctx.Value(loadersKey).(*Loaders)
+	return loaders
+}
+
+// Without returns ctx without DataLoaders, for long-lived requests such as
+// WebSocket connections, whose later operations must not be served from what
+// earlier ones cached
+func Without(ctx context.Context) context.Context {
+	return context.WithValue(ctx, loadersKey, (*Loaders)(nil))
+}
+
 // GetUser is a convenience function to get a user via DataLoader
 func GetUser(ctx context.Context, id string) (*user.User, error) {
 	loaders := FromContext(ctx)
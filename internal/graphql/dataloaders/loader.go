@@ -0,0 +1,245 @@
+package dataloaders
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// DefaultWait is how long a loader collects keys before fetching them
+	DefaultWait = 2 * time.Millisecond
+	// DefaultMaxBatch caps the keys fetched together; a full batch is fetched
+	// without waiting
+	DefaultMaxBatch = 100
+)
+
+// BatchFunc fetches the values of several keys in one call. The returned
+// values and errors must line up with keys.
+type BatchFunc[K comparable, V any] func(ctx context.Context, keys []K) ([]V, []error)
+
+// Loader batches and caches loads of one kind of value for a single request.
+// Keys requested within the wait window are fetched together, and each key
+// is fetched at most once per request.
+type Loader[K comparable, V any] struct {
+	ctx      context.Context // request context batches are fetched with
+	fetch    BatchFunc[K, V]
+	wait     time.Duration
+	maxBatch int
+
+	mutex   sync.Mutex
+	cache   map[K]V
+	batch   *batch[K, V]       // collecting keys; nil between batches
+	pending map[K]*batch[K, V] // batch each key being loaded belongs to
+
+	hits    atomic.Int64
+	misses  atomic.Int64
+	batches atomic.Int64
+}
+
+// LoaderStats counts how a loader's loads were served
+type LoaderStats struct {
+	Hits    int64 // answered from the cache or a fetch already pending
+	Misses  int64 // needed their key fetched
+	Batches int64 // fetches made
+}
+
+func (s LoaderStats) String() string {
+	return fmt.Sprintf("%d hits, %d misses, %d batches", s.Hits, s.Misses, s.Batches)
+}
+
+type result[V any] struct {
+	value V
+	err   error
+}
+
+// batch collects the keys requested during one wait window
+type batch[K comparable, V any] struct {
+	keys     []K
+	waiters  map[K][]chan result[V]
+	deadline time.Time // earliest deadline of the loads that started it; zero if none
+}
+
+// addDeadline lowers the batch's deadline to that of ctx, if it is earlier
+func (b *batch[K, V]) addDeadline(ctx context.Context) {
+	if deadline, ok := ctx.Deadline(); ok && (b.deadline.IsZero() || deadline.Before(b.deadline)) {
+		b.deadline = deadline
+	}
+}
+
+// NewLoader creates a loader that fetches with the given request context
+func NewLoader[K comparable, V any](ctx context.Context, fetch BatchFunc[K, V], wait time.Duration, maxBatch int) *Loader[K, V] {
+	return &Loader[K, V]{
+		ctx:      ctx,
+		fetch:    fetch,
+		wait:     wait,
+		maxBatch: maxBatch,
+		cache:    make(map[K]V),
+		pending:  make(map[K]*batch[K, V]),
+	}
+}
+
+// Load loads a single value by key with batching
+func (l *Loader[K, V]) Load(ctx context.Context, key K) (V, error) {
+	l.mutex.Lock()
+
+	// Check cache first
+	if cached, exists := l.cache[key]; exists {
+		l.mutex.Unlock()
+		l.hits.Add(1)
+		return cached, nil
+	}
+
+	// Wait for a batch already loading the key, or add it to the current
+	// batch, starting one if none is collecting
+	ch := make(chan result[V], 1)
+	if b, pending := l.pending[key]; pending {
+		b.waiters[key] = append(b.waiters[key], ch)
+		l.hits.Add(1)
+	} else {
+		if l.batch == nil {
+			l.batch = &batch[K, V]{waiters: make(map[K][]chan result[V])}
+			go l.startBatch(l.batch)
+		}
+		b := l.batch
+		b.keys = append(b.keys, key)
+		b.waiters[key] = append(b.waiters[key], ch)
+		b.addDeadline(ctx)
+		l.pending[key] = b
+		l.misses.Add(1)
+
+		// A full batch is fetched right away
+		if len(b.keys) >= l.maxBatch {
+			l.batch = nil
+			go l.dispatch(b)
+		}
+	}
+	l.mutex.Unlock()
+
+	select {
+	case res := <-ch:
+		return res.value, res.err
+	case <-ctx.Done():
+		var zero V
+		return zero, ctx.Err()
+	}
+}
+
+// LoadMany loads multiple values by keys
+func (l *Loader[K, V]) LoadMany(ctx context.Context, keys []K) ([]V, []error) {
+	values := make([]V, len(keys))
+	errors := make([]error, len(keys))
+
+	// Load concurrently so all keys land in the same batch
+	var wg sync.WaitGroup
+	for i, key := range keys {
+		wg.Add(1)
+		go func(i int, key K) {
+			defer wg.Done()
+			values[i], errors[i] = l.Load(ctx, key)
+		}(i, key)
+	}
+	wg.Wait()
+
+	return values, errors
+}
+
+// Prime adds a value to the cache
+func (l *Loader[K, V]) Prime(ctx context.Context, key K, value V) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.cache[key] = value
+}
+
+// Clear removes a value from the cache
+func (l *Loader[K, V]) Clear(ctx context.Context, key K) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	delete(l.cache, key)
+}
+
+// Stats reports how the loader's loads were served so far
+func (l *Loader[K, V]) Stats() LoaderStats {
+	return LoaderStats{
+		Hits:    l.hits.Load(),
+		Misses:  l.misses.Load(),
+		Batches: l.batches.Load(),
+	}
+}
+
+// startBatch fetches the batch once the wait window has passed, unless it
+// filled up and was fetched already
+func (l *Loader[K, V]) startBatch(b *batch[K, V]) {
+	timer := time.NewTimer(l.wait)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+	case <-l.ctx.Done():
+	}
+
+	l.mutex.Lock()
+	if l.batch != b {
+		l.mutex.Unlock()
+		return
+	}
+	l.batch = nil
+	l.mutex.Unlock()
+
+	l.dispatch(b)
+}
+
+// dispatch fetches every key of the batch in one call and answers its
+// waiters. The fetch gets the earliest deadline of the loads waiting for
+// it, such as an operation timeout, on top of the request context.
+func (l *Loader[K, V]) dispatch(b *batch[K, V]) {
+	values := make([]V, len(b.keys))
+	errors := make([]error, len(b.keys))
+
+	ctx := l.ctx
+	if !b.deadline.IsZero() {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithDeadline(ctx, b.deadline)
+		defer cancel()
+	}
+
+	if err := ctx.Err(); err != nil {
+		// The request is gone or out of time; don't fetch for it
+		for i := range errors {
+			errors[i] = err
+		}
+	} else {
+		l.batches.Add(1)
+		fetched, fetchErrors := l.fetch(ctx, b.keys)
+		if len(fetched) != len(b.keys) || len(fetchErrors) != len(b.keys) {
+			err := fmt.Errorf("batch fetch returned %d values and %d errors for %d keys",
+				len(fetched), len(fetchErrors), len(b.keys))
+			for i := range errors {
+				errors[i] = err
+			}
+		} else {
+			values, errors = fetched, fetchErrors
+		}
+	}
+
+	// Cache successful results; loads from now on no longer join this batch
+	l.mutex.Lock()
+	for i, key := range b.keys {
+		if errors[i] == nil {
+			l.cache[key] = values[i]
+		}
+		delete(l.pending, key)
+	}
+	l.mutex.Unlock()
+
+	// Send results to all waiters
+	for i, key := range b.keys {
+		res := result[V]{value: values[i], err: errors[i]}
+		for _, ch := range b.waiters[key] {
+			ch <- res
+			close(ch)
+		}
+	}
+}
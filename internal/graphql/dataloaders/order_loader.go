@@ -0,0 +1,155 @@
+package dataloaders
+
+import (
+	"context"
+
+	"learning/internal/common"
+	"learning/internal/order"
+)
+
+// OrderKey identifies one page of a user's orders
+type OrderKey struct {
+	UserID string
+	Status order.OrderStatus // OrderStatusUnspecified for all orders
+	Page   common.PageQuery
+}
+
+// OrderLoader provides batched loading of users' orders
+type OrderLoader = Loader[OrderKey, *order.UserOrders]
+
+// NewOrderLoader creates an OrderLoader for one request
+func NewOrderLoader(ctx context.Context, repo order.Repository) *OrderLoader {
+	fetch := func(ctx context.Context, keys []OrderKey) ([]*order.UserOrders, []error) {
+		return batchListOrders(ctx, repo, keys)
+	}
+	return NewLoader(ctx, fetch, DefaultWait, DefaultMaxBatch)
+}
+
+// OrderByIDLoader provides batched loading of orders by ID
+type OrderByIDLoader = Loader[string, *order.Order]
+
+// NewOrderByIDLoader creates an OrderByIDLoader for one request
+func NewOrderByIDLoader(ctx context.Context, repo order.Repository) *OrderByIDLoader {
+	fetch := func(ctx context.Context, ids []string) ([]*order.Order, []error) {
+		return batchGetOrders(ctx, repo, ids)
+	}
+	return NewLoader(ctx, fetch, DefaultWait, DefaultMaxBatch)
+}
+
+// HistoryLoader provides batched loading of orders' status histories
+type HistoryLoader = Loader[string, []*order.StatusTransition]
+
+// NewHistoryLoader creates a HistoryLoader for one request
+func NewHistoryLoader(ctx context.Context, repo order.Repository) *HistoryLoader {
+	fetch := func(ctx context.Context, ids []string) ([][]*order.StatusTransition, []error) {
+		return batchGetHistories(ctx, repo, ids)
+	}
+	return NewLoader(ctx, fetch, DefaultWait, DefaultMaxBatch)
+}
+
+// batchGetOrders fetches multiple orders in a single operation
+func batchGetOrders(ctx context.Context, repo order.Repository, ids []string) ([]*order.Order, []error) {
+	results := make([]*order.Order, len(ids))
+	errors := make([]error, len(ids))
+
+	found, _, err := repo.GetByIDs(ctx, ids)
+	if err != nil {
+		for i := range errors {
+			errors[i] = err
+		}
+		return results, errors
+	}
+
+	// Build result slice in the same order as input IDs
+	orderMap := make(map[string]*order.Order, len(found))
+	for _, o := range found {
+		orderMap[o.ID] = o
+	}
+	for i, id := range ids {
+		results[i] = orderMap[id]
+		if results[i] == nil {
+			errors[i] = order.ErrOrderNotFound
+		}
+	}
+
+	return results, errors
+}
+
+// batchGetHistories fetches the histories of multiple orders in a single
+// operation
+func batchGetHistories(ctx context.Context, repo order.Repository, ids []string) ([][]*order.StatusTransition, []error) {
+	results := make([][]*order.StatusTransition, len(ids))
+	errors := make([]error, len(ids))
+
+	histories, err := repo.GetHistories(ctx, ids)
+	if err != nil {
+		for i := range errors {
+			errors[i] = err
+		}
+		return results, errors
+	}
+
+	for i, id := range ids {
+		history, found := histories[id]
+		if !found {
+			errors[i] = order.ErrOrderNotFound
+		}
+		results[i] = history
+	}
+
+	return results, errors
+}
+
+// orderPage is the part of an OrderKey shared by the keys fetched together
+type orderPage struct {
+	status order.OrderStatus
+	query  common.PageQuery
+}
+
+// batchListOrders fetches the orders of multiple users with one call for
+// each distinct filter and page among the keys
+func batchListOrders(ctx context.Context, repo order.Repository, keys []OrderKey) ([]*order.UserOrders, []error) {
+	results := make([]*order.UserOrders, len(keys))
+	errors := make([]error, len(keys))
+
+	// Group the keys by page, remembering where each key's result goes
+	groups := make(map[orderPage][]int)
+	var pages []orderPage
+	for i, key := range keys {
+		page := orderPage{status: key.Status, query: key.Page}
+		if _, exists := groups[page]; !exists {
+			pages = append(pages, page)
+		}
+		groups[page] = append(groups[page], i)
+	}
+
+	for _, page := range pages {
+		indexes := groups[page]
+		userIDs := make([]string, len(indexes))
+		for j, i := range indexes {
+			userIDs[j] = keys[i].UserID
+		}
+
+		found, err := repo.ListByUsers(ctx, userIDs, page.status, page.query)
+		if err != nil {
+			for _, i := range indexes {
+				errors[i] = err
+			}
+			continue
+		}
+
+		byUser := make(map[string]*order.UserOrders, len(found))
+		for _, result := range found {
+			byUser[result.UserID] = result
+		}
+		for _, i := range indexes {
+			results[i] = byUser[keys[i].UserID]
+			if results[i] == nil {
+				// Users without orders may be left out
+				results[i] = &order.UserOrders{UserID: keys[i].UserID, Orders: []*order.Order{}}
+			}
+		}
+	}
+
+	return results, errors
+}
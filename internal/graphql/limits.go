@@ -0,0 +1,163 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/errcode"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+
+	"learning/internal/common"
+	"learning/internal/graphql/generated"
+	"learning/internal/graphql/models"
+)
+
+// Error codes of operations rejected or cut short by a limit; the
+// complexity code is the one extension.ComplexityLimit uses
+const (
+	errDepthLimit       = "DEPTH_LIMIT_EXCEEDED"
+	errComplexityLimit  = "COMPLEXITY_LIMIT_EXCEEDED"
+	errOperationTimeout = "OPERATION_TIMEOUT"
+)
+
+func init() {
+	// Operations over a limit are rejected before they run, like invalid ones
+	errcode.RegisterErrorType(errDepthLimit, errcode.KindProtocol)
+	errcode.RegisterErrorType(errComplexityLimit, errcode.KindProtocol)
+}
+
+// setComplexity weights connection fields by the page size they ask for, so
+// nested lists count for every node they can fan out to
+func setComplexity(c *generated.ComplexityRoot) {
+	c.Query.Users = func(childComplexity int, first *int, after *string, last *int, before *string, search *string) int {
+		return connectionComplexity(childComplexity, first, last)
+	}
+	c.Query.Products = func(childComplexity int, first *int, after *string, last *int, before *string, category *string, search *string, inStock *bool) int {
+		return connectionComplexity(childComplexity, first, last)
+	}
+	c.Query.Orders = func(childComplexity int, first *int, after *string, last *int, before *string, userID *string, status *models.OrderStatus, dateFrom *time.Time, dateTo *time.Time) int {
+		return connectionComplexity(childComplexity, first, last)
+	}
+	c.Query.Nodes = func(childComplexity int, ids []string) int {
+		return 1 + childComplexity*len(ids)
+	}
+	c.User.Orders = func(childComplexity int, first *int, after *string, last *int, before *string, status *models.OrderStatus) int {
+		return connectionComplexity(childComplexity, first, last)
+	}
+}
+
+// connectionComplexity is the complexity of a connection field returning
+// up to first or last nodes, the default page size when neither is given
+func connectionComplexity(childComplexity int, first, last *int) int {
+	size := 0
+	if first != nil {
+		size = *first
+	} else if last != nil {
+		size = *last
+	}
+	return 1 + childComplexity*common.PageSize(size)
+}
+
+// depthLimit rejects operations whose fields nest deeper than max
+type depthLimit struct {
+	max int
+}
+
+var _ interface {
+	graphql.HandlerExtension
+	graphql.OperationContextMutator
+} = depthLimit{}
+
+// ExtensionName names the extension
+func (d depthLimit) ExtensionName() string {
+	return "DepthLimit"
+}
+
+// Validate accepts any schema
+func (d depthLimit) Validate(schema graphql.ExecutableSchema) error {
+	return nil
+}
+
+// MutateOperationContext checks the operation's depth
+func (d depthLimit) MutateOperationContext(ctx context.Context, opCtx *graphql.OperationContext) *gqlerror.Error {
+	depth := selectionDepth(opCtx.Operation.SelectionSet, opCtx.Doc.Fragments)
+	if depth > d.max {
+		err := gqlerror.Errorf("operation has depth %d, which exceeds the limit of %d", depth, d.max)
+		errcode.Set(err, errDepthLimit)
+		return err
+	}
+	return nil
+}
+
+// selectionDepth returns how deeply the fields of a selection set nest,
+// looking through fragments. Introspection fields don't count, so tools can
+// always load the schema.
+func selectionDepth(selections ast.SelectionSet, fragments ast.FragmentDefinitionList) int {
+	deepest := 0
+	for _, selection := range selections {
+		depth := 0
+		switch s := selection.(type) {
+		case *ast.Field:
+			if strings.HasPrefix(s.Name, "__") {
+				continue
+			}
+			depth = 1 + selectionDepth(s.SelectionSet, fragments)
+		case *ast.InlineFragment:
+			depth = selectionDepth(s.SelectionSet, fragments)
+		case *ast.FragmentSpread:
+			// Validation has already ruled out fragment cycles
+			if fragment := fragments.ForName(s.Name); fragment != nil {
+				depth = selectionDepth(fragment.SelectionSet, fragments)
+			}
+		}
+		deepest = max(deepest, depth)
+	}
+	return deepest
+}
+
+// operationTimeout cuts queries and mutations short after timeout;
+// subscriptions last for as long as the client stays subscribed
+type operationTimeout struct {
+	timeout time.Duration
+}
+
+var _ interface {
+	graphql.HandlerExtension
+	graphql.ResponseInterceptor
+} = operationTimeout{}
+
+// ExtensionName names the extension
+func (t operationTimeout) ExtensionName() string {
+	return "OperationTimeout"
+}
+
+// Validate accepts any schema
+func (t operationTimeout) Validate(schema graphql.ExecutableSchema) error {
+	return nil
+}
+
+// InterceptResponse runs the operation with a deadline. Resolvers still
+// running at the deadline see their context cancelled, and the response
+// says the operation timed out.
+func (t operationTimeout) InterceptResponse(ctx context.Context, next graphql.ResponseHandler) *graphql.Response {
+	// Requests rejected before they run have no operation to time out
+	operation := graphql.GetOperationContext(ctx).Operation
+	if operation == nil || operation.Operation == ast.Subscription {
+		return next(ctx)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, t.timeout)
+	defer cancel()
+
+	resp := next(ctx)
+	if resp != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		err := gqlerror.Errorf("operation timed out after %v", t.timeout)
+		errcode.Set(err, errOperationTimeout)
+		resp.Errors = append(resp.Errors, err)
+	}
+	return resp
+}
@@ -0,0 +1,44 @@
+package models
+
+import (
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// DateTimeLayout is RFC 3339 with all nine digits of nanoseconds, so
+// timestamps have a fixed width and sort as strings
+const DateTimeLayout = "2006-01-02T15:04:05.000000000Z07:00"
+
+// MarshalDateTime writes a DateTime in its own time zone
+func MarshalDateTime(t time.Time) graphql.Marshaler {
+	return graphql.WriterFunc(func(w io.Writer) {
+		io.WriteString(w, strconv.Quote(t.Format(DateTimeLayout)))
+	})
+}
+
+// UnmarshalDateTime reads a DateTime, an RFC 3339 string with an optional
+// fraction of a second. Anything else, dates without a time or times
+// without an offset included, is rejected.
+func UnmarshalDateTime(v any) (time.Time, error) {
+	s, ok := v.(string)
+	if !ok {
+		return time.Time{}, invalidDateTime("DateTime must be a string, got %T", v)
+	}
+
+	t, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return time.Time{}, invalidDateTime("invalid DateTime %q: expected RFC 3339, like 2006-01-02T15:04:05.999999999Z", s)
+	}
+	return t, nil
+}
+
+// invalidDateTime reports a malformed DateTime to the client
+func invalidDateTime(format string, args ...any) *gqlerror.Error {
+	err := gqlerror.Errorf(format, args...)
+	err.Extensions = map[string]any{"code": ErrorCodeValidationError}
+	return err
+}
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"io"
 	"strconv"
+	"time"
 )
 
 type Node interface {
@@ -86,16 +87,21 @@ type Mutation struct {
 }
 
 type Order struct {
-	ID             string       `json:"id"`
-	User           *User        `json:"user"`
-	Items          []*OrderItem `json:"items"`
-	TotalAmount    float64      `json:"totalAmount"`
-	Status         OrderStatus  `json:"status"`
-	CreatedAt      string       `json:"createdAt"`
-	UpdatedAt      string       `json:"updatedAt"`
-	ItemCount      int          `json:"itemCount"`
-	CanBeCancelled bool         `json:"canBeCancelled"`
-}
+	ID             string               `json:"id"`
+	User           *User                `json:"user"`
+	Items          []*OrderItem         `json:"items"`
+	TotalAmount    float64              `json:"totalAmount"`
+	Status         OrderStatus          `json:"status"`
+	CreatedAt      time.Time            `json:"createdAt"`
+	UpdatedAt      time.Time            `json:"updatedAt"`
+	ItemCount      int                  `json:"itemCount"`
+	CanBeCancelled bool                 `json:"canBeCancelled"`
+	History        []*OrderStatusChange `json:"history"`
+	UserID         string               `json:"-"`
+}
+
+func (Order) IsNode()            {}
+func (this Order) GetID() string { return this.ID }
 
 type OrderConnection struct {
 	Edges      []*OrderEdge `json:"edges"`
@@ -121,6 +127,7 @@ type OrderItem struct {
 	ProductPrice float64  `json:"productPrice"`
 	Quantity     int      `json:"quantity"`
 	Total        float64  `json:"total"`
+	ProductID    string   `json:"-"`
 }
 
 type OrderItemInput struct {
@@ -128,6 +135,14 @@ type OrderItemInput struct {
 	Quantity  int    `json:"quantity"`
 }
 
+type OrderStatusChange struct {
+	From      *OrderStatus `json:"from,omitempty"`
+	To        OrderStatus  `json:"to"`
+	Timestamp time.Time    `json:"timestamp"`
+	Actor     string       `json:"actor"`
+	Reason    string       `json:"reason"`
+}
+
 type PageInfo struct {
 	HasNextPage     bool    `json:"hasNextPage"`
 	HasPreviousPage bool    `json:"hasPreviousPage"`
@@ -143,17 +158,22 @@ type PaginationInput struct {
 }
 
 type Product struct {
-	ID          string      `json:"id"`
-	Name        string      `json:"name"`
-	Description string      `json:"description"`
-	Price       float64     `json:"price"`
-	Stock       int         `json:"stock"`
-	Category    string      `json:"category"`
-	CreatedAt   string      `json:"createdAt"`
-	UpdatedAt   string      `json:"updatedAt"`
-	IsInStock   bool        `json:"isInStock"`
-	StockStatus StockStatus `json:"stockStatus"`
-}
+	ID             string      `json:"id"`
+	Name           string      `json:"name"`
+	Description    string      `json:"description"`
+	Price          float64     `json:"price"`
+	Stock          int         `json:"stock"`
+	ReservedStock  int         `json:"reservedStock"`
+	AvailableStock int         `json:"availableStock"`
+	Category       string      `json:"category"`
+	CreatedAt      time.Time   `json:"createdAt"`
+	UpdatedAt      time.Time   `json:"updatedAt"`
+	IsInStock      bool        `json:"isInStock"`
+	StockStatus    StockStatus `json:"stockStatus"`
+}
+
+func (Product) IsNode()            {}
+func (this Product) GetID() string { return this.ID }
 
 type ProductCategory struct {
 	Name  string `json:"name"`
@@ -191,6 +211,7 @@ type Subscription struct {
 type UpdateOrderStatusInput struct {
 	OrderID string      `json:"orderId"`
 	Status  OrderStatus `json:"status"`
+	Reason  *string     `json:"reason,omitempty"`
 }
 
 type UpdateOrderStatusPayload struct {
@@ -239,11 +260,14 @@ type User struct {
 	Name      string           `json:"name"`
 	Email     string           `json:"email"`
 	Phone     string           `json:"phone"`
-	CreatedAt string           `json:"createdAt"`
-	UpdatedAt string           `json:"updatedAt"`
+	CreatedAt time.Time        `json:"createdAt"`
+	UpdatedAt time.Time        `json:"updatedAt"`
 	Orders    *OrderConnection `json:"orders"`
 }
 
+func (User) IsNode()            {}
+func (this User) GetID() string { return this.ID }
+
 type UserConnection struct {
 	Edges      []*UserEdge `json:"edges"`
 	PageInfo   *PageInfo   `json:"pageInfo"`
@@ -264,24 +288,26 @@ type UserError struct {
 type ErrorCode string
 
 const (
-	ErrorCodeValidationError ErrorCode = "VALIDATION_ERROR"
-	ErrorCodeNotFound        ErrorCode = "NOT_FOUND"
-	ErrorCodeAlreadyExists   ErrorCode = "ALREADY_EXISTS"
-	ErrorCodeUnauthorized    ErrorCode = "UNAUTHORIZED"
-	ErrorCodeInternalError   ErrorCode = "INTERNAL_ERROR"
+	ErrorCodeValidationError    ErrorCode = "VALIDATION_ERROR"
+	ErrorCodeNotFound           ErrorCode = "NOT_FOUND"
+	ErrorCodeAlreadyExists      ErrorCode = "ALREADY_EXISTS"
+	ErrorCodeFailedPrecondition ErrorCode = "FAILED_PRECONDITION"
+	ErrorCodeUnauthorized       ErrorCode = "UNAUTHORIZED"
+	ErrorCodeInternalError      ErrorCode = "INTERNAL_ERROR"
 )
 
 var AllErrorCode = []ErrorCode{
 	ErrorCodeValidationError,
 	ErrorCodeNotFound,
 	ErrorCodeAlreadyExists,
+	ErrorCodeFailedPrecondition,
 	ErrorCodeUnauthorized,
 	ErrorCodeInternalError,
 }
 
 func (e ErrorCode) IsValid() bool {
 	switch e {
-	case ErrorCodeValidationError, ErrorCodeNotFound, ErrorCodeAlreadyExists, ErrorCodeUnauthorized, ErrorCodeInternalError:
+	case ErrorCodeValidationError, ErrorCodeNotFound, ErrorCodeAlreadyExists, ErrorCodeFailedPrecondition, ErrorCodeUnauthorized, ErrorCodeInternalError:
 		return true
 	}
 	return false
@@ -0,0 +1,103 @@
+package graphql
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/errcode"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// errUntrustedDocument is the error code of operations missing from the
+// trusted documents
+const errUntrustedDocument = "UNTRUSTED_DOCUMENT"
+
+func init() {
+	errcode.RegisterErrorType(errUntrustedDocument, errcode.KindProtocol)
+}
+
+// LoadTrustedDocuments reads a trusted documents manifest: a JSON object
+// mapping the hex SHA-256 hash of each document to the document
+func LoadTrustedDocuments(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read trusted documents: %w", err)
+	}
+
+	var documents map[string]string
+	if err := json.Unmarshal(data, &documents); err != nil {
+		return nil, fmt.Errorf("failed to parse trusted documents %s: %w", path, err)
+	}
+	if documents == nil {
+		documents = map[string]string{}
+	}
+
+	// Documents sent in full are looked up by their hash, so it has to match
+	for hash, document := range documents {
+		if documentHash(document) != hash {
+			return nil, fmt.Errorf("trusted document %s does not match its SHA-256 hash", hash)
+		}
+	}
+
+	return documents, nil
+}
+
+// trustedDocuments only lets operations from a manifest run. Clients send the
+// hash of a document the way automatic persisted queries do, or the document
+// itself.
+type trustedDocuments struct {
+	documents map[string]string
+}
+
+var _ interface {
+	graphql.HandlerExtension
+	graphql.OperationParameterMutator
+} = trustedDocuments{}
+
+// ExtensionName names the extension
+func (t trustedDocuments) ExtensionName() string {
+	return "TrustedDocuments"
+}
+
+// Validate accepts any schema
+func (t trustedDocuments) Validate(schema graphql.ExecutableSchema) error {
+	return nil
+}
+
+// MutateOperationParameters replaces a hash with its document, and rejects
+// operations that aren't trusted
+func (t trustedDocuments) MutateOperationParameters(ctx context.Context, params *graphql.RawParams) *gqlerror.Error {
+	hash := persistedQueryHash(params)
+	if params.Query != "" {
+		hash = documentHash(params.Query)
+	}
+
+	document, ok := t.documents[hash]
+	if !ok {
+		err := gqlerror.Errorf("operation is not one of the trusted documents")
+		errcode.Set(err, errUntrustedDocument)
+		return err
+	}
+
+	params.Query = document
+	return nil
+}
+
+// persistedQueryHash returns the hash an automatic persisted query request
+// carries, or "" for other requests
+func persistedQueryHash(params *graphql.RawParams) string {
+	persistedQuery, _ := params.Extensions["persistedQuery"].(map[string]any)
+	hash, _ := persistedQuery["sha256Hash"].(string)
+	return hash
+}
+
+// documentHash returns the hex SHA-256 hash of a document
+func documentHash(document string) string {
+	sum := sha256.Sum256([]byte(document))
+	return hex.EncodeToString(sum[:])
+}
@@ -1,8 +1,16 @@
 package resolvers
 
 import (
-	"strconv"
+	"context"
+	"slices"
+	"sync"
+	"time"
+	_ "time/tzdata" // time zones for hosts without a zoneinfo database
 
+	"github.com/99designs/gqlgen/graphql"
+
+	"learning/internal/common"
+	"learning/internal/graphql/dataloaders"
 	"learning/internal/graphql/models"
 	"learning/internal/order"
 	"learning/internal/product"
@@ -16,21 +24,13 @@ func domainUserToGraphQL(u *user.User) *models.User {
 	}
 
 	return &models.User{
-		ID:        u.ID,
+		ID:        toGlobalID(userType, u.ID),
 		Name:      u.Name,
 		Email:     u.Email,
 		Phone:     u.Phone,
-		CreatedAt: u.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
-		UpdatedAt: u.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
-	}
-}
-
-func domainUsersToGraphQL(users []*user.User) []*models.User {
-	result := make([]*models.User, len(users))
-	for i, u := range users {
-		result[i] = domainUserToGraphQL(u)
+		CreatedAt: u.CreatedAt,
+		UpdatedAt: u.UpdatedAt,
 	}
-	return result
 }
 
 // Product converters
@@ -40,30 +40,24 @@ func domainProductToGraphQL(p *product.Product) *models.Product {
 	}
 
 	return &models.Product{
-		ID:          p.ID,
-		Name:        p.Name,
-		Description: p.Description,
-		Price:       p.Price,
-		Stock:       int(p.Stock),
-		Category:    p.Category,
-		CreatedAt:   p.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
-		UpdatedAt:   p.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
-		IsInStock:   p.Stock > 0,
-		StockStatus: getStockStatus(p.Stock),
-	}
-}
-
-func domainProductsToGraphQL(products []*product.Product) []*models.Product {
-	result := make([]*models.Product, len(products))
-	for i, p := range products {
-		result[i] = domainProductToGraphQL(p)
+		ID:             toGlobalID(productType, p.ID),
+		Name:           p.Name,
+		Description:    p.Description,
+		Price:          p.Price,
+		Stock:          int(p.Stock),
+		ReservedStock:  int(p.Reserved),
+		AvailableStock: int(p.Available()),
+		Category:       p.Category,
+		CreatedAt:      p.CreatedAt,
+		UpdatedAt:      p.UpdatedAt,
+		IsInStock:      p.Available() > 0,
+		StockStatus:    getStockStatus(p.Available()),
 	}
-	return result
 }
 
 func getStockStatus(stock int32) models.StockStatus {
 	switch {
-	case stock == 0:
+	case stock <= 0:
 		return models.StockStatusOutOfStock
 	case stock <= 10:
 		return models.StockStatusLowStock
@@ -79,22 +73,16 @@ func domainOrderToGraphQL(o *order.Order) *models.Order {
 	}
 
 	return &models.Order{
-		ID:             o.ID,
+		ID:             toGlobalID(orderType, o.ID),
+		UserID:         o.UserID,
+		Items:          domainOrderItemsToGraphQL(o.Items),
 		TotalAmount:    o.TotalAmount,
 		Status:         domainOrderStatusToGraphQL(o.Status),
-		CreatedAt:      o.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
-		UpdatedAt:      o.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		CreatedAt:      o.CreatedAt,
+		UpdatedAt:      o.UpdatedAt,
 		ItemCount:      len(o.Items),
-		CanBeCancelled: o.Status == order.OrderStatusPending || o.Status == order.OrderStatusConfirmed,
-	}
-}
-
-func domainOrdersToGraphQL(orders []*order.Order) []*models.Order {
-	result := make([]*models.Order, len(orders))
-	for i, o := range orders {
-		result[i] = domainOrderToGraphQL(o)
+		CanBeCancelled: o.Status.IsCancellable(),
 	}
-	return result
 }
 
 func domainOrderItemToGraphQL(item *order.OrderItem) *models.OrderItem {
@@ -104,6 +92,7 @@ func domainOrderItemToGraphQL(item *order.OrderItem) *models.OrderItem {
 
 	return &models.OrderItem{
 		ID:           item.ID,
+		ProductID:    item.ProductID,
 		ProductName:  item.ProductName,
 		ProductPrice: item.ProductPrice,
 		Quantity:     int(item.Quantity),
@@ -119,6 +108,26 @@ func domainOrderItemsToGraphQL(items []*order.OrderItem) []*models.OrderItem {
 	return result
 }
 
+func domainStatusTransitionsToGraphQL(history []*order.StatusTransition) []*models.OrderStatusChange {
+	result := make([]*models.OrderStatusChange, len(history))
+	for i, t := range history {
+		var from *models.OrderStatus
+		if t.From != order.OrderStatusUnspecified {
+			status := domainOrderStatusToGraphQL(t.From)
+			from = &status
+		}
+
+		result[i] = &models.OrderStatusChange{
+			From:      from,
+			To:        domainOrderStatusToGraphQL(t.To),
+			Timestamp: t.Timestamp,
+			Actor:     t.Actor,
+			Reason:    t.Reason,
+		}
+	}
+	return result
+}
+
 func domainOrderStatusToGraphQL(status order.OrderStatus) models.OrderStatus {
 	switch status {
 	case order.OrderStatusPending:
@@ -157,6 +166,20 @@ func graphQLOrderStatusToDomain(status models.OrderStatus) order.OrderStatus {
 	}
 }
 
+// DataLoader helpers
+
+// forgetOrderProducts drops the products of an order from the request's
+// ProductLoader, since creating or cancelling the order changed their stock
+func forgetOrderProducts(ctx context.Context, o *order.Order) {
+	loaders := dataloaders.For(ctx)
+	if loaders == nil || o == nil {
+		return
+	}
+	for _, item := range o.Items {
+		loaders.ProductLoader.Clear(ctx, item.ProductID)
+	}
+}
+
 // Error helpers
 func createUserError(field string, message string, code models.ErrorCode) *models.UserError {
 	return &models.UserError{
@@ -182,96 +205,128 @@ func createOrderError(field string, message string, code models.ErrorCode) *mode
 	}
 }
 
+// Time helpers
+
+// timeZones caches the time zones timestamps were asked for by name
+var timeZones sync.Map
+
+// inTimeZone returns t in the named IANA time zone, or in UTC when no zone
+// is given
+func inTimeZone(t time.Time, timeZone *string) (*time.Time, error) {
+	if timeZone == nil {
+		t = t.UTC()
+		return &t, nil
+	}
+
+	location, ok := timeZones.Load(*timeZone)
+	if !ok {
+		loaded, err := time.LoadLocation(*timeZone)
+		if err != nil || *timeZone == "" || *timeZone == "Local" {
+			return nil, inputErrorf("unknown time zone %q", *timeZone)
+		}
+		location, _ = timeZones.LoadOrStore(*timeZone, loaded)
+	}
+
+	t = t.In(location.(*time.Location))
+	return &t, nil
+}
+
 // Pagination helpers
-func createUserConnection(users []*models.User, total int, page int, pageSize int) *models.UserConnection {
-	edges := make([]*models.UserEdge, len(users))
-	for i, user := range users {
-		cursor := strconv.Itoa((page-1)*pageSize + i + 1)
-		edges[i] = &models.UserEdge{
-			Node:   user,
-			Cursor: cursor,
+
+// pageArgs builds the page query for the arguments of a Relay connection.
+// The list is only counted when the connection's totalCount is selected.
+func pageArgs(ctx context.Context, first *int, after *string, last *int, before *string) (common.PageQuery, error) {
+	query := common.PageQuery{SkipTotal: !slices.Contains(graphql.CollectAllFields(ctx), "totalCount")}
+	switch {
+	case first != nil && last != nil:
+		return common.PageQuery{}, inputErrorf("first and last must not be used together")
+	case last != nil:
+		query.Limit = common.PageSize(*last)
+		query.FromEnd = true
+	case first != nil:
+		query.Limit = common.PageSize(*first)
+	default:
+		query.Limit = common.DefaultPageSize
+	}
+
+	var err error
+	if after != nil {
+		if query.After, err = common.DecodeCursor(*after); err != nil {
+			return common.PageQuery{}, inputErrorf("invalid cursor %q", *after)
+		}
+	}
+	if before != nil {
+		if query.Before, err = common.DecodeCursor(*before); err != nil {
+			return common.PageQuery{}, inputErrorf("invalid cursor %q", *before)
 		}
 	}
 
-	hasNextPage := (page * pageSize) < total
-	hasPreviousPage := page > 1
+	return query, nil
+}
 
-	var startCursor, endCursor *string
-	if len(edges) > 0 {
-		startCursor = &edges[0].Cursor
-		endCursor = &edges[len(edges)-1].Cursor
+// createPageInfo builds the Relay page info of a page of edges
+func createPageInfo(info common.PageInfo, cursors []string) *models.PageInfo {
+	pageInfo := &models.PageInfo{
+		HasNextPage:     info.HasNext,
+		HasPreviousPage: info.HasPrevious,
+	}
+	if len(cursors) > 0 {
+		pageInfo.StartCursor = &cursors[0]
+		pageInfo.EndCursor = &cursors[len(cursors)-1]
+	}
+	return pageInfo
+}
+
+func createUserConnection(users []*user.User, info common.PageInfo) *models.UserConnection {
+	edges := make([]*models.UserEdge, len(users))
+	cursors := make([]string, len(users))
+	for i, u := range users {
+		cursors[i] = common.EncodeCursor(u.Cursor())
+		edges[i] = &models.UserEdge{
+			Node:   domainUserToGraphQL(u),
+			Cursor: cursors[i],
+		}
 	}
 
 	return &models.UserConnection{
-		Edges: edges,
-		PageInfo: &models.PageInfo{
-			HasNextPage:     hasNextPage,
-			HasPreviousPage: hasPreviousPage,
-			StartCursor:     startCursor,
-			EndCursor:       endCursor,
-		},
-		TotalCount: total,
+		Edges:      edges,
+		PageInfo:   createPageInfo(info, cursors),
+		TotalCount: info.Total,
 	}
 }
 
-func createProductConnection(products []*models.Product, total int, page int, pageSize int) *models.ProductConnection {
+func createProductConnection(products []*product.Product, info common.PageInfo) *models.ProductConnection {
 	edges := make([]*models.ProductEdge, len(products))
-	for i, product := range products {
-		cursor := strconv.Itoa((page-1)*pageSize + i + 1)
+	cursors := make([]string, len(products))
+	for i, p := range products {
+		cursors[i] = common.EncodeCursor(p.Cursor())
 		edges[i] = &models.ProductEdge{
-			Node:   product,
-			Cursor: cursor,
+			Node:   domainProductToGraphQL(p),
+			Cursor: cursors[i],
 		}
 	}
 
-	hasNextPage := (page * pageSize) < total
-	hasPreviousPage := page > 1
-
-	var startCursor, endCursor *string
-	if len(edges) > 0 {
-		startCursor = &edges[0].Cursor
-		endCursor = &edges[len(edges)-1].Cursor
-	}
-
 	return &models.ProductConnection{
-		Edges: edges,
-		PageInfo: &models.PageInfo{
-			HasNextPage:     hasNextPage,
-			HasPreviousPage: hasPreviousPage,
-			StartCursor:     startCursor,
-			EndCursor:       endCursor,
-		},
-		TotalCount: total,
+		Edges:      edges,
+		PageInfo:   createPageInfo(info, cursors),
+		TotalCount: info.Total,
 	}
 }
 
-func createOrderConnection(orders []*models.Order, total int, page int, pageSize int) *models.OrderConnection {
+func createOrderConnection(orders []*order.Order, info common.PageInfo) *models.OrderConnection {
 	edges := make([]*models.OrderEdge, len(orders))
-	for i, order := range orders {
-		cursor := strconv.Itoa((page-1)*pageSize + i + 1)
+	cursors := make([]string, len(orders))
+	for i, o := range orders {
+		cursors[i] = common.EncodeCursor(o.Cursor())
 		edges[i] = &models.OrderEdge{
-			Node:   order,
-			Cursor: cursor,
+			Node:   domainOrderToGraphQL(o),
+			Cursor: cursors[i],
 		}
 	}
 
-	hasNextPage := (page * pageSize) < total
-	hasPreviousPage := page > 1
-
-	var startCursor, endCursor *string
-	if len(edges) > 0 {
-		startCursor = &edges[0].Cursor
-		endCursor = &edges[len(edges)-1].Cursor
-	}
-
 	return &models.OrderConnection{
-		Edges: edges,
-		PageInfo: &models.PageInfo{
-			HasNextPage:     hasNextPage,
-			HasPreviousPage: hasPreviousPage,
-			StartCursor:     startCursor,
-			EndCursor:       endCursor,
-		},
-		TotalCount: total,
+		Edges:      edges,
+		PageInfo:   createPageInfo(info, cursors),
+		TotalCount: info.Total,
 	}
 }
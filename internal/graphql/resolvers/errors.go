@@ -0,0 +1,144 @@
+package resolvers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"runtime/debug"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/google/uuid"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"learning/internal/auth"
+	"learning/internal/graphql/models"
+	"learning/internal/order"
+	"learning/internal/product"
+	"learning/internal/user"
+)
+
+// inputError is a problem with a field's arguments, for the client to fix
+type inputError struct {
+	message string
+}
+
+func (e *inputError) Error() string {
+	return e.message
+}
+
+// inputErrorf formats an inputError
+func inputErrorf(format string, args ...any) error {
+	return &inputError{message: fmt.Sprintf(format, args...)}
+}
+
+// ErrorCode returns the code of an input, domain or gRPC status error, or
+// INTERNAL_ERROR for any other error
+func ErrorCode(err error) models.ErrorCode {
+	var inputErr *inputError
+	var authErr *authError
+	var userValidationErr *user.ValidationError
+	var productValidationErr *product.ValidationError
+	var orderValidationErr *order.ValidationError
+	switch {
+	case errors.As(err, &inputErr), errors.As(err, &userValidationErr),
+		errors.As(err, &productValidationErr), errors.As(err, &orderValidationErr):
+		return models.ErrorCodeValidationError
+	case errors.Is(err, user.ErrUserNotFound), errors.Is(err, product.ErrProductNotFound),
+		errors.Is(err, product.ErrReservationNotFound), errors.Is(err, order.ErrOrderNotFound):
+		return models.ErrorCodeNotFound
+	case errors.Is(err, user.ErrUserAlreadyExists), errors.Is(err, product.ErrProductAlreadyExists),
+		errors.Is(err, order.ErrOrderAlreadyExists):
+		return models.ErrorCodeAlreadyExists
+	case errors.Is(err, product.ErrInsufficientStock), errors.Is(err, product.ErrReservationClosed),
+		errors.Is(err, order.ErrInvalidStatusTransition), errors.Is(err, order.ErrStatusConflict),
+		errors.Is(err, errWebSocketRequired):
+		return models.ErrorCodeFailedPrecondition
+	case errors.As(err, &authErr), errors.Is(err, auth.ErrPermissionDenied):
+		return models.ErrorCodeUnauthorized
+	}
+
+	if st, ok := status.FromError(err); ok {
+		switch st.Code() {
+		case codes.InvalidArgument:
+			return models.ErrorCodeValidationError
+		case codes.NotFound:
+			return models.ErrorCodeNotFound
+		case codes.AlreadyExists:
+			return models.ErrorCodeAlreadyExists
+		case codes.FailedPrecondition, codes.Aborted, codes.OutOfRange:
+			return models.ErrorCodeFailedPrecondition
+		case codes.Unauthenticated, codes.PermissionDenied:
+			return models.ErrorCodeUnauthorized
+		}
+	}
+
+	return models.ErrorCodeInternalError
+}
+
+// errorMessage returns the message of an error for the client. The text of
+// internal errors is logged under a correlation ID, which the client gets
+// instead.
+func errorMessage(err error) string {
+	if ErrorCode(err) == models.ErrorCodeInternalError {
+		return internalError(nil, err).Message
+	}
+	if st, ok := status.FromError(err); ok {
+		return st.Message()
+	}
+	return err.Error()
+}
+
+// PresentError turns an error into the one the client sees. Errors built
+// for clients, by gqlgen or the server's extensions, are shown as they are;
+// input, domain and gRPC status errors get the matching ErrorCode; anything
+// else is hidden behind a correlation ID.
+func PresentError(ctx context.Context, err error) *gqlerror.Error {
+	gqlErr := graphql.DefaultErrorPresenter(ctx, err)
+	if gqlErr.Err == nil {
+		return gqlErr
+	}
+
+	code := ErrorCode(gqlErr.Err)
+	if code == models.ErrorCodeInternalError {
+		presented := internalError(gqlErr.Path, gqlErr.Err)
+		presented.Locations = gqlErr.Locations
+		return presented
+	}
+
+	return &gqlerror.Error{
+		Message:    errorMessage(gqlErr.Err),
+		Path:       gqlErr.Path,
+		Locations:  gqlErr.Locations,
+		Extensions: map[string]any{"code": code},
+	}
+}
+
+// Recover turns a panic in a resolver into an error on its field, logging
+// the panic and its stack under a correlation ID
+func Recover(ctx context.Context, p any) error {
+	return internalError(graphql.GetPath(ctx), fmt.Sprintf("panic: %v\n%s", p, debug.Stack()))
+}
+
+// internalError logs the cause of an internal error under a new correlation
+// ID, and returns the error the client gets in its place
+func internalError(path ast.Path, cause any) *gqlerror.Error {
+	correlationID := uuid.NewString()
+	if len(path) > 0 {
+		log.Printf("GraphQL internal error %s at %s: %v", correlationID, path, cause)
+	} else {
+		log.Printf("GraphQL internal error %s: %v", correlationID, cause)
+	}
+
+	return &gqlerror.Error{
+		Message: fmt.Sprintf("internal error, reference %s", correlationID),
+		Path:    path,
+		Extensions: map[string]any{
+			"code":          models.ErrorCodeInternalError,
+			"correlationId": correlationID,
+		},
+	}
+}
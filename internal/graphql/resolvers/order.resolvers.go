@@ -6,56 +6,304 @@ package resolvers
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"learning/internal/graphql/dataloaders"
+	"learning/internal/graphql/generated"
 	"learning/internal/graphql/models"
+	"learning/internal/order"
+	"time"
 )
 
 // CreateOrder is the resolver for the createOrder field.
 func (r *mutationResolver) CreateOrder(ctx context.Context, input models.CreateOrderInput) (*models.CreateOrderPayload, error) {
-	panic(fmt.Errorf("not implemented: CreateOrder - createOrder"))
+	// Validate input
+	var inputErrors []*models.OrderError
+
+	userID, err := localID(userType, input.UserID)
+	if input.UserID == "" {
+		inputErrors = append(inputErrors, createOrderError("userId", "User ID is required", models.ErrorCodeValidationError))
+	} else if err != nil {
+		inputErrors = append(inputErrors, createOrderError("userId", err.Error(), models.ErrorCodeValidationError))
+	}
+	if len(input.Items) == 0 {
+		inputErrors = append(inputErrors, createOrderError("items", "At least one item is required", models.ErrorCodeValidationError))
+	}
+	productIDs := make([]string, len(input.Items))
+	for i, item := range input.Items {
+		if item.ProductID == "" {
+			inputErrors = append(inputErrors, createOrderError(fmt.Sprintf("items.%d.productId", i), "Product ID is required", models.ErrorCodeValidationError))
+		} else if productIDs[i], err = localID(productType, item.ProductID); err != nil {
+			inputErrors = append(inputErrors, createOrderError(fmt.Sprintf("items.%d.productId", i), err.Error(), models.ErrorCodeValidationError))
+		}
+		if item.Quantity <= 0 {
+			inputErrors = append(inputErrors, createOrderError(fmt.Sprintf("items.%d.quantity", i), "Quantity must be greater than 0", models.ErrorCodeValidationError))
+		}
+	}
+
+	if len(inputErrors) > 0 {
+		return &models.CreateOrderPayload{
+			Order:  nil,
+			Errors: inputErrors,
+		}, nil
+	}
+
+	items := make([]*order.OrderItemRequest, len(input.Items))
+	for i, item := range input.Items {
+		items[i] = &order.OrderItemRequest{
+			ProductID: productIDs[i],
+			Quantity:  int32(item.Quantity),
+		}
+	}
+
+	// The order service checks the user and the stock and prices the items
+	domainOrder, err := r.OrderService.CreateOrder(ctx, userID, items)
+	if err != nil {
+		return &models.CreateOrderPayload{
+			Order:  nil,
+			Errors: []*models.OrderError{createOrderError("", errorMessage(err), ErrorCode(err))},
+		}, nil
+	}
+
+	forgetOrderProducts(ctx, domainOrder)
+	if loaders := dataloaders.For(ctx); loaders != nil {
+		loaders.OrderByIDLoader.Prime(ctx, domainOrder.ID, domainOrder)
+	}
+
+	return &models.CreateOrderPayload{
+		Order:  domainOrderToGraphQL(domainOrder),
+		Errors: []*models.OrderError{},
+	}, nil
 }
 
 // UpdateOrderStatus is the resolver for the updateOrderStatus field.
 func (r *mutationResolver) UpdateOrderStatus(ctx context.Context, input models.UpdateOrderStatusInput) (*models.UpdateOrderStatusPayload, error) {
-	panic(fmt.Errorf("not implemented: UpdateOrderStatus - updateOrderStatus"))
+	id, err := localID(orderType, input.OrderID)
+	if err != nil {
+		return &models.UpdateOrderStatusPayload{
+			Order:  nil,
+			Errors: []*models.OrderError{createOrderError("orderId", err.Error(), models.ErrorCodeValidationError)},
+		}, nil
+	}
+
+	reason := ""
+	if input.Reason != nil {
+		reason = *input.Reason
+	}
+
+	// The order service checks the transition, records the caller as its
+	// actor, and restores stock when the order is cancelled
+	domainOrder, err := r.OrderService.UpdateOrderStatus(ctx, id, graphQLOrderStatusToDomain(input.Status), reason)
+	if err != nil {
+		field := "orderId"
+		if errors.Is(err, order.ErrInvalidStatusTransition) {
+			field = "status"
+		}
+		return &models.UpdateOrderStatusPayload{
+			Order:  nil,
+			Errors: []*models.OrderError{createOrderError(field, errorMessage(err), ErrorCode(err))},
+		}, nil
+	}
+
+	if domainOrder.Status == order.OrderStatusCancelled {
+		forgetOrderProducts(ctx, domainOrder)
+	}
+	if loaders := dataloaders.For(ctx); loaders != nil {
+		loaders.OrderByIDLoader.Prime(ctx, domainOrder.ID, domainOrder)
+	}
+
+	return &models.UpdateOrderStatusPayload{
+		Order:  domainOrderToGraphQL(domainOrder),
+		Errors: []*models.OrderError{},
+	}, nil
 }
 
 // CancelOrder is the resolver for the cancelOrder field.
 func (r *mutationResolver) CancelOrder(ctx context.Context, input models.CancelOrderInput) (*models.CancelOrderPayload, error) {
-	panic(fmt.Errorf("not implemented: CancelOrder - cancelOrder"))
+	reason := ""
+	if input.Reason != nil {
+		reason = *input.Reason
+	}
+
+	id, err := localID(orderType, input.OrderID)
+	if err != nil {
+		return &models.CancelOrderPayload{
+			Order:  nil,
+			Errors: []*models.OrderError{createOrderError("orderId", err.Error(), models.ErrorCodeValidationError)},
+		}, nil
+	}
+
+	// The order service checks the order is cancellable and restores stock
+	domainOrder, err := r.OrderService.CancelOrder(ctx, id, reason)
+	if err != nil {
+		return &models.CancelOrderPayload{
+			Order:  nil,
+			Errors: []*models.OrderError{createOrderError("orderId", errorMessage(err), ErrorCode(err))},
+		}, nil
+	}
+
+	forgetOrderProducts(ctx, domainOrder)
+	if loaders := dataloaders.For(ctx); loaders != nil {
+		loaders.OrderByIDLoader.Prime(ctx, domainOrder.ID, domainOrder)
+	}
+
+	return &models.CancelOrderPayload{
+		Order:  domainOrderToGraphQL(domainOrder),
+		Errors: []*models.OrderError{},
+	}, nil
 }
 
-// Order is the resolver for the order field.
-func (r *queryResolver) Order(ctx context.Context, id string) (*models.Order, error) {
-	panic(fmt.Errorf("not implemented: Order - order"))
+// User is the resolver for the user field.
+func (r *orderResolver) User(ctx context.Context, obj *models.Order) (*models.User, error) {
+	if loaders := dataloaders.For(ctx); loaders != nil {
+		domainUser, err := loaders.UserLoader.Load(ctx, obj.UserID)
+		if err != nil {
+			return nil, err
+		}
+		return domainUserToGraphQL(domainUser), nil
+	}
+
+	// Fallback to direct repository access
+	domainUser, err := r.UserRepo.GetByID(ctx, obj.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	return domainUserToGraphQL(domainUser), nil
 }
 
-// Orders is the resolver for the orders field.
-func (r *queryResolver) Orders(ctx context.Context, first *int, after *string, last *int, before *string, userID *string, status *models.OrderStatus, dateFrom *string, dateTo *string) (*models.OrderConnection, error) {
-	panic(fmt.Errorf("not implemented: Orders - orders"))
+// CreatedAt is the resolver for the createdAt field.
+func (r *orderResolver) CreatedAt(ctx context.Context, obj *models.Order, timeZone *string) (*time.Time, error) {
+	return inTimeZone(obj.CreatedAt, timeZone)
 }
 
-// !!! WARNING !!!
-// The code below was going to be deleted when updating resolvers. It has been copied here so you have
-// one last chance to move it out of harms way if you want. There are two reasons this happens:
-//  - When renaming or deleting a resolver the old code will be put in here. You can safely delete
-//    it when you're done.
-//  - You have helper methods in this file. Move them out to keep these resolver files clean.
-/*
-	func (r *orderResolver) User(ctx context.Context, obj *order.Order) (*user.User, error) {
-	panic(fmt.Errorf("not implemented: User - user"))
+// UpdatedAt is the resolver for the updatedAt field.
+func (r *orderResolver) UpdatedAt(ctx context.Context, obj *models.Order, timeZone *string) (*time.Time, error) {
+	return inTimeZone(obj.UpdatedAt, timeZone)
 }
-func (r *orderResolver) ItemCount(ctx context.Context, obj *order.Order) (int, error) {
-	panic(fmt.Errorf("not implemented: ItemCount - itemCount"))
+
+// History is the resolver for the history field.
+func (r *orderResolver) History(ctx context.Context, obj *models.Order) ([]*models.OrderStatusChange, error) {
+	id, err := localID(orderType, obj.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if loaders := dataloaders.For(ctx); loaders != nil {
+		history, err := loaders.HistoryLoader.Load(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		return domainStatusTransitionsToGraphQL(history), nil
+	}
+
+	// Fallback to direct repository access
+	history, err := r.OrderRepo.GetHistory(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return domainStatusTransitionsToGraphQL(history), nil
 }
-func (r *orderResolver) CanBeCancelled(ctx context.Context, obj *order.Order) (bool, error) {
-	panic(fmt.Errorf("not implemented: CanBeCancelled - canBeCancelled"))
+
+// Product is the resolver for the product field.
+func (r *orderItemResolver) Product(ctx context.Context, obj *models.OrderItem) (*models.Product, error) {
+	if loaders := dataloaders.For(ctx); loaders != nil {
+		domainProduct, err := loaders.ProductLoader.Load(ctx, obj.ProductID)
+		if err != nil {
+			return nil, err
+		}
+		return domainProductToGraphQL(domainProduct), nil
+	}
+
+	// Fallback to direct repository access
+	domainProduct, err := r.ProductRepo.GetByID(ctx, obj.ProductID)
+	if err != nil {
+		return nil, err
+	}
+
+	return domainProductToGraphQL(domainProduct), nil
+}
+
+// Timestamp is the resolver for the timestamp field.
+func (r *orderStatusChangeResolver) Timestamp(ctx context.Context, obj *models.OrderStatusChange, timeZone *string) (*time.Time, error) {
+	return inTimeZone(obj.Timestamp, timeZone)
+}
+
+// Order is the resolver for the order field.
+func (r *queryResolver) Order(ctx context.Context, id string) (*models.Order, error) {
+	id, err := localID(orderType, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Use DataLoader for efficient fetching
+	if loaders := dataloaders.For(ctx); loaders != nil {
+		domainOrder, err := loaders.OrderByIDLoader.Load(ctx, id)
+		if err != nil {
+			if err == order.ErrOrderNotFound {
+				return nil, nil // Return nil for not found (GraphQL convention)
+			}
+			return nil, err
+		}
+		return domainOrderToGraphQL(domainOrder), nil
+	}
+
+	// Fallback to direct repository access
+	domainOrder, err := r.OrderRepo.GetByID(ctx, id)
+	if err != nil {
+		if err == order.ErrOrderNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return domainOrderToGraphQL(domainOrder), nil
 }
-func (r *orderItemResolver) Product(ctx context.Context, obj *order.OrderItem) (*product.Product, error) {
-	panic(fmt.Errorf("not implemented: Product - product"))
+
+// Orders is the resolver for the orders field.
+func (r *queryResolver) Orders(ctx context.Context, first *int, after *string, last *int, before *string, userID *string, status *models.OrderStatus, dateFrom *time.Time, dateTo *time.Time) (*models.OrderConnection, error) {
+	query, err := pageArgs(ctx, first, after, last, before)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := order.ListFilter{}
+	if filter.UserID, err = optionalLocalID(userType, userID); err != nil {
+		return nil, err
+	}
+	if status != nil {
+		filter.Status = graphQLOrderStatusToDomain(*status)
+	}
+	if dateFrom != nil {
+		filter.CreatedFrom = *dateFrom
+	}
+	if dateTo != nil {
+		filter.CreatedTo = *dateTo
+	}
+	if !filter.CreatedFrom.IsZero() && !filter.CreatedTo.IsZero() && filter.CreatedFrom.After(filter.CreatedTo) {
+		return nil, inputErrorf("dateFrom must not be after dateTo")
+	}
+
+	domainOrders, info, err := r.OrderService.ListOrders(ctx, query, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return createOrderConnection(domainOrders, info), nil
 }
+
+// Order returns generated.OrderResolver implementation.
 func (r *Resolver) Order() generated.OrderResolver { return &orderResolver{r} }
+
+// OrderItem returns generated.OrderItemResolver implementation.
 func (r *Resolver) OrderItem() generated.OrderItemResolver { return &orderItemResolver{r} }
+
+// OrderStatusChange returns generated.OrderStatusChangeResolver implementation.
+func (r *Resolver) OrderStatusChange() generated.OrderStatusChangeResolver {
+	return &orderStatusChangeResolver{r}
+}
+
 type orderResolver struct{ *Resolver }
 type orderItemResolver struct{ *Resolver }
-*/
+type orderStatusChangeResolver struct{ *Resolver }
@@ -6,58 +6,277 @@ package resolvers
 
 import (
 	"context"
-	"fmt"
+	"errors"
+	"learning/internal/graphql/dataloaders"
+	"learning/internal/graphql/generated"
 	"learning/internal/graphql/models"
+	"learning/internal/product"
+	"time"
 )
 
 // CreateProduct is the resolver for the createProduct field.
 func (r *mutationResolver) CreateProduct(ctx context.Context, input models.CreateProductInput) (*models.CreateProductPayload, error) {
-	panic(fmt.Errorf("not implemented: CreateProduct - createProduct"))
+	// Validate input
+	var inputErrors []*models.ProductError
+
+	if input.Name == "" {
+		inputErrors = append(inputErrors, createProductError("name", "Name is required", models.ErrorCodeValidationError))
+	}
+	if input.Description == "" {
+		inputErrors = append(inputErrors, createProductError("description", "Description is required", models.ErrorCodeValidationError))
+	}
+	if input.Category == "" {
+		inputErrors = append(inputErrors, createProductError("category", "Category is required", models.ErrorCodeValidationError))
+	}
+	if input.Price <= 0 {
+		inputErrors = append(inputErrors, createProductError("price", "Price must be greater than 0", models.ErrorCodeValidationError))
+	}
+	if input.Stock < 0 {
+		inputErrors = append(inputErrors, createProductError("stock", "Stock cannot be negative", models.ErrorCodeValidationError))
+	}
+
+	if len(inputErrors) > 0 {
+		return &models.CreateProductPayload{
+			Product: nil,
+			Errors:  inputErrors,
+		}, nil
+	}
+
+	// Create product using service
+	productService := product.NewService(r.ProductRepo)
+	domainProduct, err := productService.CreateProduct(ctx, input.Name, input.Description, input.Category, input.Price, int32(input.Stock))
+	if err != nil {
+		return &models.CreateProductPayload{
+			Product: nil,
+			Errors:  []*models.ProductError{createProductError("", errorMessage(err), ErrorCode(err))},
+		}, nil
+	}
+
+	// Prime the cache for future lookups
+	if loaders := dataloaders.For(ctx); loaders != nil {
+		loaders.ProductLoader.Prime(ctx, domainProduct.ID, domainProduct)
+	}
+
+	return &models.CreateProductPayload{
+		Product: domainProductToGraphQL(domainProduct),
+		Errors:  []*models.ProductError{},
+	}, nil
 }
 
 // UpdateProduct is the resolver for the updateProduct field.
 func (r *mutationResolver) UpdateProduct(ctx context.Context, input models.UpdateProductInput) (*models.UpdateProductPayload, error) {
-	panic(fmt.Errorf("not implemented: UpdateProduct - updateProduct"))
+	// Only the fields given are changed
+	var fields []string
+	var name, description, category string
+	var price float64
+	var stock int32
+	if input.Name != nil {
+		fields, name = append(fields, product.FieldName), *input.Name
+	}
+	if input.Description != nil {
+		fields, description = append(fields, product.FieldDescription), *input.Description
+	}
+	if input.Category != nil {
+		fields, category = append(fields, product.FieldCategory), *input.Category
+	}
+	if input.Price != nil {
+		fields, price = append(fields, product.FieldPrice), *input.Price
+	}
+	if input.Stock != nil {
+		fields, stock = append(fields, product.FieldStock), int32(*input.Stock)
+	}
+
+	id, err := localID(productType, input.ID)
+	if err != nil {
+		return &models.UpdateProductPayload{
+			Product: nil,
+			Errors:  []*models.ProductError{createProductError("id", err.Error(), models.ErrorCodeValidationError)},
+		}, nil
+	}
+
+	productService := product.NewService(r.ProductRepo)
+	domainProduct, err := productService.PatchProduct(ctx, id, name, description, category, price, stock, fields)
+	if err != nil {
+		field := ""
+		switch {
+		case errors.Is(err, product.ErrProductNotFound):
+			field = "id"
+		case errors.Is(err, product.ErrInsufficientStock):
+			field = "stock"
+		}
+		return &models.UpdateProductPayload{
+			Product: nil,
+			Errors:  []*models.ProductError{createProductError(field, errorMessage(err), ErrorCode(err))},
+		}, nil
+	}
+
+	// Replace the cached product so later lookups see the update
+	if loaders := dataloaders.For(ctx); loaders != nil {
+		loaders.ProductLoader.Prime(ctx, domainProduct.ID, domainProduct)
+	}
+
+	return &models.UpdateProductPayload{
+		Product: domainProductToGraphQL(domainProduct),
+		Errors:  []*models.ProductError{},
+	}, nil
 }
 
 // DeleteProduct is the resolver for the deleteProduct field.
 func (r *mutationResolver) DeleteProduct(ctx context.Context, input models.DeleteProductInput) (*models.DeleteProductPayload, error) {
-	panic(fmt.Errorf("not implemented: DeleteProduct - deleteProduct"))
+	id, err := localID(productType, input.ID)
+	if err != nil {
+		return &models.DeleteProductPayload{
+			DeletedProductID: nil,
+			Errors:           []*models.ProductError{createProductError("id", err.Error(), models.ErrorCodeValidationError)},
+		}, nil
+	}
+
+	productService := product.NewService(r.ProductRepo)
+	if err := productService.DeleteProduct(ctx, id); err != nil {
+		return &models.DeleteProductPayload{
+			DeletedProductID: nil,
+			Errors:           []*models.ProductError{createProductError("id", errorMessage(err), ErrorCode(err))},
+		}, nil
+	}
+
+	// Drop the cached product so later lookups don't find it
+	if loaders := dataloaders.For(ctx); loaders != nil {
+		loaders.ProductLoader.Clear(ctx, id)
+	}
+
+	deletedID := toGlobalID(productType, id)
+	return &models.DeleteProductPayload{
+		DeletedProductID: &deletedID,
+		Errors:           []*models.ProductError{},
+	}, nil
 }
 
 // UpdateProductStock is the resolver for the updateProductStock field.
 func (r *mutationResolver) UpdateProductStock(ctx context.Context, input models.UpdateProductStockInput) (*models.UpdateProductStockPayload, error) {
-	panic(fmt.Errorf("not implemented: UpdateProductStock - updateProductStock"))
+	id, err := localID(productType, input.ProductID)
+	if err != nil {
+		return &models.UpdateProductStockPayload{
+			Product: nil,
+			Errors:  []*models.ProductError{createProductError("productId", err.Error(), models.ErrorCodeValidationError)},
+		}, nil
+	}
+
+	productService := product.NewService(r.ProductRepo)
+	domainProduct, err := productService.UpdateStock(ctx, id, int32(input.Quantity))
+	if err != nil {
+		field := "productId"
+		if errors.Is(err, product.ErrInsufficientStock) {
+			field = "quantity"
+		}
+		return &models.UpdateProductStockPayload{
+			Product: nil,
+			Errors:  []*models.ProductError{createProductError(field, errorMessage(err), ErrorCode(err))},
+		}, nil
+	}
+
+	// Replace the cached product so later lookups see the new stock
+	if loaders := dataloaders.For(ctx); loaders != nil {
+		loaders.ProductLoader.Prime(ctx, domainProduct.ID, domainProduct)
+	}
+
+	return &models.UpdateProductStockPayload{
+		Product: domainProductToGraphQL(domainProduct),
+		Errors:  []*models.ProductError{},
+	}, nil
+}
+
+// CreatedAt is the resolver for the createdAt field.
+func (r *productResolver) CreatedAt(ctx context.Context, obj *models.Product, timeZone *string) (*time.Time, error) {
+	return inTimeZone(obj.CreatedAt, timeZone)
+}
+
+// UpdatedAt is the resolver for the updatedAt field.
+func (r *productResolver) UpdatedAt(ctx context.Context, obj *models.Product, timeZone *string) (*time.Time, error) {
+	return inTimeZone(obj.UpdatedAt, timeZone)
 }
 
 // Product is the resolver for the product field.
 func (r *queryResolver) Product(ctx context.Context, id string) (*models.Product, error) {
-	panic(fmt.Errorf("not implemented: Product - product"))
+	id, err := localID(productType, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Use DataLoader for efficient fetching
+	if loaders := dataloaders.For(ctx); loaders != nil {
+		domainProduct, err := loaders.ProductLoader.Load(ctx, id)
+		if err != nil {
+			if err == product.ErrProductNotFound {
+				return nil, nil // Return nil for not found (GraphQL convention)
+			}
+			return nil, err
+		}
+		return domainProductToGraphQL(domainProduct), nil
+	}
+
+	// Fallback to direct repository access
+	domainProduct, err := r.ProductRepo.GetByID(ctx, id)
+	if err != nil {
+		if err == product.ErrProductNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return domainProductToGraphQL(domainProduct), nil
 }
 
 // Products is the resolver for the products field.
 func (r *queryResolver) Products(ctx context.Context, first *int, after *string, last *int, before *string, category *string, search *string, inStock *bool) (*models.ProductConnection, error) {
-	panic(fmt.Errorf("not implemented: Products - products"))
+	query, err := pageArgs(ctx, first, after, last, before)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := product.ListFilter{InStock: inStock}
+	if category != nil {
+		filter.Category = *category
+	}
+	if search != nil {
+		filter.Search = *search
+	}
+
+	// Get products from service
+	productService := product.NewService(r.ProductRepo)
+	domainProducts, info, err := productService.ListProducts(ctx, query, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	// Prime the cache so order items of these products don't load them again
+	if loaders := dataloaders.For(ctx); loaders != nil {
+		for _, domainProduct := range domainProducts {
+			loaders.ProductLoader.Prime(ctx, domainProduct.ID, domainProduct)
+		}
+	}
+
+	return createProductConnection(domainProducts, info), nil
 }
 
 // ProductCategories is the resolver for the productCategories field.
 func (r *queryResolver) ProductCategories(ctx context.Context) ([]*models.ProductCategory, error) {
-	panic(fmt.Errorf("not implemented: ProductCategories - productCategories"))
-}
+	productService := product.NewService(r.ProductRepo)
+	categories, err := productService.ListCategories(ctx)
+	if err != nil {
+		return nil, err
+	}
 
-// !!! WARNING !!!
-// The code below was going to be deleted when updating resolvers. It has been copied here so you have
-// one last chance to move it out of harms way if you want. There are two reasons this happens:
-//  - When renaming or deleting a resolver the old code will be put in here. You can safely delete
-//    it when you're done.
-//  - You have helper methods in this file. Move them out to keep these resolver files clean.
-/*
-	func (r *productResolver) IsInStock(ctx context.Context, obj *product.Product) (bool, error) {
-	panic(fmt.Errorf("not implemented: IsInStock - isInStock"))
-}
-func (r *productResolver) StockStatus(ctx context.Context, obj *product.Product) (models.StockStatus, error) {
-	panic(fmt.Errorf("not implemented: StockStatus - stockStatus"))
+	result := make([]*models.ProductCategory, len(categories))
+	for i, category := range categories {
+		result[i] = &models.ProductCategory{
+			Name:  category.Name,
+			Count: category.Count,
+		}
+	}
+	return result, nil
 }
+
+// Product returns generated.ProductResolver implementation.
 func (r *Resolver) Product() generated.ProductResolver { return &productResolver{r} }
+
 type productResolver struct{ *Resolver }
-*/
@@ -1,7 +1,10 @@
 package resolvers
 
 import (
-	"learning/internal/graphql/dataloaders"
+	"context"
+
+	"learning/internal/common"
+	"learning/internal/graphql/subscriptions"
 	"learning/internal/order"
 	"learning/internal/product"
 	"learning/internal/user"
@@ -11,11 +14,25 @@ import (
 //
 // It serves as dependency injection for your app, add any dependencies you require here.
 
+// OrderService is the part of order.Service the resolvers change and list
+// orders through, so its checks and status rules apply
+type OrderService interface {
+	CreateOrder(ctx context.Context, userID string, items []*order.OrderItemRequest) (*order.Order, error)
+	UpdateOrderStatus(ctx context.Context, id string, status order.OrderStatus, reason string) (*order.Order, error)
+	CancelOrder(ctx context.Context, id, reason string) (*order.Order, error)
+	ListOrders(ctx context.Context, query common.PageQuery, filter order.ListFilter) ([]*order.Order, common.PageInfo, error)
+}
+
 type Resolver struct {
-	UserRepo    user.Repository
-	ProductRepo product.Repository
-	OrderRepo   order.Repository
-	Loaders     *dataloaders.Loaders
+	UserRepo     user.Repository
+	ProductRepo  product.Repository
+	OrderRepo    order.Repository
+	OrderService OrderService
+	Hub          *subscriptions.Hub
+
+	// AuthEnabled turns on the role checks of @auth, and of node lookups
+	// of the types @auth guards
+	AuthEnabled bool
 }
 
 // NewResolver creates a new resolver with dependencies
@@ -23,12 +40,16 @@ func NewResolver(
 	userRepo user.Repository,
 	productRepo product.Repository,
 	orderRepo order.Repository,
-	loaders *dataloaders.Loaders,
+	orderService OrderService,
+	hub *subscriptions.Hub,
+	authEnabled bool,
 ) *Resolver {
 	return &Resolver{
-		UserRepo:    userRepo,
-		ProductRepo: productRepo,
-		OrderRepo:   orderRepo,
-		Loaders:     loaders,
+		UserRepo:     userRepo,
+		ProductRepo:  productRepo,
+		OrderRepo:    orderRepo,
+		OrderService: orderService,
+		Hub:          hub,
+		AuthEnabled:  authEnabled,
 	}
 }
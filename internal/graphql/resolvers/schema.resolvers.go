@@ -7,7 +7,9 @@ package resolvers
 import (
 	"context"
 	"fmt"
+	"learning/internal/auth"
 	"learning/internal/graphql/generated"
+	"learning/internal/graphql/models"
 )
 
 // Empty is the resolver for the _empty field.
@@ -20,9 +22,58 @@ func (r *queryResolver) Health(ctx context.Context) (string, error) {
 	return "GraphQL server is healthy!", nil
 }
 
-// Empty is the resolver for the _empty field.
-func (r *subscriptionResolver) Empty(ctx context.Context) (<-chan *string, error) {
-	panic(fmt.Errorf("not implemented: Empty - _empty"))
+// Node is the resolver for the node field.
+func (r *queryResolver) Node(ctx context.Context, id string) (models.Node, error) {
+	return r.resolveNode(ctx, id)
+}
+
+// Nodes is the resolver for the nodes field.
+func (r *queryResolver) Nodes(ctx context.Context, ids []string) ([]models.Node, error) {
+	return r.resolveNodes(ctx, ids)
+}
+
+// OrderUpdated is the resolver for the orderUpdated field.
+func (r *subscriptionResolver) OrderUpdated(ctx context.Context, userID *string) (<-chan *models.Order, error) {
+	if err := checkConnection(ctx); err != nil {
+		return nil, err
+	}
+
+	id, err := optionalLocalID(userType, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// The hub watches every order, so USERs are held to their own here
+	id, err = auth.OwnUserID(ctx, id, auth.RoleManager)
+	if err != nil {
+		return nil, err
+	}
+
+	subscription, err := r.Hub.SubscribeOrders(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return forwardChanges(ctx, subscription, domainOrderToGraphQL), nil
+}
+
+// StockUpdated is the resolver for the stockUpdated field.
+func (r *subscriptionResolver) StockUpdated(ctx context.Context, productID *string) (<-chan *models.Product, error) {
+	if err := checkConnection(ctx); err != nil {
+		return nil, err
+	}
+
+	id, err := optionalLocalID(productType, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	subscription, err := r.Hub.SubscribeStock(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return forwardChanges(ctx, subscription, domainProductToGraphQL), nil
 }
 
 // Mutation returns generated.MutationResolver implementation.
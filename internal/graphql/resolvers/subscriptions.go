@@ -0,0 +1,59 @@
+package resolvers
+
+import (
+	"context"
+	"errors"
+
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+
+	"learning/internal/common"
+	"learning/internal/graphql/subscriptions"
+)
+
+// errWebSocketRequired is returned for subscriptions sent over plain HTTP
+var errWebSocketRequired = errors.New("subscriptions require a WebSocket connection")
+
+// checkConnection makes sure a subscription arrived over a WebSocket, the
+// only transport that can stream its results
+func checkConnection(ctx context.Context) error {
+	if !subscriptions.OnConnection(ctx) {
+		return errWebSocketRequired
+	}
+	return nil
+}
+
+// forwardChanges sends the changes a subscription receives to the client,
+// converted by convert, until the client unsubscribes. A client that falls
+// too far behind has the subscription ended with an error.
+func forwardChanges[T, M any](ctx context.Context, subscription *common.Subscription[T], convert func(T) M) <-chan M {
+	changes := make(chan M)
+
+	go func() {
+		defer close(changes)
+		defer subscription.Close()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case change, ok := <-subscription.Events():
+				// A dropped subscription stops at once rather than sending
+				// what it still buffers
+				if !ok || subscription.Dropped() {
+					if subscription.Dropped() {
+						transport.AddSubscriptionError(ctx, gqlerror.Errorf("subscription dropped: too many updates were waiting to be sent"))
+					}
+					return
+				}
+				select {
+				case changes <- convert(change):
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return changes
+}
@@ -7,8 +7,12 @@ package resolvers
 import (
 	"context"
 	"fmt"
+	"learning/internal/graphql/dataloaders"
+	"learning/internal/graphql/generated"
 	"learning/internal/graphql/models"
+	"learning/internal/order"
 	"learning/internal/user"
+	"time"
 )
 
 // CreateUser is the resolver for the createUser field.
@@ -48,7 +52,7 @@ func (r *mutationResolver) CreateUser(ctx context.Context, input models.CreateUs
 
 		return &models.CreateUserPayload{
 			User:   nil,
-			Errors: []*models.UserError{createUserError("", err.Error(), errorCode)},
+			Errors: []*models.UserError{createUserError("", errorMessage(err), errorCode)},
 		}, nil
 	}
 
@@ -56,8 +60,8 @@ func (r *mutationResolver) CreateUser(ctx context.Context, input models.CreateUs
 	gqlUser := domainUserToGraphQL(domainUser)
 
 	// Prime the cache for future lookups
-	if r.Loaders != nil {
-		r.Loaders.UserLoader.Prime(ctx, domainUser.ID, domainUser)
+	if loaders := dataloaders.For(ctx); loaders != nil {
+		loaders.UserLoader.Prime(ctx, domainUser.ID, domainUser)
 	}
 
 	return &models.CreateUserPayload{
@@ -68,7 +72,59 @@ func (r *mutationResolver) CreateUser(ctx context.Context, input models.CreateUs
 
 // UpdateUser is the resolver for the updateUser field.
 func (r *mutationResolver) UpdateUser(ctx context.Context, input models.UpdateUserInput) (*models.UpdateUserPayload, error) {
-	panic(fmt.Errorf("not implemented: UpdateUser - updateUser"))
+	// Only the fields given are changed
+	var fields []string
+	var name, email, phone string
+	if input.Name != nil {
+		fields, name = append(fields, user.FieldName), *input.Name
+	}
+	if input.Email != nil {
+		fields, email = append(fields, user.FieldEmail), *input.Email
+	}
+	if input.Phone != nil {
+		fields, phone = append(fields, user.FieldPhone), *input.Phone
+	}
+
+	id, err := localID(userType, input.ID)
+	if err != nil {
+		return &models.UpdateUserPayload{
+			User:   nil,
+			Errors: []*models.UserError{createUserError("id", err.Error(), models.ErrorCodeValidationError)},
+		}, nil
+	}
+
+	userService := user.NewService(r.UserRepo)
+	domainUser, err := userService.PatchUser(ctx, id, name, email, phone, fields)
+	if err != nil {
+		var errorCode models.ErrorCode
+		field := ""
+		if _, ok := err.(*user.ValidationError); ok {
+			errorCode = models.ErrorCodeValidationError
+		} else if err == user.ErrUserNotFound {
+			errorCode = models.ErrorCodeNotFound
+			field = "id"
+		} else if err == user.ErrUserAlreadyExists {
+			errorCode = models.ErrorCodeAlreadyExists
+			field = "email"
+		} else {
+			errorCode = models.ErrorCodeInternalError
+		}
+
+		return &models.UpdateUserPayload{
+			User:   nil,
+			Errors: []*models.UserError{createUserError(field, errorMessage(err), errorCode)},
+		}, nil
+	}
+
+	// Replace the cached user so later lookups see the update
+	if loaders := dataloaders.For(ctx); loaders != nil {
+		loaders.UserLoader.Prime(ctx, domainUser.ID, domainUser)
+	}
+
+	return &models.UpdateUserPayload{
+		User:   domainUserToGraphQL(domainUser),
+		Errors: []*models.UserError{},
+	}, nil
 }
 
 // DeleteUser is the resolver for the deleteUser field.
@@ -78,9 +134,14 @@ func (r *mutationResolver) DeleteUser(ctx context.Context, input models.DeleteUs
 
 // User is the resolver for the user field.
 func (r *queryResolver) User(ctx context.Context, id string) (*models.User, error) {
+	id, err := localID(userType, id)
+	if err != nil {
+		return nil, err
+	}
+
 	// Use DataLoader for efficient fetching
-	if r.Loaders != nil {
-		domainUser, err := r.Loaders.UserLoader.Load(ctx, id)
+	if loaders := dataloaders.For(ctx); loaders != nil {
+		domainUser, err := loaders.UserLoader.Load(ctx, id)
 		if err != nil {
 			if err == user.ErrUserNotFound {
 				return nil, nil // Return nil for not found (GraphQL convention)
@@ -104,48 +165,82 @@ func (r *queryResolver) User(ctx context.Context, id string) (*models.User, erro
 
 // Users is the resolver for the users field.
 func (r *queryResolver) Users(ctx context.Context, first *int, after *string, last *int, before *string, search *string) (*models.UserConnection, error) {
-	// Set default pagination
-	page := 1
-	pageSize := 10
-
-	if first != nil && *first > 0 {
-		pageSize = *first
-		if pageSize > 100 {
-			pageSize = 100 // Max page size
-		}
-	}
-
-	// Simple cursor-based pagination (in real implementation, decode cursor)
-	if after != nil {
-		// For simplicity, assume cursor is page number
-		// In real implementation, decode base64 cursor
-		page = 2 // Simplified
+	query, err := pageArgs(ctx, first, after, last, before)
+	if err != nil {
+		return nil, err
 	}
 
 	// Get users from service
 	userService := user.NewService(r.UserRepo)
-	domainUsers, total, err := userService.ListUsers(ctx, page, pageSize)
+	domainUsers, info, err := userService.ListUsers(ctx, query)
 	if err != nil {
 		return nil, err
 	}
 
-	// Convert to GraphQL models
-	gqlUsers := domainUsersToGraphQL(domainUsers)
+	// Prime the cache so orders of these users don't load them again
+	if loaders := dataloaders.For(ctx); loaders != nil {
+		for _, domainUser := range domainUsers {
+			loaders.UserLoader.Prime(ctx, domainUser.ID, domainUser)
+		}
+	}
 
 	// Create connection
-	return createUserConnection(gqlUsers, total, page, pageSize), nil
+	return createUserConnection(domainUsers, info), nil
 }
 
-// !!! WARNING !!!
-// The code below was going to be deleted when updating resolvers. It has been copied here so you have
-// one last chance to move it out of harms way if you want. There are two reasons this happens:
-//  - When renaming or deleting a resolver the old code will be put in here. You can safely delete
-//    it when you're done.
-//  - You have helper methods in this file. Move them out to keep these resolver files clean.
-/*
-	func (r *userResolver) Orders(ctx context.Context, obj *user.User, first *int, after *string, last *int, before *string, status *order.OrderStatus) (*models.OrderConnection, error) {
-	panic(fmt.Errorf("not implemented: Orders - orders"))
+// CreatedAt is the resolver for the createdAt field.
+func (r *userResolver) CreatedAt(ctx context.Context, obj *models.User, timeZone *string) (*time.Time, error) {
+	return inTimeZone(obj.CreatedAt, timeZone)
 }
+
+// UpdatedAt is the resolver for the updatedAt field.
+func (r *userResolver) UpdatedAt(ctx context.Context, obj *models.User, timeZone *string) (*time.Time, error) {
+	return inTimeZone(obj.UpdatedAt, timeZone)
+}
+
+// Orders is the resolver for the orders field.
+func (r *userResolver) Orders(ctx context.Context, obj *models.User, first *int, after *string, last *int, before *string, status *models.OrderStatus) (*models.OrderConnection, error) {
+	query, err := pageArgs(ctx, first, after, last, before)
+	if err != nil {
+		return nil, err
+	}
+
+	userID, err := localID(userType, obj.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	key := dataloaders.OrderKey{
+		UserID: userID,
+		Page:   query,
+	}
+	if status != nil {
+		key.Status = graphQLOrderStatusToDomain(*status)
+	}
+
+	// Orders of all users in the response are listed together
+	if loaders := dataloaders.For(ctx); loaders != nil {
+		userOrders, err := loaders.OrderLoader.Load(ctx, key)
+		if err != nil {
+			return nil, err
+		}
+		return createOrderConnection(userOrders.Orders, userOrders.Page), nil
+	}
+
+	// Fallback to direct repository access
+	results, err := r.OrderRepo.ListByUsers(ctx, []string{key.UserID}, key.Status, key.Page)
+	if err != nil {
+		return nil, err
+	}
+	userOrders := &order.UserOrders{}
+	if len(results) > 0 {
+		userOrders = results[0]
+	}
+
+	return createOrderConnection(userOrders.Orders, userOrders.Page), nil
+}
+
+// User returns generated.UserResolver implementation.
 func (r *Resolver) User() generated.UserResolver { return &userResolver{r} }
+
 type userResolver struct{ *Resolver }
-*/
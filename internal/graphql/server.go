@@ -1,14 +1,33 @@
 package graphql
 
 import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net"
 	"net/http"
+	"time"
 
+	"github.com/99designs/gqlgen/graphql"
 	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/99designs/gqlgen/graphql/handler/extension"
+	"github.com/99designs/gqlgen/graphql/handler/lru"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
 	"github.com/99designs/gqlgen/graphql/playground"
+	"github.com/gorilla/websocket"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+	"google.golang.org/grpc/metadata"
 
+	"learning/internal/auth"
+	"learning/internal/common"
 	"learning/internal/graphql/dataloaders"
 	"learning/internal/graphql/generated"
+	"learning/internal/graphql/models"
 	"learning/internal/graphql/resolvers"
+	"learning/internal/graphql/subscriptions"
 	"learning/internal/order"
 	"learning/internal/product"
 	"learning/internal/user"
@@ -18,13 +37,51 @@ import (
 type Config struct {
 	PlaygroundEnabled    bool
 	IntrospectionEnabled bool
+
+	// SubscriptionBufferSize is how many updates a subscription may fall
+	// behind before it is ended with an error
+	SubscriptionBufferSize int
+	// KeepAliveInterval is how often WebSocket clients are pinged; one that
+	// doesn't answer within two intervals is disconnected. 0 disables pings.
+	KeepAliveInterval time.Duration
+	// WriteTimeout is how long a write to a WebSocket client may take before
+	// the connection is closed. 0 lets writes take as long as they need.
+	WriteTimeout time.Duration
+
+	// MaxDepth is how deeply the fields of an operation may nest, and
+	// MaxComplexity how complex it may be, connection fields counting once
+	// per node they can return; operations over either are rejected.
+	// OperationTimeout cuts queries and mutations short. 0 disables a limit.
+	MaxDepth         int
+	MaxComplexity    int
+	OperationTimeout time.Duration
+
+	// PersistedQueryCacheSize is how many automatic persisted queries are
+	// remembered by their hash. 0 disables them.
+	PersistedQueryCacheSize int
+	// TrustedDocuments, when set, maps the hash of each operation that may
+	// run to its document. Every other operation is rejected, and
+	// introspection is turned off.
+	TrustedDocuments map[string]string
+
+	// Verifier checks the tokens of callers for the @auth directive. When
+	// nil, @auth lets every caller through and tokens are only passed on
+	// to the services.
+	Verifier *auth.Verifier
+	// Credentials authenticate the gateway itself on the streams of changes
+	// subscriptions are fed by
+	Credentials *auth.ServiceCredentials
 }
 
+// initTimeout is how long a WebSocket client has to initialise the connection
+const initTimeout = 10 * time.Second
+
 // Server represents GraphQL server
 type Server struct {
 	config     *Config
 	handler    http.Handler
 	playground http.Handler
+	hub        *subscriptions.Hub
 }
 
 // NewServer creates a new GraphQL server
@@ -33,23 +90,66 @@ func NewServer(
 	userRepo user.Repository,
 	productRepo product.Repository,
 	orderRepo order.Repository,
+	orderService resolvers.OrderService,
 ) *Server {
-	// Create dataloaders
-	loaders := dataloaders.NewLoaders(userRepo, productRepo, orderRepo)
+	// Subscriptions are fed by the services' change streams, when the
+	// repositories can watch them
+	orderWatcher, _ := orderRepo.(subscriptions.OrderWatcher)
+	stockWatcher, _ := productRepo.(subscriptions.StockWatcher)
+	hub := subscriptions.NewHub(orderWatcher, stockWatcher, config.SubscriptionBufferSize, config.Credentials)
 
 	// Create resolver with dependencies
-	resolver := resolvers.NewResolver(userRepo, productRepo, orderRepo, loaders)
+	resolver := resolvers.NewResolver(userRepo, productRepo, orderRepo, orderService, hub, config.Verifier != nil)
 
 	// Create GraphQL config
-	gqlConfig := generated.Config{Resolvers: resolver}
+	gqlConfig := generated.Config{
+		Resolvers: resolver,
+		Directives: generated.DirectiveRoot{
+			Auth: resolvers.AuthDirective(resolver.AuthEnabled),
+		},
+	}
+	setComplexity(&gqlConfig.Complexity)
 
 	// Create GraphQL handler
-	gqlHandler := handler.NewDefaultServer(generated.NewExecutableSchema(gqlConfig))
+	gqlHandler := handler.New(generated.NewExecutableSchema(gqlConfig))
+	gqlHandler.AddTransport(newWebsocketTransport(config))
+	gqlHandler.AddTransport(transport.Options{})
+	gqlHandler.AddTransport(transport.GET{})
+	gqlHandler.AddTransport(transport.POST{})
+	gqlHandler.AddTransport(transport.MultipartForm{})
+
+	gqlHandler.SetQueryCache(lru.New[*ast.QueryDocument](1000))
+
+	// Clients see error codes from the ErrorCode enum, never the text of
+	// internal errors, and a panicking resolver only fails its own field
+	gqlHandler.SetErrorPresenter(resolvers.PresentError)
+	gqlHandler.SetRecoverFunc(resolvers.Recover)
+
+	if config.TrustedDocuments != nil {
+		gqlHandler.Use(trustedDocuments{documents: config.TrustedDocuments})
+	} else {
+		if config.IntrospectionEnabled {
+			gqlHandler.Use(extension.Introspection{})
+		}
+		if config.PersistedQueryCacheSize > 0 {
+			gqlHandler.Use(extension.AutomaticPersistedQuery{
+				Cache: lru.New[string](config.PersistedQueryCacheSize),
+			})
+		}
+	}
+
+	// Limit how much work a single operation can cause
+	if config.MaxDepth > 0 {
+		gqlHandler.Use(depthLimit{max: config.MaxDepth})
+	}
+	if config.MaxComplexity > 0 {
+		gqlHandler.Use(extension.FixedComplexityLimit(config.MaxComplexity))
+	}
+	if config.OperationTimeout > 0 {
+		gqlHandler.Use(operationTimeout{timeout: config.OperationTimeout})
+	}
 
-	// Add middleware for complexity analysis, query timeout, etc.
-	// TODO: Add these middleware for production
-	// gqlHandler.Use(extension.Introspection{})
-	// gqlHandler.Use(extension.AutomaticPersistedQuery{})
+	gqlHandler.Use(idempotencyKeys{})
 
 	var playgroundHandler http.Handler
 	if config.PlaygroundEnabled {
@@ -58,9 +158,191 @@ func NewServer(
 
 	return &Server{
 		config:     config,
-		handler:    dataloaders.Middleware(loaders)(gqlHandler),
+		handler:    authenticate(config.Verifier, forwardIdempotencyKey(dataloaders.Middleware(userRepo, productRepo, orderRepo)(limitWebSocketWrites(gqlHandler, config.WriteTimeout)))),
 		playground: playgroundHandler,
+		hub:        hub,
+	}
+}
+
+// newWebsocketTransport serves subscriptions over WebSockets, speaking
+// graphql-transport-ws or the older graphql-ws protocol
+func newWebsocketTransport(config *Config) transport.Websocket {
+	return transport.Websocket{
+		Upgrader: websocket.Upgrader{
+			// Accept any origin, as the CORS policy does for the HTTP endpoints
+			CheckOrigin: func(r *http.Request) bool { return true },
+		},
+		InitFunc: func(ctx context.Context, payload transport.InitPayload) (context.Context, *transport.InitPayload, error) {
+			// Browsers can't set headers on WebSockets, so clients may send
+			// their token when initialising the connection instead
+			if authorization := payload.Authorization(); authorization != "" {
+				var err error
+				ctx, err = withToken(ctx, config.Verifier, authorization)
+				if err != nil {
+					return nil, nil, err
+				}
+			}
+
+			// The connection outlives the loaders of the upgrade request, so
+			// its operations load fresh data instead
+			return subscriptions.WithConnection(dataloaders.Without(ctx)), nil, nil
+		},
+		InitTimeout: initTimeout,
+		// graphql-transport-ws clients are pinged and must answer;
+		// graphql-ws clients are sent keep-alive messages
+		PingPongInterval:      config.KeepAliveInterval,
+		KeepAlivePingInterval: config.KeepAliveInterval,
+		ErrorFunc: func(ctx context.Context, err error) {
+			var wsErr transport.WebsocketError
+			if !errors.As(err, &wsErr) {
+				log.Printf("GraphQL WebSocket error: %v", err)
+				return
+			}
+			if !wsErr.IsReadError {
+				// A write that failed or timed out leaves the connection unusable
+				log.Printf("GraphQL WebSocket write failed, disconnecting: %v", wsErr.Err)
+				subscriptions.Disconnect(ctx)
+				return
+			}
+			// Clients closing the connection normally aren't worth logging
+			if !websocket.IsCloseError(wsErr.Err, websocket.CloseNormalClosure, websocket.CloseGoingAway, websocket.CloseNoStatusReceived) {
+				log.Printf("GraphQL WebSocket error: %v", err)
+			}
+		},
+	}
+}
+
+// limitWebSocketWrites makes writes to the connections of WebSocket requests
+// fail once they take longer than timeout, so a client that stops reading
+// can't stall the updates meant for it forever
+func limitWebSocketWrites(next http.Handler, timeout time.Duration) http.Handler {
+	if timeout <= 0 {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if websocket.IsWebSocketUpgrade(r) {
+			w = &hijackWriter{ResponseWriter: w, timeout: timeout}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// hijackWriter hands out its connection with a deadline on every write
+type hijackWriter struct {
+	http.ResponseWriter
+	timeout time.Duration
+}
+
+// Hijack takes over the connection
+func (w *hijackWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, rw, err := http.NewResponseController(w.ResponseWriter).Hijack()
+	if err != nil {
+		return nil, nil, err
+	}
+	return &writeDeadlineConn{Conn: conn, timeout: w.timeout}, rw, nil
+}
+
+// writeDeadlineConn fails writes that take longer than timeout
+type writeDeadlineConn struct {
+	net.Conn
+	timeout time.Duration
+}
+
+// Write writes p, giving up after the timeout
+func (c *writeDeadlineConn) Write(p []byte) (int, error) {
+	if err := c.Conn.SetWriteDeadline(time.Now().Add(c.timeout)); err != nil {
+		return 0, err
 	}
+	return c.Conn.Write(p)
+}
+
+// idempotencyKeyKey is the context key of a request's Idempotency-Key header
+type idempotencyKeyKey struct{}
+
+// forwardIdempotencyKey keeps the Idempotency-Key header of a GraphQL
+// request for idempotencyKeys to pass on
+func forwardIdempotencyKey(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if key := r.Header.Get(common.IdempotencyKeyHeader); key != "" {
+			r = r.WithContext(context.WithValue(r.Context(), idempotencyKeyKey{}, key))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// idempotencyKeys passes a request's idempotency key on to the services
+// each top-level mutation calls, suffixed with the field's alias so two
+// mutations in one document aren't taken for retries of each other.
+// Queries don't get a key.
+type idempotencyKeys struct{}
+
+var _ interface {
+	graphql.HandlerExtension
+	graphql.FieldInterceptor
+} = idempotencyKeys{}
+
+// ExtensionName names the extension
+func (k idempotencyKeys) ExtensionName() string {
+	return "IdempotencyKeys"
+}
+
+// Validate accepts any schema
+func (k idempotencyKeys) Validate(schema graphql.ExecutableSchema) error {
+	return nil
+}
+
+// InterceptField adds the key of a top-level mutation field to its context
+func (k idempotencyKeys) InterceptField(ctx context.Context, next graphql.Resolver) (any, error) {
+	key, ok := ctx.Value(idempotencyKeyKey{}).(string)
+	if field := graphql.GetFieldContext(ctx); ok && field.Object == "Mutation" {
+		ctx = metadata.AppendToOutgoingContext(ctx, common.IdempotencyKeyHeader, key+":"+field.Field.Alias)
+	}
+	return next(ctx)
+}
+
+// authenticate checks the bearer token of a GraphQL request, if it has one,
+// for the @auth directive, and passes it on to the services its resolvers
+// call. Requests with a bad token are turned away.
+func authenticate(verifier *auth.Verifier, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization := r.Header.Get(auth.AuthorizationHeader)
+		if authorization == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		ctx, err := withToken(r.Context(), verifier, authorization)
+		if err != nil {
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			w.WriteHeader(http.StatusUnauthorized)
+			gqlErr := gqlerror.Errorf("%s", err)
+			gqlErr.Extensions = map[string]any{"code": models.ErrorCodeUnauthorized}
+			json.NewEncoder(w).Encode(graphql.Response{Errors: gqlerror.List{gqlErr}})
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// withToken returns ctx carrying the claims of the bearer token in an
+// Authorization value, verified when there is a verifier, and the token for
+// the services
+func withToken(ctx context.Context, verifier *auth.Verifier, authorization string) (context.Context, error) {
+	token, ok := auth.ParseBearer(authorization)
+	if !ok {
+		return nil, errors.New("authorization must be a bearer token")
+	}
+
+	if verifier != nil {
+		claims, err := verifier.Verify(token)
+		if err != nil {
+			return nil, err
+		}
+		ctx = auth.NewContext(ctx, claims)
+	}
+
+	return auth.NewOutgoingContext(ctx, token), nil
 }
 
 // Handler returns the GraphQL HTTP handler
@@ -73,6 +355,11 @@ func (s *Server) PlaygroundHandler() http.Handler {
 	return s.playground
 }
 
+// Close stops watching the services and ends every subscription
+func (s *Server) Close() {
+	s.hub.Close()
+}
+
 // ServeHTTP implements http.Handler interface
 func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	s.handler.ServeHTTP(w, r)
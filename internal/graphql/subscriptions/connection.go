@@ -0,0 +1,25 @@
+package subscriptions
+
+import "context"
+
+type connectionKey struct{}
+
+// WithConnection returns the context for a new WebSocket connection.
+// Cancelling it, through Disconnect, closes the connection.
+func WithConnection(ctx context.Context) context.Context {
+	ctx, cancel := context.WithCancel(ctx)
+	return context.WithValue(ctx, connectionKey{}, cancel)
+}
+
+// OnConnection reports whether ctx belongs to a WebSocket connection
+func OnConnection(ctx context.Context) bool {
+	_, ok := ctx.Value(connectionKey{}).(context.CancelFunc)
+	return ok
+}
+
+// Disconnect closes the WebSocket connection ctx belongs to, if any
+func Disconnect(ctx context.Context) {
+	if cancel, ok := ctx.Value(connectionKey{}).(context.CancelFunc); ok {
+		cancel()
+	}
+}
@@ -0,0 +1,161 @@
+package subscriptions
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"learning/internal/auth"
+	"learning/internal/common"
+	"learning/internal/order"
+	"learning/internal/product"
+)
+
+// ErrUnavailable is returned when the service behind a subscription can't
+// stream its changes
+var ErrUnavailable = errors.New("subscription is not available")
+
+const (
+	// minRetryDelay and maxRetryDelay bound how long the hub waits before
+	// watching a service again after its stream broke
+	minRetryDelay = time.Second
+	maxRetryDelay = 30 * time.Second
+)
+
+// OrderWatcher streams order changes from the order service
+type OrderWatcher interface {
+	WatchOrders(ctx context.Context, userID string, fn func(*order.Order)) error
+}
+
+// StockWatcher streams product changes from the product service
+type StockWatcher interface {
+	WatchStock(ctx context.Context, productID string, fn func(*product.Product)) error
+}
+
+// Hub keeps one stream of changes open to each service and fans the changes
+// out to the gateway's subscribers, so the services see one watcher per
+// gateway rather than one per client
+type Hub struct {
+	orders *common.Broadcaster[*order.Order]
+	stock  *common.Broadcaster[*product.Product]
+
+	hasOrders bool
+	hasStock  bool
+
+	// credentials authenticate the gateway on its streams, when set
+	credentials *auth.ServiceCredentials
+
+	cancel context.CancelFunc
+	done   sync.WaitGroup
+}
+
+// NewHub starts watching the services; either watcher may be nil, in which
+// case its subscriptions are unavailable. Each subscriber buffers up to
+// bufferSize changes. The streams are opened with credentials, when given,
+// since they carry every user's changes.
+func NewHub(orderWatcher OrderWatcher, stockWatcher StockWatcher, bufferSize int, credentials *auth.ServiceCredentials) *Hub {
+	ctx, cancel := context.WithCancel(context.Background())
+	h := &Hub{
+		orders:      common.NewBroadcaster[*order.Order](bufferSize),
+		stock:       common.NewBroadcaster[*product.Product](bufferSize),
+		credentials: credentials,
+		cancel:      cancel,
+	}
+
+	if orderWatcher != nil {
+		h.hasOrders = true
+		h.follow(ctx, "order", func(ctx context.Context) error {
+			return orderWatcher.WatchOrders(ctx, "", h.orders.Publish)
+		})
+	}
+	if stockWatcher != nil {
+		h.hasStock = true
+		h.follow(ctx, "product", func(ctx context.Context) error {
+			return stockWatcher.WatchStock(ctx, "", h.stock.Publish)
+		})
+	}
+
+	return h
+}
+
+// SubscribeOrders subscribes to the orders of one user, or of every user
+// when userID is empty
+func (h *Hub) SubscribeOrders(userID string) (*common.Subscription[*order.Order], error) {
+	if !h.hasOrders {
+		return nil, ErrUnavailable
+	}
+	if userID == "" {
+		return h.orders.Subscribe(nil), nil
+	}
+	return h.orders.Subscribe(func(o *order.Order) bool {
+		return o.UserID == userID
+	}), nil
+}
+
+// SubscribeStock subscribes to one product, or to every product when
+// productID is empty
+func (h *Hub) SubscribeStock(productID string) (*common.Subscription[*product.Product], error) {
+	if !h.hasStock {
+		return nil, ErrUnavailable
+	}
+	if productID == "" {
+		return h.stock.Subscribe(nil), nil
+	}
+	return h.stock.Subscribe(func(p *product.Product) bool {
+		return p.ID == productID
+	}), nil
+}
+
+// Close stops watching the services and ends every subscription
+func (h *Hub) Close() {
+	h.cancel()
+	h.done.Wait()
+	h.orders.Close()
+	h.stock.Close()
+}
+
+// follow runs watch until ctx is done, starting it again with a growing
+// delay whenever the stream breaks. Changes made while the stream is down
+// are not announced.
+func (h *Hub) follow(ctx context.Context, service string, watch func(context.Context) error) {
+	h.done.Add(1)
+	go func() {
+		defer h.done.Done()
+
+		delay := minRetryDelay
+		for {
+			started := time.Now()
+			err := h.watch(ctx, watch)
+			if ctx.Err() != nil {
+				return
+			}
+
+			// A stream that stayed up for a while starts over with a short delay
+			if time.Since(started) > maxRetryDelay {
+				delay = minRetryDelay
+			}
+			log.Printf("Watching %s service changes failed, retrying in %v: %v", service, delay, err)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(delay):
+			}
+			delay = min(delay*2, maxRetryDelay)
+		}
+	}()
+}
+
+// watch runs watch once, authenticated with the hub's credentials
+func (h *Hub) watch(ctx context.Context, watch func(context.Context) error) error {
+	if h.credentials != nil {
+		var err error
+		ctx, err = h.credentials.NewOutgoingContext(ctx)
+		if err != nil {
+			return err
+		}
+	}
+	return watch(ctx)
+}
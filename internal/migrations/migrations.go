@@ -0,0 +1,385 @@
+package migrations
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"learning/internal/common"
+)
+
+//go:embed user/*.sql product/*.sql order/*.sql
+var files embed.FS
+
+// Services lists the services that own a migration set, in dependency order
+var Services = []string{"user", "product", "order"}
+
+// stateTable records which migrations have been applied to a database
+const stateTable = `
+CREATE TABLE IF NOT EXISTS schema_migrations (
+	service    VARCHAR(64)  NOT NULL,
+	version    INTEGER      NOT NULL,
+	name       VARCHAR(255) NOT NULL,
+	applied_at TIMESTAMP    NOT NULL,
+	PRIMARY KEY (service, version)
+)`
+
+var (
+	// fileNamePattern matches migration file names such as 0001_create_users.up.sql
+	fileNamePattern = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+	// namePattern matches the NAME part accepted by Create
+	namePattern = regexp.MustCompile(`^[a-z0-9_]+$`)
+)
+
+// Migration is a single versioned schema change
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Status describes whether a migration has been applied
+type Status struct {
+	Migration
+	Applied   bool
+	AppliedAt time.Time
+}
+
+// SchemaBehindError is returned when a database is missing migrations
+type SchemaBehindError struct {
+	Service string
+	Pending []Migration
+}
+
+func (e *SchemaBehindError) Error() string {
+	return fmt.Sprintf("%s schema is behind: %d pending migration(s), run `migrate up` or set AUTO_MIGRATE=true",
+		e.Service, len(e.Pending))
+}
+
+// Migrator applies and rolls back the migrations of one service
+type Migrator struct {
+	db         *common.Database
+	service    string
+	migrations []Migration
+}
+
+// NewMigrator creates a migrator for the embedded migrations of a service
+func NewMigrator(db *common.Database, service string) (*Migrator, error) {
+	migrations, err := Load(service)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Migrator{
+		db:         db,
+		service:    service,
+		migrations: migrations,
+	}, nil
+}
+
+// Load reads the embedded migrations of a service, ordered by version
+func Load(service string) ([]Migration, error) {
+	entries, err := fs.ReadDir(files, service)
+	if err != nil {
+		return nil, fmt.Errorf("unknown migration set %q: %w", service, err)
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, entry := range entries {
+		match := fileNamePattern.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("invalid migration file name %s/%s", service, entry.Name())
+		}
+
+		version, _ := strconv.Atoi(match[1])
+		content, err := files.ReadFile(path.Join(service, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		}
+		if m.Name != match[2] {
+			return nil, fmt.Errorf("migration %s/%04d has mismatched names %q and %q", service, version, m.Name, match[2])
+		}
+
+		if match[3] == "up" {
+			m.Up = string(content)
+		} else {
+			m.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" || m.Down == "" {
+			return nil, fmt.Errorf("migration %s/%04d_%s needs both up and down files", service, m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+// Status reports every known migration and whether it has been applied
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, len(m.migrations))
+	for i, migration := range m.migrations {
+		appliedAt, ok := applied[migration.Version]
+		statuses[i] = Status{
+			Migration: migration,
+			Applied:   ok,
+			AppliedAt: appliedAt,
+		}
+	}
+
+	return statuses, nil
+}
+
+// Pending returns the migrations that have not been applied yet
+func (m *Migrator) Pending(ctx context.Context) ([]Migration, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var pending []Migration
+	for _, migration := range m.migrations {
+		if _, ok := applied[migration.Version]; !ok {
+			pending = append(pending, migration)
+		}
+	}
+
+	return pending, nil
+}
+
+// Up applies all pending migrations in version order
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	pending, err := m.Pending(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var done []Migration
+	for _, migration := range pending {
+		if err := m.apply(ctx, migration, true); err != nil {
+			return done, err
+		}
+		done = append(done, migration)
+	}
+
+	return done, nil
+}
+
+// Down rolls back the n most recently applied migrations
+func (m *Migrator) Down(ctx context.Context, n int) ([]Migration, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var done []Migration
+	for i := len(m.migrations) - 1; i >= 0 && len(done) < n; i-- {
+		migration := m.migrations[i]
+		if _, ok := applied[migration.Version]; !ok {
+			continue
+		}
+		if err := m.apply(ctx, migration, false); err != nil {
+			return done, err
+		}
+		done = append(done, migration)
+	}
+
+	return done, nil
+}
+
+// EnsureCurrent fails with SchemaBehindError when migrations are pending,
+// or applies them first when autoMigrate is set
+func (m *Migrator) EnsureCurrent(ctx context.Context, autoMigrate bool) error {
+	pending, err := m.Pending(ctx)
+	if err != nil {
+		return err
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+
+	if !autoMigrate {
+		return &SchemaBehindError{Service: m.service, Pending: pending}
+	}
+
+	applied, err := m.Up(ctx)
+	for _, migration := range applied {
+		log.Printf("Applied %s migration %04d_%s", m.service, migration.Version, migration.Name)
+	}
+	return err
+}
+
+// apply runs one migration in a transaction and records the new state
+func (m *Migrator) apply(ctx context.Context, migration Migration, up bool) error {
+	script := migration.Down
+	if up {
+		script = migration.Up
+	}
+
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin migration transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, stmt := range splitStatements(script) {
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("migration %s/%04d_%s failed: %w", m.service, migration.Version, migration.Name, err)
+		}
+	}
+
+	if up {
+		_, err = tx.ExecContext(ctx, m.db.Rebind(
+			"INSERT INTO schema_migrations (service, version, name, applied_at) VALUES (?, ?, ?, ?)"),
+			m.service, migration.Version, migration.Name, time.Now().UTC(),
+		)
+	} else {
+		_, err = tx.ExecContext(ctx, m.db.Rebind(
+			"DELETE FROM schema_migrations WHERE service = ? AND version = ?"),
+			m.service, migration.Version,
+		)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to record migration state: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// applied returns the applied versions of this service and when they ran
+func (m *Migrator) applied(ctx context.Context) (map[int]time.Time, error) {
+	if _, err := m.db.ExecContext(ctx, stateTable); err != nil {
+		return nil, fmt.Errorf("failed to create migration state table: %w", err)
+	}
+
+	rows, err := m.db.QueryContext(ctx, m.db.Rebind(
+		"SELECT version, applied_at FROM schema_migrations WHERE service = ?"), m.service)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migration state: %w", err)
+	}
+	defer rows.Close()
+
+	applied := make(map[int]time.Time)
+	for rows.Next() {
+		var version int
+		var appliedAt time.Time
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, fmt.Errorf("failed to read migration state: %w", err)
+		}
+		applied[version] = appliedAt
+	}
+
+	return applied, rows.Err()
+}
+
+// Create writes an empty up/down migration pair for a service into dir
+// and returns the paths of the new files
+func Create(dir, service, name string) (string, string, error) {
+	name = strings.ToLower(strings.ReplaceAll(strings.TrimSpace(name), " ", "_"))
+	if !namePattern.MatchString(name) {
+		return "", "", fmt.Errorf("invalid migration name %q: use letters, digits and underscores", name)
+	}
+
+	serviceDir := filepath.Join(dir, service)
+	entries, err := os.ReadDir(serviceDir)
+	if err != nil {
+		return "", "", fmt.Errorf("unknown migration set %q: %w", service, err)
+	}
+
+	next := 1
+	for _, entry := range entries {
+		if match := fileNamePattern.FindStringSubmatch(entry.Name()); match != nil {
+			if version, _ := strconv.Atoi(match[1]); version >= next {
+				next = version + 1
+			}
+		}
+	}
+
+	base := filepath.Join(serviceDir, fmt.Sprintf("%04d_%s", next, name))
+	upPath, downPath := base+".up.sql", base+".down.sql"
+
+	if err := os.WriteFile(upPath, []byte("-- "+name+" (up)\n"), 0o644); err != nil {
+		return "", "", err
+	}
+	if err := os.WriteFile(downPath, []byte("-- "+name+" (down)\n"), 0o644); err != nil {
+		return "", "", err
+	}
+
+	return upPath, downPath, nil
+}
+
+// splitStatements splits a SQL script on semicolons that are outside of
+// quotes and comments, dropping empty statements
+func splitStatements(script string) []string {
+	var (
+		statements []string
+		current    strings.Builder
+		quote      rune
+		comment    bool
+	)
+
+	runes := []rune(script)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+
+		switch {
+		case comment:
+			if r == '\n' {
+				comment = false
+			}
+			continue
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"':
+			quote = r
+		case r == '-' && i+1 < len(runes) && runes[i+1] == '-':
+			comment = true
+			continue
+		case r == ';':
+			if stmt := strings.TrimSpace(current.String()); stmt != "" {
+				statements = append(statements, stmt)
+			}
+			current.Reset()
+			continue
+		}
+
+		current.WriteRune(r)
+	}
+
+	if stmt := strings.TrimSpace(current.String()); stmt != "" {
+		statements = append(statements, stmt)
+	}
+
+	return statements
+}
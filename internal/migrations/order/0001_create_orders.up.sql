@@ -0,0 +1,10 @@
+CREATE TABLE orders (
+	id           VARCHAR(36)      PRIMARY KEY,
+	user_id      VARCHAR(36)      NOT NULL,
+	total_amount DOUBLE PRECISION NOT NULL,
+	status       INTEGER          NOT NULL,
+	created_at   TIMESTAMP        NOT NULL,
+	updated_at   TIMESTAMP        NOT NULL
+);
+
+CREATE INDEX idx_orders_user_id ON orders (user_id);
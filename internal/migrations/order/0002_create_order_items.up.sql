@@ -0,0 +1,12 @@
+CREATE TABLE order_items (
+	id            VARCHAR(36)      PRIMARY KEY,
+	order_id      VARCHAR(36)      NOT NULL REFERENCES orders (id) ON DELETE CASCADE,
+	position      INTEGER          NOT NULL,
+	product_id    VARCHAR(36)      NOT NULL,
+	product_name  VARCHAR(255)     NOT NULL,
+	product_price DOUBLE PRECISION NOT NULL,
+	quantity      INTEGER          NOT NULL,
+	total         DOUBLE PRECISION NOT NULL
+);
+
+CREATE INDEX idx_order_items_order_id ON order_items (order_id);
@@ -0,0 +1,10 @@
+CREATE TABLE order_status_history (
+	order_id    VARCHAR(36)  NOT NULL REFERENCES orders (id) ON DELETE CASCADE,
+	seq         INTEGER      NOT NULL,
+	from_status INTEGER      NOT NULL,
+	to_status   INTEGER      NOT NULL,
+	actor       VARCHAR(255) NOT NULL,
+	reason      TEXT         NOT NULL,
+	created_at  TIMESTAMP    NOT NULL,
+	PRIMARY KEY (order_id, seq)
+);
@@ -0,0 +1,7 @@
+CREATE TABLE order_sagas (
+	id         VARCHAR(36) PRIMARY KEY,
+	state      VARCHAR(32) NOT NULL,
+	data       TEXT        NOT NULL,
+	created_at TIMESTAMP   NOT NULL,
+	updated_at TIMESTAMP   NOT NULL
+);
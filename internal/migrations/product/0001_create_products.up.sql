@@ -0,0 +1,10 @@
+CREATE TABLE products (
+	id          VARCHAR(36)      PRIMARY KEY,
+	name        VARCHAR(255)     NOT NULL,
+	description TEXT             NOT NULL,
+	price       DOUBLE PRECISION NOT NULL,
+	stock       INTEGER          NOT NULL CHECK (stock >= 0),
+	category    VARCHAR(255)     NOT NULL,
+	created_at  TIMESTAMP        NOT NULL,
+	updated_at  TIMESTAMP        NOT NULL
+);
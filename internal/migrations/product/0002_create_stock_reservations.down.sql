@@ -0,0 +1,3 @@
+DROP TABLE stock_reservations;
+
+ALTER TABLE products DROP COLUMN reserved;
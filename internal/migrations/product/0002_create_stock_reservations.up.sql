@@ -0,0 +1,13 @@
+ALTER TABLE products ADD COLUMN reserved INTEGER NOT NULL DEFAULT 0 CHECK (reserved >= 0);
+
+CREATE TABLE stock_reservations (
+	id         VARCHAR(64) PRIMARY KEY,
+	product_id VARCHAR(36) NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+	quantity   INTEGER     NOT NULL CHECK (quantity > 0),
+	status     VARCHAR(16) NOT NULL,
+	expires_at TIMESTAMP   NOT NULL,
+	created_at TIMESTAMP   NOT NULL,
+	updated_at TIMESTAMP   NOT NULL
+);
+
+CREATE INDEX idx_stock_reservations_status_expires_at ON stock_reservations (status, expires_at);
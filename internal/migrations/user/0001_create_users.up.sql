@@ -0,0 +1,8 @@
+CREATE TABLE users (
+	id         VARCHAR(36)  PRIMARY KEY,
+	name       VARCHAR(255) NOT NULL,
+	email      VARCHAR(255) NOT NULL UNIQUE,
+	phone      VARCHAR(64)  NOT NULL,
+	created_at TIMESTAMP    NOT NULL,
+	updated_at TIMESTAMP    NOT NULL
+);
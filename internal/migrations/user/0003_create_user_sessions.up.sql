@@ -0,0 +1,10 @@
+CREATE TABLE user_sessions (
+	id         VARCHAR(36) PRIMARY KEY,
+	user_id    VARCHAR(36) NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+	token_hash VARCHAR(64) NOT NULL,
+	expires_at TIMESTAMP   NOT NULL,
+	created_at TIMESTAMP   NOT NULL,
+	updated_at TIMESTAMP   NOT NULL
+);
+
+CREATE INDEX idx_user_sessions_expires_at ON user_sessions (expires_at);
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"strings"
 	"time"
 
 	"google.golang.org/grpc"
@@ -19,9 +20,11 @@ type UserServiceClient struct {
 	conn   *grpc.ClientConn
 }
 
-// NewUserServiceClient creates a new user service client
-func NewUserServiceClient(address string) (*UserServiceClient, error) {
-	conn, err := grpc.Dial(address, grpc.WithTransportCredentials(insecure.NewCredentials()))
+// NewUserServiceClient creates a new user service client; opts add to the
+// options the connection is dialled with
+func NewUserServiceClient(address string, opts ...grpc.DialOption) (*UserServiceClient, error) {
+	opts = append([]grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}, opts...)
+	conn, err := grpc.Dial(address, opts...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to user service: %w", err)
 	}
@@ -60,9 +63,11 @@ type ProductServiceClient struct {
 	conn   *grpc.ClientConn
 }
 
-// NewProductServiceClient creates a new product service client
-func NewProductServiceClient(address string) (*ProductServiceClient, error) {
-	conn, err := grpc.Dial(address, grpc.WithTransportCredentials(insecure.NewCredentials()))
+// NewProductServiceClient creates a new product service client; opts add to the
+// options the connection is dialled with
+func NewProductServiceClient(address string, opts ...grpc.DialOption) (*ProductServiceClient, error) {
+	opts = append([]grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}, opts...)
+	conn, err := grpc.Dial(address, opts...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to product service: %w", err)
 	}
@@ -75,35 +80,115 @@ func NewProductServiceClient(address string) (*ProductServiceClient, error) {
 	}, nil
 }
 
-// GetProduct retrieves a product by ID
-func (c *ProductServiceClient) GetProduct(ctx context.Context, productID string) (*productpb.Product, error) {
+// BatchGetProducts retrieves several products in one call. It returns the
+// products found, in request order, and the IDs that matched no product.
+func (c *ProductServiceClient) BatchGetProducts(ctx context.Context, productIDs []string) ([]*productpb.Product, []string, error) {
 	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
 
-	resp, err := c.client.GetProduct(ctx, &productpb.GetProductRequest{
-		Id: productID,
+	resp, err := c.client.BatchGetProducts(ctx, &productpb.BatchGetProductsRequest{
+		Ids: productIDs,
 	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to get product: %w", err)
+		return nil, nil, fmt.Errorf("failed to get products: %w", err)
 	}
 
-	return resp.Product, nil
+	return resp.Products, resp.NotFoundIds, nil
 }
 
-// UpdateStock updates product stock
-func (c *ProductServiceClient) UpdateStock(ctx context.Context, productID string, quantity int32) error {
+// BatchUpdateStock updates the stock of several products in one call, all
+// or none. Rejected changes are returned as a *StockUpdateError.
+func (c *ProductServiceClient) BatchUpdateStock(ctx context.Context, changes []*productpb.StockChange) error {
 	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
 
-	_, err := c.client.UpdateStock(ctx, &productpb.UpdateStockRequest{
-		ProductId: productID,
-		Quantity:  quantity, // negative to reduce stock
+	resp, err := c.client.BatchUpdateStock(ctx, &productpb.BatchUpdateStockRequest{
+		Changes: changes, // negative quantities reduce stock
 	})
 	if err != nil {
 		return fmt.Errorf("failed to update stock: %w", err)
 	}
 
-	log.Printf("Updated stock for product %s by %d", productID, quantity)
+	if !resp.Success {
+		return &StockUpdateError{Failures: resp.Failures}
+	}
+
+	log.Printf("Updated stock for %d products", len(changes))
+	return nil
+}
+
+// StockUpdateError reports the changes of a batch stock update the product
+// service rejected; none of the batch was applied
+type StockUpdateError struct {
+	Failures []*productpb.StockChangeFailure
+}
+
+func (e *StockUpdateError) Error() string {
+	parts := make([]string, len(e.Failures))
+	for i, failure := range e.Failures {
+		parts[i] = fmt.Sprintf("product %s: %s", failure.ProductId, failure.Error)
+	}
+	return "stock update rejected: " + strings.Join(parts, ", ")
+}
+
+// Rejected reports whether the change at index was one of those rejected
+func (e *StockUpdateError) Rejected(index int) bool {
+	for _, failure := range e.Failures {
+		if int(failure.Index) == index {
+			return true
+		}
+	}
+	return false
+}
+
+// ReserveStock holds stock of a product for ttl under the given reservation ID
+func (c *ProductServiceClient) ReserveStock(ctx context.Context, reservationID, productID string, quantity int32, ttl time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	_, err := c.client.ReserveStock(ctx, &productpb.ReserveStockRequest{
+		ReservationId: reservationID,
+		ProductId:     productID,
+		Quantity:      quantity,
+		TtlSeconds:    int32(ttl / time.Second),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to reserve stock: %w", err)
+	}
+
+	log.Printf("Reserved %d of product %s as %s", quantity, productID, reservationID)
+	return nil
+}
+
+// CommitReservation turns a stock reservation into a permanent reduction
+func (c *ProductServiceClient) CommitReservation(ctx context.Context, reservationID string) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	_, err := c.client.CommitReservation(ctx, &productpb.CommitReservationRequest{
+		ReservationId: reservationID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to commit reservation: %w", err)
+	}
+
+	log.Printf("Committed stock reservation %s", reservationID)
+	return nil
+}
+
+// ReleaseReservation gives the stock of a reservation back
+func (c *ProductServiceClient) ReleaseReservation(ctx context.Context, reservationID string) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	_, err := c.client.ReleaseReservation(ctx, &productpb.ReleaseReservationRequest{
+		ReservationId: reservationID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to release reservation: %w", err)
+	}
+
+	log.Printf("Released stock reservation %s", reservationID)
 	return nil
 }
 
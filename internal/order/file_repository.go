@@ -0,0 +1,108 @@
+package order
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"learning/internal/common"
+)
+
+const opPut = "put"
+
+// journalRecord is a single logged change to the order set.
+// Orders are never deleted; records carry the whole order and its whole
+// status history so replaying one twice is harmless.
+type journalRecord struct {
+	Op      string              `json:"op"`
+	Order   *Order              `json:"order,omitempty"`
+	History []*StatusTransition `json:"history,omitempty"`
+}
+
+// snapshot is the persisted form of the whole order set
+type snapshot struct {
+	Orders  map[string]*Order              `json:"orders"`
+	History map[string][]*StatusTransition `json:"history"`
+}
+
+// NewFileBackedRepository creates an in-memory repository whose contents are
+// persisted to a snapshot and append-only log inside dataDir
+func NewFileBackedRepository(dataDir string) (*InMemoryRepository, error) {
+	journal, err := common.OpenJournal(dataDir, "orders", common.DefaultCompactEvery)
+	if err != nil {
+		return nil, err
+	}
+
+	repo := NewInMemoryRepository()
+	err = journal.Replay(
+		func(data []byte) error {
+			return json.Unmarshal(data, &snapshot{Orders: repo.orders, History: repo.history})
+		},
+		func(data []byte) error {
+			var record journalRecord
+			if err := json.Unmarshal(data, &record); err != nil {
+				return err
+			}
+			return repo.apply(record)
+		},
+	)
+	if err != nil {
+		journal.Close()
+		return nil, err
+	}
+
+	repo.journal = journal
+	log.Printf("Loaded %d orders from %s", len(repo.orders), dataDir)
+	return repo, nil
+}
+
+// Close closes the journal of a file-backed repository
+func (r *InMemoryRepository) Close() error {
+	if r.journal == nil {
+		return nil
+	}
+	return r.journal.Close()
+}
+
+// apply replays a journal record against the order map
+func (r *InMemoryRepository) apply(record journalRecord) error {
+	switch record.Op {
+	case opPut:
+		if record.Order == nil {
+			return fmt.Errorf("put record without order")
+		}
+		r.orders[record.Order.ID] = record.Order
+		if record.History != nil {
+			r.history[record.Order.ID] = record.History
+		}
+	default:
+		return fmt.Errorf("unknown journal op %q", record.Op)
+	}
+	return nil
+}
+
+// persist logs a change before it is applied to the map.
+// The caller must hold the write lock.
+func (r *InMemoryRepository) persist(record journalRecord) error {
+	if r.journal == nil {
+		return nil
+	}
+
+	if err := r.journal.Append(record); err != nil {
+		return fmt.Errorf("failed to persist order: %w", err)
+	}
+	return nil
+}
+
+// compact writes a fresh snapshot once the log is long enough.
+// The caller must hold the write lock and have applied the latest change.
+func (r *InMemoryRepository) compact() {
+	if r.journal == nil || !r.journal.NeedsCompaction() {
+		return
+	}
+
+	if err := r.journal.Compact(snapshot{Orders: r.orders, History: r.history}); err != nil {
+		// The log still holds every change, so this only delays compaction
+		log.Printf("Failed to compact order journal: %v", err)
+	}
+}
@@ -0,0 +1,341 @@
+package order
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"learning/internal/common"
+	pb "learning/pkg/order/pb"
+)
+
+// GRPCRepository implements Repository on top of the order service gRPC API.
+// It lets other processes (e.g. the API gateway) share the order service's data.
+//
+// Create goes through the CreateOrder RPC, so the order service still runs
+// its user and stock checks and computes prices; only UserID and each item's
+// ProductID and Quantity are taken from the given order. Likewise, moving an
+// order to CANCELLED goes through CancelOrder so its stock is restored.
+type GRPCRepository struct {
+	client pb.OrderServiceClient
+}
+
+// NewGRPCRepository creates a repository backed by an order service connection
+func NewGRPCRepository(conn grpc.ClientConnInterface) *GRPCRepository {
+	return &GRPCRepository{
+		client: pb.NewOrderServiceClient(conn),
+	}
+}
+
+// Create creates a new order through the order service
+func (r *GRPCRepository) Create(ctx context.Context, order *Order) (*Order, error) {
+	items := make([]*pb.OrderItemRequest, len(order.Items))
+	for i, item := range order.Items {
+		items[i] = &pb.OrderItemRequest{
+			ProductId: item.ProductID,
+			Quantity:  item.Quantity,
+		}
+	}
+
+	resp, err := r.client.CreateOrder(ctx, &pb.CreateOrderRequest{
+		UserId: order.UserID,
+		Items:  items,
+	})
+	if err != nil {
+		return nil, fromStatus(err)
+	}
+
+	return protoToOrder(resp.Order), nil
+}
+
+// GetByID retrieves an order by ID
+func (r *GRPCRepository) GetByID(ctx context.Context, id string) (*Order, error) {
+	resp, err := r.client.GetOrder(ctx, &pb.GetOrderRequest{Id: id})
+	if err != nil {
+		return nil, fromStatus(err)
+	}
+
+	return protoToOrder(resp.Order), nil
+}
+
+// GetByIDs retrieves several orders by ID in one call
+func (r *GRPCRepository) GetByIDs(ctx context.Context, ids []string) ([]*Order, []string, error) {
+	resp, err := r.client.BatchGetOrders(ctx, &pb.BatchGetOrdersRequest{Ids: ids})
+	if err != nil {
+		return nil, nil, fromStatus(err)
+	}
+
+	orders := make([]*Order, len(resp.Orders))
+	for i, o := range resp.Orders {
+		orders[i] = protoToOrder(o)
+	}
+
+	return orders, resp.NotFoundIds, nil
+}
+
+// UpdateStatus moves an order to transition.To through the order service,
+// which checks the transition against the order's current status itself
+// and records the caller as its actor; transition.From and transition.Actor
+// are not sent. Cancellation goes through the CancelOrder RPC
+// so the order's stock is restored.
+func (r *GRPCRepository) UpdateStatus(ctx context.Context, id string, transition *StatusTransition) (*Order, error) {
+	if transition.To == OrderStatusCancelled {
+		resp, err := r.client.CancelOrder(ctx, &pb.CancelOrderRequest{
+			Id:     id,
+			Reason: transition.Reason,
+		})
+		if err != nil {
+			return nil, fromStatus(err)
+		}
+		return protoToOrder(resp.Order), nil
+	}
+
+	resp, err := r.client.UpdateOrderStatus(ctx, &pb.UpdateOrderStatusRequest{
+		Id:     id,
+		Status: pb.OrderStatus(transition.To),
+		Reason: transition.Reason,
+	})
+	if err != nil {
+		return nil, fromStatus(err)
+	}
+
+	return protoToOrder(resp.Order), nil
+}
+
+// GetHistory retrieves the status transitions of an order, oldest first
+func (r *GRPCRepository) GetHistory(ctx context.Context, orderID string) ([]*StatusTransition, error) {
+	resp, err := r.client.GetOrderHistory(ctx, &pb.GetOrderHistoryRequest{Id: orderID})
+	if err != nil {
+		return nil, fromStatus(err)
+	}
+
+	return protoToTransitions(resp.Transitions), nil
+}
+
+// GetHistories retrieves the status transitions of several orders in one
+// call, keyed by order ID
+func (r *GRPCRepository) GetHistories(ctx context.Context, orderIDs []string) (map[string][]*StatusTransition, error) {
+	resp, err := r.client.BatchGetOrderHistory(ctx, &pb.BatchGetOrderHistoryRequest{Ids: orderIDs})
+	if err != nil {
+		return nil, fromStatus(err)
+	}
+
+	histories := make(map[string][]*StatusTransition, len(resp.Histories))
+	for _, h := range resp.Histories {
+		histories[h.OrderId] = protoToTransitions(h.Transitions)
+	}
+
+	return histories, nil
+}
+
+// ListByUser retrieves orders for a specific user with pagination.
+// An offset is converted to a page number, so it should be a multiple of the
+// limit; cursors are sent as a page token.
+func (r *GRPCRepository) ListByUser(ctx context.Context, userID string, query common.PageQuery) ([]*Order, common.PageInfo, error) {
+	if query.Limit <= 0 {
+		return []*Order{}, common.PageInfo{}, nil
+	}
+
+	page, pageToken := pageRequest(query)
+	resp, err := r.client.ListOrdersByUser(ctx, &pb.ListOrdersByUserRequest{
+		UserId:    userID,
+		Page:      page,
+		PageSize:  int32(query.Limit),
+		PageToken: pageToken,
+		SkipTotal: query.SkipTotal,
+	})
+	if err != nil {
+		return nil, common.PageInfo{}, fromStatus(err)
+	}
+
+	return protoToOrders(resp.Orders), pageInfo(resp.Total, resp.PreviousPageToken, resp.NextPageToken), nil
+}
+
+// ListByUsers retrieves one page of orders for each of several users.
+// An offset is converted to a page number, so it should be a multiple of the
+// limit; cursors are sent as a page token.
+func (r *GRPCRepository) ListByUsers(ctx context.Context, userIDs []string, status OrderStatus, query common.PageQuery) ([]*UserOrders, error) {
+	if len(userIDs) == 0 || query.Limit <= 0 {
+		return []*UserOrders{}, nil
+	}
+
+	page, pageToken := pageRequest(query)
+	resp, err := r.client.BatchListOrdersByUser(ctx, &pb.BatchListOrdersByUserRequest{
+		UserIds:   userIDs,
+		Status:    pb.OrderStatus(status),
+		Page:      page,
+		PageSize:  int32(query.Limit),
+		PageToken: pageToken,
+		SkipTotal: query.SkipTotal,
+	})
+	if err != nil {
+		return nil, fromStatus(err)
+	}
+
+	results := make([]*UserOrders, len(resp.Results))
+	for i, result := range resp.Results {
+		results[i] = &UserOrders{
+			UserID: result.UserId,
+			Orders: protoToOrders(result.Orders),
+			Page:   pageInfo(result.Total, result.PreviousPageToken, result.NextPageToken),
+		}
+	}
+	return results, nil
+}
+
+// List retrieves orders with pagination and optional filters.
+// An offset is converted to a page number, so it should be a multiple of the
+// limit; cursors are sent as a page token.
+func (r *GRPCRepository) List(ctx context.Context, query common.PageQuery, filter ListFilter) ([]*Order, common.PageInfo, error) {
+	if query.Limit <= 0 {
+		return []*Order{}, common.PageInfo{}, nil
+	}
+
+	page, pageToken := pageRequest(query)
+	req := &pb.ListOrdersRequest{
+		Page:      page,
+		PageSize:  int32(query.Limit),
+		PageToken: pageToken,
+		Status:    pb.OrderStatus(filter.Status),
+		UserId:    filter.UserID,
+		SkipTotal: query.SkipTotal,
+	}
+	if !filter.CreatedFrom.IsZero() {
+		req.CreatedFrom = timestamppb.New(filter.CreatedFrom)
+	}
+	if !filter.CreatedTo.IsZero() {
+		req.CreatedTo = timestamppb.New(filter.CreatedTo)
+	}
+
+	resp, err := r.client.ListOrders(ctx, req)
+	if err != nil {
+		return nil, common.PageInfo{}, fromStatus(err)
+	}
+
+	return protoToOrders(resp.Orders), pageInfo(resp.Total, resp.PreviousPageToken, resp.NextPageToken), nil
+}
+
+// WatchOrders calls fn with each change to the orders of one user, or of
+// every user when userID is empty, until ctx is done or the stream breaks
+func (r *GRPCRepository) WatchOrders(ctx context.Context, userID string, fn func(*Order)) error {
+	stream, err := r.client.WatchOrders(ctx, &pb.WatchOrdersRequest{UserId: userID})
+	if err != nil {
+		return fromStatus(err)
+	}
+
+	for {
+		resp, err := stream.Recv()
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return fromStatus(err)
+		}
+		fn(protoToOrder(resp.Order))
+	}
+}
+
+// pageRequest turns a page query into the page number or page token of a
+// list request
+func pageRequest(query common.PageQuery) (int32, string) {
+	if query.IsKeyset() {
+		return 0, query.PageToken()
+	}
+	return int32(query.Offset/query.Limit + 1), ""
+}
+
+// pageInfo rebuilds the page info of a list response
+func pageInfo(total int32, previousPageToken, nextPageToken string) common.PageInfo {
+	return common.PageInfo{
+		Total:       int(total),
+		HasPrevious: previousPageToken != "",
+		HasNext:     nextPageToken != "",
+	}
+}
+
+// protoToTransitions converts protobuf status transitions to domain ones
+func protoToTransitions(transitions []*pb.OrderStatusTransition) []*StatusTransition {
+	history := make([]*StatusTransition, len(transitions))
+	for i, t := range transitions {
+		history[i] = &StatusTransition{
+			From:      OrderStatus(t.FromStatus),
+			To:        OrderStatus(t.ToStatus),
+			Actor:     t.Actor,
+			Reason:    t.Reason,
+			Timestamp: t.Timestamp.AsTime(),
+		}
+	}
+	return history
+}
+
+// protoToOrder converts protobuf order to domain order
+func protoToOrder(o *pb.Order) *Order {
+	if o == nil {
+		return nil
+	}
+
+	items := make([]*OrderItem, len(o.Items))
+	for i, item := range o.Items {
+		items[i] = &OrderItem{
+			ID:           item.Id,
+			ProductID:    item.ProductId,
+			ProductName:  item.ProductName,
+			ProductPrice: item.ProductPrice,
+			Quantity:     item.Quantity,
+			Total:        item.Total,
+		}
+	}
+
+	return &Order{
+		ID:          o.Id,
+		UserID:      o.UserId,
+		Items:       items,
+		TotalAmount: o.TotalAmount,
+		Status:      OrderStatus(o.Status),
+		CreatedAt:   o.CreatedAt.AsTime(),
+		UpdatedAt:   o.UpdatedAt.AsTime(),
+	}
+}
+
+// protoToOrders converts a list of protobuf orders to domain orders
+func protoToOrders(orders []*pb.Order) []*Order {
+	result := make([]*Order, len(orders))
+	for i, o := range orders {
+		result[i] = protoToOrder(o)
+	}
+	return result
+}
+
+// fromStatus converts a gRPC status error back into the matching domain error
+func fromStatus(err error) error {
+	st, ok := status.FromError(err)
+	if !ok {
+		return err
+	}
+
+	switch st.Code() {
+	case codes.NotFound:
+		return ErrOrderNotFound
+	case codes.AlreadyExists:
+		return ErrOrderAlreadyExists
+	case codes.InvalidArgument:
+		return NewValidationError(st.Message())
+	case codes.FailedPrecondition:
+		if msg, ok := strings.CutPrefix(st.Message(), ErrInvalidStatusTransition.Error()); ok {
+			return fmt.Errorf("%w%s", ErrInvalidStatusTransition, msg)
+		}
+		return fmt.Errorf("order service: %w", err)
+	case codes.Aborted:
+		return ErrStatusConflict
+	case codes.Unauthenticated, codes.PermissionDenied:
+		// The caller's token or role falls short; pass the reason on as is
+		return err
+	default:
+		return fmt.Errorf("order service: %w", err)
+	}
+}
@@ -0,0 +1,83 @@
+package order
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+
+	"learning/internal/common"
+	pb "learning/pkg/order/pb"
+)
+
+// GRPCService offers the order operations of Service through the order
+// service gRPC API, for processes that don't run their own sagas (e.g. the
+// API gateway). The order service applies its checks and status rules to
+// every call.
+type GRPCService struct {
+	client pb.OrderServiceClient
+	repo   *GRPCRepository
+}
+
+// NewGRPCService creates a service backed by an order service connection
+func NewGRPCService(conn grpc.ClientConnInterface) *GRPCService {
+	client := pb.NewOrderServiceClient(conn)
+	return &GRPCService{
+		client: client,
+		repo:   &GRPCRepository{client: client},
+	}
+}
+
+// CreateOrder creates an order through the CreateOrder RPC
+func (s *GRPCService) CreateOrder(ctx context.Context, userID string, items []*OrderItemRequest) (*Order, error) {
+	pbItems := make([]*pb.OrderItemRequest, len(items))
+	for i, item := range items {
+		pbItems[i] = &pb.OrderItemRequest{
+			ProductId: item.ProductID,
+			Quantity:  item.Quantity,
+		}
+	}
+
+	resp, err := s.client.CreateOrder(ctx, &pb.CreateOrderRequest{
+		UserId: userID,
+		Items:  pbItems,
+	})
+	if err != nil {
+		return nil, fromStatus(err)
+	}
+
+	return protoToOrder(resp.Order), nil
+}
+
+// UpdateOrderStatus moves an order to a new status through the
+// UpdateOrderStatus RPC
+func (s *GRPCService) UpdateOrderStatus(ctx context.Context, id string, status OrderStatus, reason string) (*Order, error) {
+	resp, err := s.client.UpdateOrderStatus(ctx, &pb.UpdateOrderStatusRequest{
+		Id:     id,
+		Status: pb.OrderStatus(status),
+		Reason: reason,
+	})
+	if err != nil {
+		return nil, fromStatus(err)
+	}
+
+	return protoToOrder(resp.Order), nil
+}
+
+// CancelOrder cancels an order through the CancelOrder RPC
+func (s *GRPCService) CancelOrder(ctx context.Context, id, reason string) (*Order, error) {
+	resp, err := s.client.CancelOrder(ctx, &pb.CancelOrderRequest{
+		Id:     id,
+		Reason: reason,
+	})
+	if err != nil {
+		return nil, fromStatus(err)
+	}
+
+	return protoToOrder(resp.Order), nil
+}
+
+// ListOrders retrieves orders through the ListOrders RPC
+func (s *GRPCService) ListOrders(ctx context.Context, query common.PageQuery, filter ListFilter) ([]*Order, common.PageInfo, error) {
+	query.Limit = common.PageSize(query.Limit)
+	return s.repo.List(ctx, query, filter)
+}
@@ -2,12 +2,15 @@ package order
 
 import (
 	"context"
+	"errors"
 	"log"
 
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/timestamppb"
 
+	"learning/internal/auth"
+	"learning/internal/common"
 	pb "learning/pkg/order/pb"
 )
 
@@ -41,6 +44,10 @@ func (h *Handler) CreateOrder(ctx context.Context, req *pb.CreateOrderRequest) (
 	if err != nil {
 		log.Printf("CreateOrder error: %v", err)
 
+		if err == auth.ErrPermissionDenied {
+			return nil, status.Error(codes.PermissionDenied, "orders can only be placed for yourself")
+		}
+
 		// Handle validation errors
 		if validationErr, ok := err.(*ValidationError); ok {
 			return nil, status.Error(codes.InvalidArgument, validationErr.Message)
@@ -74,11 +81,37 @@ func (h *Handler) GetOrder(ctx context.Context, req *pb.GetOrderRequest) (*pb.Ge
 	}, nil
 }
 
+// BatchGetOrders retrieves several orders by ID
+func (h *Handler) BatchGetOrders(ctx context.Context, req *pb.BatchGetOrdersRequest) (*pb.BatchGetOrdersResponse, error) {
+	log.Printf("BatchGetOrders request: %d IDs", len(req.Ids))
+
+	orders, notFound, err := h.service.BatchGetOrders(ctx, req.Ids)
+	if err != nil {
+		log.Printf("BatchGetOrders error: %v", err)
+
+		if validationErr, ok := err.(*ValidationError); ok {
+			return nil, status.Error(codes.InvalidArgument, validationErr.Message)
+		}
+
+		return nil, status.Error(codes.Internal, "failed to get orders")
+	}
+
+	protoOrders := make([]*pb.Order, len(orders))
+	for i, order := range orders {
+		protoOrders[i] = h.orderToProto(order)
+	}
+
+	return &pb.BatchGetOrdersResponse{
+		Orders:      protoOrders,
+		NotFoundIds: notFound,
+	}, nil
+}
+
 // UpdateOrderStatus updates the status of an order
 func (h *Handler) UpdateOrderStatus(ctx context.Context, req *pb.UpdateOrderStatusRequest) (*pb.UpdateOrderStatusResponse, error) {
 	log.Printf("UpdateOrderStatus request: %+v", req)
 
-	order, err := h.service.UpdateOrderStatus(ctx, req.Id, OrderStatus(req.Status))
+	order, err := h.service.UpdateOrderStatus(ctx, req.Id, OrderStatus(req.Status), req.Reason)
 	if err != nil {
 		log.Printf("UpdateOrderStatus error: %v", err)
 
@@ -91,6 +124,14 @@ func (h *Handler) UpdateOrderStatus(ctx context.Context, req *pb.UpdateOrderStat
 			return nil, status.Error(codes.NotFound, "order not found")
 		}
 
+		if errors.Is(err, ErrInvalidStatusTransition) {
+			return nil, status.Error(codes.FailedPrecondition, err.Error())
+		}
+
+		if err == ErrStatusConflict {
+			return nil, status.Error(codes.Aborted, err.Error())
+		}
+
 		return nil, status.Error(codes.Internal, "failed to update order status")
 	}
 
@@ -99,14 +140,122 @@ func (h *Handler) UpdateOrderStatus(ctx context.Context, req *pb.UpdateOrderStat
 	}, nil
 }
 
+// CancelOrder cancels an order and restores its items' stock
+func (h *Handler) CancelOrder(ctx context.Context, req *pb.CancelOrderRequest) (*pb.CancelOrderResponse, error) {
+	log.Printf("CancelOrder request: %+v", req)
+
+	order, err := h.service.CancelOrder(ctx, req.Id, req.Reason)
+	if err != nil {
+		log.Printf("CancelOrder error: %v", err)
+
+		if err == ErrOrderNotFound {
+			return nil, status.Error(codes.NotFound, "order not found")
+		}
+
+		if errors.Is(err, ErrInvalidStatusTransition) {
+			return nil, status.Error(codes.FailedPrecondition, err.Error())
+		}
+
+		if err == ErrStatusConflict {
+			return nil, status.Error(codes.Aborted, err.Error())
+		}
+
+		return nil, status.Error(codes.Internal, "failed to cancel order")
+	}
+
+	return &pb.CancelOrderResponse{
+		Order: h.orderToProto(order),
+	}, nil
+}
+
+// GetOrderHistory retrieves the status history of an order
+func (h *Handler) GetOrderHistory(ctx context.Context, req *pb.GetOrderHistoryRequest) (*pb.GetOrderHistoryResponse, error) {
+	log.Printf("GetOrderHistory request: %+v", req)
+
+	history, err := h.service.GetOrderHistory(ctx, req.Id)
+	if err != nil {
+		log.Printf("GetOrderHistory error: %v", err)
+
+		if err == ErrOrderNotFound {
+			return nil, status.Error(codes.NotFound, "order not found")
+		}
+
+		return nil, status.Error(codes.Internal, "failed to get order history")
+	}
+
+	return &pb.GetOrderHistoryResponse{
+		Transitions: transitionsToProto(history),
+	}, nil
+}
+
+// BatchGetOrderHistory retrieves the status histories of several orders
+func (h *Handler) BatchGetOrderHistory(ctx context.Context, req *pb.BatchGetOrderHistoryRequest) (*pb.BatchGetOrderHistoryResponse, error) {
+	log.Printf("BatchGetOrderHistory request: %d IDs", len(req.Ids))
+
+	histories, notFound, err := h.service.BatchGetOrderHistory(ctx, req.Ids)
+	if err != nil {
+		log.Printf("BatchGetOrderHistory error: %v", err)
+
+		if validationErr, ok := err.(*ValidationError); ok {
+			return nil, status.Error(codes.InvalidArgument, validationErr.Message)
+		}
+
+		return nil, status.Error(codes.Internal, "failed to get order histories")
+	}
+
+	// Answer in request order, each order at most once
+	protoHistories := make([]*pb.OrderHistory, 0, len(histories))
+	for _, id := range req.Ids {
+		history, found := histories[id]
+		if !found {
+			continue
+		}
+		delete(histories, id)
+		protoHistories = append(protoHistories, &pb.OrderHistory{
+			OrderId:     id,
+			Transitions: transitionsToProto(history),
+		})
+	}
+
+	return &pb.BatchGetOrderHistoryResponse{
+		Histories:   protoHistories,
+		NotFoundIds: notFound,
+	}, nil
+}
+
+// transitionsToProto converts domain status transitions to protobuf ones
+func transitionsToProto(history []*StatusTransition) []*pb.OrderStatusTransition {
+	transitions := make([]*pb.OrderStatusTransition, len(history))
+	for i, transition := range history {
+		transitions[i] = &pb.OrderStatusTransition{
+			FromStatus: pb.OrderStatus(transition.From),
+			ToStatus:   pb.OrderStatus(transition.To),
+			Timestamp:  timestamppb.New(transition.Timestamp),
+			Actor:      transition.Actor,
+			Reason:     transition.Reason,
+		}
+	}
+	return transitions
+}
+
 // ListOrdersByUser retrieves orders for a specific user
 func (h *Handler) ListOrdersByUser(ctx context.Context, req *pb.ListOrdersByUserRequest) (*pb.ListOrdersByUserResponse, error) {
 	log.Printf("ListOrdersByUser request: %+v", req)
 
-	orders, total, err := h.service.ListOrdersByUser(ctx, req.UserId, int(req.Page), int(req.PageSize))
+	query, err := common.NewPageQuery(int(req.Page), int(req.PageSize), req.PageToken)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	query.SkipTotal = req.SkipTotal
+
+	orders, info, err := h.service.ListOrdersByUser(ctx, req.UserId, query)
 	if err != nil {
 		log.Printf("ListOrdersByUser error: %v", err)
 
+		if err == auth.ErrPermissionDenied {
+			return nil, status.Error(codes.PermissionDenied, "orders of other users are not accessible")
+		}
+
 		// Handle validation errors
 		if validationErr, ok := err.(*ValidationError); ok {
 			return nil, status.Error(codes.InvalidArgument, validationErr.Message)
@@ -120,21 +269,99 @@ func (h *Handler) ListOrdersByUser(ctx context.Context, req *pb.ListOrdersByUser
 		protoOrders[i] = h.orderToProto(order)
 	}
 
+	previous, next := pageTokens(orders, info)
 	return &pb.ListOrdersByUserResponse{
-		Orders:   protoOrders,
-		Total:    int32(total),
+		Orders:            protoOrders,
+		Total:             int32(info.Total),
+		Page:              req.Page,
+		PageSize:          req.PageSize,
+		NextPageToken:     next,
+		PreviousPageToken: previous,
+	}, nil
+}
+
+// BatchListOrdersByUser retrieves one page of orders for each of several users
+func (h *Handler) BatchListOrdersByUser(ctx context.Context, req *pb.BatchListOrdersByUserRequest) (*pb.BatchListOrdersByUserResponse, error) {
+	log.Printf("BatchListOrdersByUser request: %d users, status %v, page %d", len(req.UserIds), req.Status, req.Page)
+
+	query, err := common.NewPageQuery(int(req.Page), int(req.PageSize), req.PageToken)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	query.SkipTotal = req.SkipTotal
+
+	results, err := h.service.BatchListOrdersByUser(ctx, req.UserIds, OrderStatus(req.Status), query)
+	if err != nil {
+		log.Printf("BatchListOrdersByUser error: %v", err)
+
+		if err == auth.ErrPermissionDenied {
+			return nil, status.Error(codes.PermissionDenied, "orders of other users are not accessible")
+		}
+
+		if validationErr, ok := err.(*ValidationError); ok {
+			return nil, status.Error(codes.InvalidArgument, validationErr.Message)
+		}
+
+		return nil, status.Error(codes.Internal, "failed to list orders")
+	}
+
+	protoResults := make([]*pb.UserOrders, len(results))
+	for i, result := range results {
+		protoOrders := make([]*pb.Order, len(result.Orders))
+		for j, order := range result.Orders {
+			protoOrders[j] = h.orderToProto(order)
+		}
+		previous, next := pageTokens(result.Orders, result.Page)
+		protoResults[i] = &pb.UserOrders{
+			UserId:            result.UserID,
+			Orders:            protoOrders,
+			Total:             int32(result.Page.Total),
+			NextPageToken:     next,
+			PreviousPageToken: previous,
+		}
+	}
+
+	return &pb.BatchListOrdersByUserResponse{
+		Results:  protoResults,
 		Page:     req.Page,
 		PageSize: req.PageSize,
 	}, nil
 }
 
-// ListOrders retrieves all orders with pagination and optional status filter
+// ListOrders retrieves all orders with pagination and optional filters
 func (h *Handler) ListOrders(ctx context.Context, req *pb.ListOrdersRequest) (*pb.ListOrdersResponse, error) {
 	log.Printf("ListOrders request: %+v", req)
 
-	orders, total, err := h.service.ListOrders(ctx, int(req.Page), int(req.PageSize), OrderStatus(req.Status))
+	query, err := common.NewPageQuery(int(req.Page), int(req.PageSize), req.PageToken)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	query.SkipTotal = req.SkipTotal
+
+	filter := ListFilter{
+		UserID: req.UserId,
+		Status: OrderStatus(req.Status),
+	}
+	if req.CreatedFrom != nil {
+		filter.CreatedFrom = req.CreatedFrom.AsTime()
+	}
+	if req.CreatedTo != nil {
+		filter.CreatedTo = req.CreatedTo.AsTime()
+	}
+
+	orders, info, err := h.service.ListOrders(ctx, query, filter)
 	if err != nil {
 		log.Printf("ListOrders error: %v", err)
+
+		if err == auth.ErrPermissionDenied {
+			return nil, status.Error(codes.PermissionDenied, "orders of other users are not accessible")
+		}
+
+		// Handle validation errors
+		if validationErr, ok := err.(*ValidationError); ok {
+			return nil, status.Error(codes.InvalidArgument, validationErr.Message)
+		}
+
 		return nil, status.Error(codes.Internal, "failed to list orders")
 	}
 
@@ -143,14 +370,63 @@ func (h *Handler) ListOrders(ctx context.Context, req *pb.ListOrdersRequest) (*p
 		protoOrders[i] = h.orderToProto(order)
 	}
 
+	previous, next := pageTokens(orders, info)
 	return &pb.ListOrdersResponse{
-		Orders:   protoOrders,
-		Total:    int32(total),
-		Page:     req.Page,
-		PageSize: req.PageSize,
+		Orders:            protoOrders,
+		Total:             int32(info.Total),
+		Page:              req.Page,
+		PageSize:          req.PageSize,
+		NextPageToken:     next,
+		PreviousPageToken: previous,
 	}, nil
 }
 
+// WatchOrders streams orders as they are created or change status
+func (h *Handler) WatchOrders(req *pb.WatchOrdersRequest, stream pb.OrderService_WatchOrdersServer) error {
+	log.Printf("WatchOrders request: %+v", req)
+
+	subscription, err := h.service.WatchOrders(stream.Context(), req.UserId)
+	if err != nil {
+		log.Printf("WatchOrders error: %v", err)
+
+		if err == auth.ErrPermissionDenied {
+			return status.Error(codes.PermissionDenied, "orders of other users are not accessible")
+		}
+
+		if err == ErrWatchUnavailable {
+			return status.Error(codes.Unimplemented, "order changes can't be watched")
+		}
+
+		return status.Error(codes.Internal, "failed to watch orders")
+	}
+	defer subscription.Close()
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case order, ok := <-subscription.Events():
+			if !ok {
+				if subscription.Dropped() {
+					return status.Error(codes.ResourceExhausted, "watcher fell too far behind")
+				}
+				return status.Error(codes.Unavailable, "service is shutting down")
+			}
+			if err := stream.Send(&pb.WatchOrdersResponse{Order: h.orderToProto(order)}); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// pageTokens returns the page tokens of the pages around a page of orders
+func pageTokens(orders []*Order, info common.PageInfo) (previous, next string) {
+	if len(orders) == 0 {
+		return "", ""
+	}
+	return info.Tokens(orders[0].Cursor(), orders[len(orders)-1].Cursor())
+}
+
 // orderToProto converts domain order to protobuf order
 func (h *Handler) orderToProto(order *Order) *pb.Order {
 	items := make([]*pb.OrderItem, len(order.Items))
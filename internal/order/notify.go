@@ -0,0 +1,72 @@
+package order
+
+import (
+	"context"
+	"errors"
+
+	"learning/internal/common"
+)
+
+// ErrWatchUnavailable is returned when the repository doesn't announce changes
+var ErrWatchUnavailable = errors.New("order changes can't be watched")
+
+// ChangeBufferSize is how many order changes a watcher may fall behind
+// before it is dropped
+const ChangeBufferSize = 256
+
+// OrderNotifier is implemented by repositories that announce order changes
+type OrderNotifier interface {
+	// SubscribeOrders watches the orders of one user, or of every user when
+	// userID is empty
+	SubscribeOrders(userID string) *common.Subscription[*Order]
+}
+
+// NotifyingRepository wraps a repository and announces every order it
+// creates or changes the status of
+type NotifyingRepository struct {
+	Repository
+	changes *common.Broadcaster[*Order]
+}
+
+// NewNotifyingRepository wraps repo so its order changes can be watched;
+// each watcher buffers up to bufferSize changes
+func NewNotifyingRepository(repo Repository, bufferSize int) *NotifyingRepository {
+	return &NotifyingRepository{
+		Repository: repo,
+		changes:    common.NewBroadcaster[*Order](bufferSize),
+	}
+}
+
+// SubscribeOrders watches the orders of one user, or of every user when
+// userID is empty
+func (r *NotifyingRepository) SubscribeOrders(userID string) *common.Subscription[*Order] {
+	if userID == "" {
+		return r.changes.Subscribe(nil)
+	}
+	return r.changes.Subscribe(func(o *Order) bool {
+		return o.UserID == userID
+	})
+}
+
+// Close ends every watch
+func (r *NotifyingRepository) Close() {
+	r.changes.Close()
+}
+
+// Create creates a new order
+func (r *NotifyingRepository) Create(ctx context.Context, order *Order) (*Order, error) {
+	created, err := r.Repository.Create(ctx, order)
+	if err == nil {
+		r.changes.Publish(created)
+	}
+	return created, err
+}
+
+// UpdateStatus moves an order to a new status
+func (r *NotifyingRepository) UpdateStatus(ctx context.Context, id string, transition *StatusTransition) (*Order, error) {
+	updated, err := r.Repository.UpdateStatus(ctx, id, transition)
+	if err == nil {
+		r.changes.Publish(updated)
+	}
+	return updated, err
+}
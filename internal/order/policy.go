@@ -0,0 +1,22 @@
+package order
+
+import (
+	"learning/internal/auth"
+	pb "learning/pkg/order/pb"
+)
+
+// Policy says who may call each RPC of the order service. USERs may only
+// place, see and cancel their own orders; the service enforces that itself.
+var Policy = auth.Policy{
+	pb.OrderService_CreateOrder_FullMethodName:           auth.Require(auth.RoleUser),
+	pb.OrderService_GetOrder_FullMethodName:              auth.Require(auth.RoleUser),
+	pb.OrderService_BatchGetOrders_FullMethodName:        auth.Require(auth.RoleUser),
+	pb.OrderService_UpdateOrderStatus_FullMethodName:     auth.Require(auth.RoleManager),
+	pb.OrderService_CancelOrder_FullMethodName:           auth.Require(auth.RoleUser),
+	pb.OrderService_GetOrderHistory_FullMethodName:       auth.Require(auth.RoleUser),
+	pb.OrderService_BatchGetOrderHistory_FullMethodName:  auth.Require(auth.RoleUser),
+	pb.OrderService_ListOrdersByUser_FullMethodName:      auth.Require(auth.RoleUser),
+	pb.OrderService_BatchListOrdersByUser_FullMethodName: auth.Require(auth.RoleUser),
+	pb.OrderService_ListOrders_FullMethodName:            auth.Require(auth.RoleUser),
+	pb.OrderService_WatchOrders_FullMethodName:           auth.Require(auth.RoleUser),
+}
@@ -7,6 +7,8 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+
+	"learning/internal/common"
 )
 
 var (
@@ -48,25 +50,73 @@ type Order struct {
 	UpdatedAt   time.Time
 }
 
+// Cursor returns the order's position in lists
+func (o *Order) Cursor() common.Cursor {
+	return common.Cursor{CreatedAt: o.CreatedAt, ID: o.ID}
+}
+
+// ListFilter narrows down the orders returned by List; zero fields match
+// every order
+type ListFilter struct {
+	UserID      string
+	Status      OrderStatus
+	CreatedFrom time.Time // earliest creation time, inclusive
+	CreatedTo   time.Time // latest creation time, inclusive
+}
+
+// Matches reports whether an order passes the filter
+func (f ListFilter) Matches(o *Order) bool {
+	if f.UserID != "" && o.UserID != f.UserID {
+		return false
+	}
+	if f.Status != OrderStatusUnspecified && o.Status != f.Status {
+		return false
+	}
+	if !f.CreatedFrom.IsZero() && o.CreatedAt.Before(f.CreatedFrom) {
+		return false
+	}
+	if !f.CreatedTo.IsZero() && o.CreatedAt.After(f.CreatedTo) {
+		return false
+	}
+	return true
+}
+
+// UserOrders is one page of a user's orders and where it sits among all of
+// the user's matching orders
+type UserOrders struct {
+	UserID string
+	Orders []*Order
+	Page   common.PageInfo
+}
+
 // Repository interface for order operations
 type Repository interface {
 	Create(ctx context.Context, order *Order) (*Order, error)
 	GetByID(ctx context.Context, id string) (*Order, error)
-	UpdateStatus(ctx context.Context, id string, status OrderStatus) (*Order, error)
-	ListByUser(ctx context.Context, userID string, offset, limit int) ([]*Order, int, error)
-	List(ctx context.Context, offset, limit int, status OrderStatus) ([]*Order, int, error)
+	GetByIDs(ctx context.Context, ids []string) ([]*Order, []string, error)
+	UpdateStatus(ctx context.Context, id string, transition *StatusTransition) (*Order, error)
+	GetHistory(ctx context.Context, orderID string) ([]*StatusTransition, error)
+	GetHistories(ctx context.Context, orderIDs []string) (map[string][]*StatusTransition, error)
+	ListByUser(ctx context.Context, userID string, query common.PageQuery) ([]*Order, common.PageInfo, error)
+	ListByUsers(ctx context.Context, userIDs []string, status OrderStatus, query common.PageQuery) ([]*UserOrders, error)
+	List(ctx context.Context, query common.PageQuery, filter ListFilter) ([]*Order, common.PageInfo, error)
 }
 
 // InMemoryRepository implements Repository interface using in-memory storage
 type InMemoryRepository struct {
-	orders map[string]*Order
-	mutex  sync.RWMutex
+	orders  map[string]*Order
+	history map[string][]*StatusTransition
+	mutex   sync.RWMutex
+
+	// journal persists changes for file-backed repositories; nil otherwise
+	journal *common.Journal
 }
 
 // NewInMemoryRepository creates a new in-memory repository
 func NewInMemoryRepository() *InMemoryRepository {
 	return &InMemoryRepository{
-		orders: make(map[string]*Order),
+		orders:  make(map[string]*Order),
+		history: make(map[string][]*StatusTransition),
 	}
 }
 
@@ -97,8 +147,16 @@ func (r *InMemoryRepository) Create(ctx context.Context, order *Order) (*Order,
 	order.CreatedAt = now
 	order.UpdatedAt = now
 
+	// Record the initial status as the first history entry
+	history := []*StatusTransition{newOrderTransition(order)}
+
 	// Store order
+	if err := r.persist(journalRecord{Op: opPut, Order: order, History: history}); err != nil {
+		return nil, err
+	}
 	r.orders[order.ID] = order
+	r.history[order.ID] = history
+	r.compact()
 
 	return order, nil
 }
@@ -116,8 +174,35 @@ func (r *InMemoryRepository) GetByID(ctx context.Context, id string) (*Order, er
 	return order, nil
 }
 
-// UpdateStatus updates the status of an order
-func (r *InMemoryRepository) UpdateStatus(ctx context.Context, id string, status OrderStatus) (*Order, error) {
+// GetByIDs retrieves the orders with the given IDs in the order the IDs were
+// requested, each at most once, along with the IDs that matched no order
+func (r *InMemoryRepository) GetByIDs(ctx context.Context, ids []string) ([]*Order, []string, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	found := make([]*Order, 0, len(ids))
+	var notFound []string
+	seen := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		if order, exists := r.orders[id]; exists {
+			found = append(found, order)
+		} else {
+			notFound = append(notFound, id)
+		}
+	}
+
+	return found, notFound, nil
+}
+
+// UpdateStatus moves an order from transition.From to transition.To and
+// appends the transition to its history. It fails with ErrStatusConflict if
+// the order is no longer in transition.From.
+func (r *InMemoryRepository) UpdateStatus(ctx context.Context, id string, transition *StatusTransition) (*Order, error) {
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
 
@@ -126,79 +211,120 @@ func (r *InMemoryRepository) UpdateStatus(ctx context.Context, id string, status
 		return nil, ErrOrderNotFound
 	}
 
+	if order.Status != transition.From {
+		return nil, ErrStatusConflict
+	}
+
 	// Create a copy and update status
 	updatedOrder := *order
-	updatedOrder.Status = status
+	updatedOrder.Status = transition.To
 	updatedOrder.UpdatedAt = time.Now()
+	transition.Timestamp = updatedOrder.UpdatedAt
+
+	// Copy the history so slices already handed out stay unchanged
+	history := make([]*StatusTransition, 0, len(r.history[id])+1)
+	history = append(append(history, r.history[id]...), transition)
 
 	// Store updated order
+	if err := r.persist(journalRecord{Op: opPut, Order: &updatedOrder, History: history}); err != nil {
+		return nil, err
+	}
 	r.orders[id] = &updatedOrder
+	r.history[id] = history
+	r.compact()
 
 	return &updatedOrder, nil
 }
 
-// ListByUser retrieves orders for a specific user with pagination
-func (r *InMemoryRepository) ListByUser(ctx context.Context, userID string, offset, limit int) ([]*Order, int, error) {
+// GetHistory retrieves the status transitions of an order, oldest first
+func (r *InMemoryRepository) GetHistory(ctx context.Context, orderID string) ([]*StatusTransition, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	if _, exists := r.orders[orderID]; !exists {
+		return nil, ErrOrderNotFound
+	}
+
+	return r.history[orderID], nil
+}
+
+// GetHistories retrieves the status transitions of several orders, oldest
+// first, keyed by order ID; IDs that match no order are left out
+func (r *InMemoryRepository) GetHistories(ctx context.Context, orderIDs []string) (map[string][]*StatusTransition, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	histories := make(map[string][]*StatusTransition, len(orderIDs))
+	for _, id := range orderIDs {
+		if _, exists := r.orders[id]; exists {
+			histories[id] = r.history[id]
+		}
+	}
+	return histories, nil
+}
+
+// ListByUser retrieves orders for a specific user with pagination, oldest first
+func (r *InMemoryRepository) ListByUser(ctx context.Context, userID string, query common.PageQuery) ([]*Order, common.PageInfo, error) {
 	r.mutex.RLock()
 	defer r.mutex.RUnlock()
 
 	// Filter orders by user ID
-	var userOrders []*Order
+	userOrders := []*Order{}
 	for _, order := range r.orders {
 		if order.UserID == userID {
 			userOrders = append(userOrders, order)
 		}
 	}
 
-	total := len(userOrders)
+	page, info := common.Paginate(userOrders, (*Order).Cursor, query)
+	return page, info, nil
+}
+
+// ListByUsers retrieves one page of orders for each of several users, oldest
+// first, with an optional status filter. Results are in request order with
+// one entry per distinct user ID.
+func (r *InMemoryRepository) ListByUsers(ctx context.Context, userIDs []string, status OrderStatus, query common.PageQuery) ([]*UserOrders, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
 
-	// Apply pagination
-	start := offset
-	if start > total {
-		start = total
+	results := make([]*UserOrders, 0, len(userIDs))
+	byUser := make(map[string]*UserOrders, len(userIDs))
+	for _, userID := range userIDs {
+		if _, seen := byUser[userID]; seen {
+			continue
+		}
+		result := &UserOrders{UserID: userID, Orders: []*Order{}}
+		byUser[userID] = result
+		results = append(results, result)
 	}
 
-	end := start + limit
-	if end > total {
-		end = total
+	for _, order := range r.orders {
+		result, wanted := byUser[order.UserID]
+		if wanted && (status == OrderStatusUnspecified || order.Status == status) {
+			result.Orders = append(result.Orders, order)
+		}
 	}
 
-	if start >= total {
-		return []*Order{}, total, nil
+	for _, result := range results {
+		result.Orders, result.Page = common.Paginate(result.Orders, (*Order).Cursor, query)
 	}
 
-	return userOrders[start:end], total, nil
+	return results, nil
 }
 
-// List retrieves orders with pagination and optional status filter
-func (r *InMemoryRepository) List(ctx context.Context, offset, limit int, status OrderStatus) ([]*Order, int, error) {
+// List retrieves orders with pagination and optional filters, oldest first
+func (r *InMemoryRepository) List(ctx context.Context, query common.PageQuery, filter ListFilter) ([]*Order, common.PageInfo, error) {
 	r.mutex.RLock()
 	defer r.mutex.RUnlock()
 
-	// Convert map to slice and filter by status if specified
-	var orders []*Order
+	// Convert map to slice and apply the filter
+	orders := []*Order{}
 	for _, order := range r.orders {
-		if status == OrderStatusUnspecified || order.Status == status {
+		if filter.Matches(order) {
 			orders = append(orders, order)
 		}
 	}
 
-	total := len(orders)
-
-	// Apply pagination
-	start := offset
-	if start > total {
-		start = total
-	}
-
-	end := start + limit
-	if end > total {
-		end = total
-	}
-
-	if start >= total {
-		return []*Order{}, total, nil
-	}
-
-	return orders[start:end], total, nil
+	page, info := common.Paginate(orders, (*Order).Cursor, query)
+	return page, info, nil
 }
@@ -0,0 +1,595 @@
+package order
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	productpb "learning/pkg/product/pb"
+)
+
+// SagaState is the overall progress of an order saga
+type SagaState string
+
+const (
+	// SagaReserving takes stock for the order's items one by one
+	SagaReserving SagaState = "RESERVING"
+	// SagaPersisting stores the order once every item is reserved
+	SagaPersisting SagaState = "PERSISTING"
+	// SagaCommitting makes the reservations of a stored order permanent
+	SagaCommitting SagaState = "COMMITTING"
+	// SagaCompensating releases the reservations of a failed saga
+	SagaCompensating SagaState = "COMPENSATING"
+	// SagaVoiding cancels a stored order whose stock couldn't be taken
+	SagaVoiding SagaState = "VOIDING"
+	// SagaCancelling is recorded before an order is cancelled
+	SagaCancelling SagaState = "CANCELLING"
+	// SagaRestoring puts the stock of a cancelled order back
+	SagaRestoring SagaState = "RESTORING"
+)
+
+// SagaStepStatus is the progress of a single item's stock reservation
+type SagaStepStatus string
+
+const (
+	StepPending   SagaStepStatus = "PENDING"
+	StepReserving SagaStepStatus = "RESERVING" // call to product service in flight
+	StepReserved  SagaStepStatus = "RESERVED"
+	StepCommitted SagaStepStatus = "COMMITTED"
+	StepReleased  SagaStepStatus = "RELEASED"
+	StepFailed    SagaStepStatus = "FAILED" // stock gone for good, never taken
+)
+
+const (
+	// compensationTimeout bounds rolling back a saga after its request ended
+	compensationTimeout = 30 * time.Second
+	// reservationTTL bounds how long a saga lost in a crash holds stock
+	reservationTTL = 15 * time.Minute
+	// sagaRetryDelay is how long an unfinished saga waits before it is
+	// driven again, doubling after each failed attempt up to
+	// maxSagaRetryDelay
+	sagaRetryDelay    = time.Second
+	maxSagaRetryDelay = time.Minute
+	// maxSagaRetries is how often a saga is retried before it is left for
+	// the next restart
+	maxSagaRetries = 20
+)
+
+// SagaStep tracks the stock reservation of one order item
+type SagaStep struct {
+	ReservationID string
+	ProductID     string
+	Quantity      int32
+	Status        SagaStepStatus
+}
+
+// Saga is the persisted state of an in-flight CreateOrder or CancelOrder.
+// When creating, stock for every item is reserved first; the order is only
+// stored once all reservations succeeded, after which they are committed. A
+// failure before the order is stored releases what was already reserved; if
+// stock can't be taken after that, the order is voided (cancelled) and what
+// was taken is put back.
+// When cancelling, the saga is recorded before the order is cancelled and
+// puts its items back in stock afterwards.
+// Finished sagas are deleted from the store, so everything left in it after
+// a crash needs recovering.
+type Saga struct {
+	ID        string
+	State     SagaState
+	Order     *Order
+	Steps     []*SagaStep
+	Failure   string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// SagaStore persists order sagas between steps
+type SagaStore interface {
+	Save(ctx context.Context, saga *Saga) error
+	Delete(ctx context.Context, id string) error
+	List(ctx context.Context) ([]*Saga, error)
+}
+
+// newOrderSaga starts a saga that creates the given order
+func newOrderSaga(order *Order) *Saga {
+	steps := make([]*SagaStep, len(order.Items))
+	for i, item := range order.Items {
+		steps[i] = &SagaStep{
+			// Deterministic, so a retried or recovered call can't reserve twice
+			ReservationID: fmt.Sprintf("%s-%d", order.ID, i+1),
+			ProductID:     item.ProductID,
+			Quantity:      item.Quantity,
+			Status:        StepPending,
+		}
+	}
+
+	now := time.Now()
+	return &Saga{
+		ID:        order.ID,
+		State:     SagaReserving,
+		Order:     order,
+		Steps:     steps,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+// newCancelSaga starts a saga that cancels the given order
+func newCancelSaga(order *Order) *Saga {
+	now := time.Now()
+	return &Saga{
+		// One per order, so a retried cancel replaces an interrupted one
+		// instead of restoring the stock a second time
+		ID:        uuid.NewSHA1(uuid.NameSpaceOID, []byte("cancel:"+order.ID)).String(),
+		State:     SagaCancelling,
+		Order:     order,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+// clone returns a deep copy of the saga's mutable state
+func (s *Saga) clone() *Saga {
+	copied := *s
+	copied.Steps = make([]*SagaStep, len(s.Steps))
+	for i, step := range s.Steps {
+		stepCopy := *step
+		copied.Steps[i] = &stepCopy
+	}
+	return &copied
+}
+
+// runSaga drives a saga from its current state until the order is stored or
+// every reservation is released. It returns the stored order, or the error
+// that made the saga fail. A saga that can't be finished, because a call or
+// saving its state failed, is left in its last saved state and driven again
+// in the background.
+func (s *Service) runSaga(ctx context.Context, saga *Saga) (*Order, error) {
+	order, cause, unfinished := s.driveSaga(ctx, saga)
+	if unfinished != nil {
+		log.Printf("Saga %s: unfinished in state %s, retrying in the background: %v", saga.ID, saga.State, unfinished)
+		go s.retrySaga(saga)
+	}
+	return order, cause
+}
+
+// driveSaga takes a saga as far as it can. Besides the outcome of the
+// order, it returns the error that kept the saga from finishing, if any.
+func (s *Service) driveSaga(ctx context.Context, saga *Saga) (order *Order, cause, unfinished error) {
+	if saga.State == SagaCancelling || saga.State == SagaVoiding || saga.State == SagaRestoring {
+		unfinished = s.driveCancelSaga(ctx, saga)
+		if saga.Failure != "" {
+			// A voided order failed even though it was stored
+			return nil, errors.New(saga.Failure), unfinished
+		}
+		return saga.Order, nil, unfinished
+	}
+
+	if saga.State == SagaReserving {
+		cause = s.reserveStock(ctx, saga)
+		if cause == nil {
+			cause = s.advanceSaga(ctx, saga, SagaPersisting, nil)
+		}
+		if cause != nil {
+			if err := s.advanceSaga(ctx, saga, SagaCompensating, cause); err != nil {
+				return nil, cause, err
+			}
+		}
+	}
+
+	if saga.State == SagaPersisting {
+		stored, err := s.persistOrder(ctx, saga)
+		if err != nil {
+			cause = err
+			if err := s.advanceSaga(ctx, saga, SagaCompensating, cause); err != nil {
+				return nil, cause, err
+			}
+		} else {
+			// The order exists now, so from here on the saga only moves forward
+			order = stored
+			if err := s.advanceSaga(ctx, saga, SagaCommitting, nil); err != nil {
+				return order, nil, err
+			}
+		}
+	}
+
+	if saga.State == SagaCommitting {
+		if err := s.commitStock(ctx, saga); err != nil {
+			unfinished = err
+		} else if saga.State == SagaVoiding {
+			return s.driveSaga(ctx, saga)
+		} else {
+			s.finishSaga(ctx, saga)
+		}
+		if order == nil {
+			order, cause = s.repo.GetByID(ctx, saga.ID)
+		}
+		return order, cause, unfinished
+	}
+
+	if saga.State == SagaCompensating {
+		// Roll back even if the caller has gone away
+		compensateCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), compensationTimeout)
+		defer cancel()
+
+		if err := s.compensate(compensateCtx, saga); err != nil {
+			unfinished = err
+		} else {
+			s.finishSaga(compensateCtx, saga)
+		}
+	}
+
+	if cause == nil {
+		cause = errors.New(saga.Failure)
+	}
+	return nil, cause, unfinished
+}
+
+// driveCancelSaga puts the stock of the order a cancel saga cancelled back.
+// A saga still CANCELLING may have been cut short before the order was
+// cancelled, so it first checks that the order was. A VOIDING saga cancels
+// its order itself.
+func (s *Service) driveCancelSaga(ctx context.Context, saga *Saga) error {
+	if saga.State == SagaVoiding {
+		voided, err := s.voidOrder(ctx, saga)
+		if err != nil {
+			return err
+		}
+		if !voided {
+			s.finishSaga(ctx, saga)
+			return nil
+		}
+		if err := s.advanceSaga(ctx, saga, SagaRestoring, nil); err != nil {
+			return err
+		}
+	}
+
+	if saga.State == SagaCancelling {
+		cancelled, err := s.cancelledSince(ctx, saga.Order.ID, saga.CreatedAt)
+		if err != nil {
+			return err
+		}
+		if !cancelled {
+			s.finishSaga(ctx, saga)
+			return nil
+		}
+		if err := s.advanceSaga(ctx, saga, SagaRestoring, nil); err != nil {
+			return err
+		}
+	}
+
+	if err := s.restoreStock(ctx, saga); err != nil {
+		return err
+	}
+	s.finishSaga(ctx, saga)
+	return nil
+}
+
+// voidOrder cancels the order of a VOIDING saga on behalf of the order
+// service. It reports whether the saga cancelled it, now or on an earlier
+// attempt; an order cancelled by someone else gets its stock back from their
+// cancel saga, and one past cancelling is left to be corrected by hand.
+func (s *Service) voidOrder(ctx context.Context, saga *Saga) (bool, error) {
+	_, changed, err := s.changeStatusAs(ctx, saga.ID, OrderStatusCancelled, ServiceName, saga.Failure)
+	if errors.Is(err, ErrInvalidStatusTransition) {
+		log.Printf("Saga %s: order can't be voided, correct it by hand: %v", saga.ID, err)
+		return false, nil
+	}
+	if err != nil || changed {
+		return changed, err
+	}
+
+	history, err := s.repo.GetHistory(ctx, saga.ID)
+	if err != nil {
+		return false, err
+	}
+	for _, transition := range history {
+		if transition.To == OrderStatusCancelled {
+			return transition.Actor == ServiceName && transition.Reason == saga.Failure, nil
+		}
+	}
+	return false, nil
+}
+
+// cancelledSince checks if an order was cancelled at or after the given time
+func (s *Service) cancelledSince(ctx context.Context, orderID string, since time.Time) (bool, error) {
+	history, err := s.repo.GetHistory(ctx, orderID)
+	if err == ErrOrderNotFound {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	for _, transition := range history {
+		if transition.To == OrderStatusCancelled {
+			return !transition.Timestamp.Before(since), nil
+		}
+	}
+	return false, nil
+}
+
+// retrySaga drives a saga its request couldn't finish until it is finished,
+// waiting longer after each failed attempt. Sagas still unfinished after
+// maxSagaRetries attempts, or when the service stops, are recovered on
+// restart.
+func (s *Service) retrySaga(saga *Saga) {
+	delay := sagaRetryDelay
+	for attempt := 1; attempt <= maxSagaRetries; attempt++ {
+		time.Sleep(delay)
+
+		ctx, cancel := context.WithTimeout(context.Background(), compensationTimeout)
+		_, _, unfinished := s.resumeSaga(ctx, saga, "abandoned after its request failed")
+		cancel()
+		if unfinished == nil {
+			log.Printf("Saga %s: finished after %d retries", saga.ID, attempt)
+			return
+		}
+
+		log.Printf("Saga %s: retry %d failed in state %s: %v", saga.ID, attempt, saga.State, unfinished)
+		delay = min(2*delay, maxSagaRetryDelay)
+	}
+	log.Printf("Saga %s: giving up after %d retries, left in state %s until restart", saga.ID, maxSagaRetries, saga.State)
+}
+
+// resumeSaga drives a saga whose request is gone. A saga whose order isn't
+// stored yet is rolled back, with failure as its reason, rather than
+// creating an order its caller was told failed or never heard of.
+func (s *Service) resumeSaga(ctx context.Context, saga *Saga, failure string) (*Order, error, error) {
+	if saga.State == SagaPersisting {
+		// The order may have been stored just before the request ended
+		_, err := s.repo.GetByID(ctx, saga.ID)
+		if err == nil {
+			return s.driveSaga(ctx, saga)
+		}
+		if err != ErrOrderNotFound {
+			return nil, nil, err
+		}
+	}
+	if saga.State == SagaReserving || saga.State == SagaPersisting {
+		saga.State = SagaCompensating
+		saga.Failure = failure
+	}
+	return s.driveSaga(ctx, saga)
+}
+
+// reserveStock reserves stock for every item not reserved yet
+func (s *Service) reserveStock(ctx context.Context, saga *Saga) error {
+	for i, step := range saga.Steps {
+		if step.Status != StepPending {
+			continue
+		}
+
+		step.Status = StepReserving
+		if err := s.saveSaga(ctx, saga); err != nil {
+			step.Status = StepPending
+			return err
+		}
+
+		err := s.productClient.ReserveStock(ctx, step.ReservationID, step.ProductID, step.Quantity, reservationTTL)
+		if err != nil {
+			log.Printf("Saga %s: failed to reserve product %s: %v", saga.ID, step.ProductID, err)
+			// Rejected calls reserved nothing; anything else (e.g. a timeout)
+			// may have gone through and stays RESERVING
+			if code := status.Code(err); code == codes.InvalidArgument || code == codes.NotFound || code == codes.AlreadyExists {
+				step.Status = StepPending
+			}
+			return reservationError(saga.Order.Items[i], err)
+		}
+
+		step.Status = StepReserved
+		if err := s.saveSaga(ctx, saga); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// persistOrder stores the saga's order. An order already stored by an
+// earlier attempt counts as success.
+func (s *Service) persistOrder(ctx context.Context, saga *Saga) (*Order, error) {
+	order, err := s.repo.Create(ctx, saga.Order)
+	if err == ErrOrderAlreadyExists {
+		return s.repo.GetByID(ctx, saga.ID)
+	}
+	if err != nil {
+		// The write may have landed even though it reported an error
+		if stored, getErr := s.repo.GetByID(ctx, saga.ID); getErr == nil {
+			return stored, nil
+		}
+		return nil, fmt.Errorf("failed to create order: %w", err)
+	}
+	return order, nil
+}
+
+// commitStock commits the reservation of every item not committed yet.
+// Stock whose reservation is gone is taken directly; if that is refused too
+// the saga moves on to voiding its order.
+func (s *Service) commitStock(ctx context.Context, saga *Saga) error {
+	var lost []*SagaStep
+	for _, step := range saga.Steps {
+		if step.Status != StepReserved {
+			continue
+		}
+
+		err := s.productClient.CommitReservation(ctx, step.ReservationID)
+		if code := status.Code(err); code == codes.FailedPrecondition || code == codes.NotFound {
+			// The reservation expired before it could be committed (e.g. the
+			// service was down too long), or went with its product
+			lost = append(lost, step)
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to commit reservation %s: %w", step.ReservationID, err)
+		}
+
+		step.Status = StepCommitted
+		if err := s.saveSaga(ctx, saga); err != nil {
+			return err
+		}
+	}
+
+	if len(lost) == 0 {
+		return nil
+	}
+	if err := s.takeStock(ctx, saga, lost); err != nil {
+		return err
+	}
+
+	for _, step := range saga.Steps {
+		if step.Status == StepFailed {
+			return s.advanceSaga(ctx, saga, SagaVoiding,
+				fmt.Errorf("order %s voided: stock of product %s could not be taken", saga.ID, step.ProductID))
+		}
+	}
+	return s.saveSaga(ctx, saga)
+}
+
+// takeStock takes the stock of steps without a reservation directly, in one
+// batch. Steps the product service rejects (product deleted or out of stock)
+// are marked FAILED and the rest are tried again, since a rejected batch
+// changes nothing.
+func (s *Service) takeStock(ctx context.Context, saga *Saga, steps []*SagaStep) error {
+	for len(steps) > 0 {
+		changes := make([]*productpb.StockChange, len(steps))
+		for i, step := range steps {
+			log.Printf("Saga %s: reservation %s is gone, taking stock of product %s directly",
+				saga.ID, step.ReservationID, step.ProductID)
+			changes[i] = &productpb.StockChange{
+				ProductId: step.ProductID,
+				Quantity:  -step.Quantity,
+			}
+		}
+
+		err := s.productClient.BatchUpdateStock(ctx, changes)
+		var rejected *StockUpdateError
+		if !errors.As(err, &rejected) {
+			if err != nil {
+				return fmt.Errorf("failed to take stock of order %s: %w", saga.ID, err)
+			}
+			for _, step := range steps {
+				step.Status = StepCommitted
+			}
+			return nil
+		}
+
+		var remaining []*SagaStep
+		for i, step := range steps {
+			if !rejected.Rejected(i) {
+				remaining = append(remaining, step)
+				continue
+			}
+			log.Printf("Saga %s: stock of product %s can't be taken: %v", saga.ID, step.ProductID, err)
+			step.Status = StepFailed
+		}
+		steps = remaining
+	}
+	return nil
+}
+
+// compensate releases the reservation of every item that may hold one.
+// Reservation IDs are deterministic, so even a reservation whose outcome is
+// unknown (crash or timeout mid-call) can be released safely.
+func (s *Service) compensate(ctx context.Context, saga *Saga) error {
+	for _, step := range saga.Steps {
+		if step.Status != StepReserved && step.Status != StepReserving {
+			continue
+		}
+
+		err := s.productClient.ReleaseReservation(ctx, step.ReservationID)
+		switch status.Code(err) {
+		case codes.OK:
+		case codes.NotFound:
+			// The reservation never happened
+		case codes.FailedPrecondition:
+			// Already expired or released
+		default:
+			return fmt.Errorf("failed to release reservation %s: %w", step.ReservationID, err)
+		}
+
+		step.Status = StepReleased
+		if err := s.saveSaga(ctx, saga); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// advanceSaga moves a saga to a new state and persists it. If the state
+// can't be saved the saga is left in its previous state, so it never runs
+// ahead of what recovery would find.
+func (s *Service) advanceSaga(ctx context.Context, saga *Saga, state SagaState, cause error) error {
+	previousState, previousFailure := saga.State, saga.Failure
+	saga.State = state
+	if cause != nil {
+		saga.Failure = cause.Error()
+	}
+	if err := s.saveSaga(ctx, saga); err != nil {
+		saga.State, saga.Failure = previousState, previousFailure
+		return err
+	}
+	return nil
+}
+
+// saveSaga persists the current saga state
+func (s *Service) saveSaga(ctx context.Context, saga *Saga) error {
+	saga.UpdatedAt = time.Now()
+	if err := s.sagas.Save(ctx, saga); err != nil {
+		log.Printf("Saga %s: failed to save state: %v", saga.ID, err)
+		return fmt.Errorf("failed to save order saga: %w", err)
+	}
+	return nil
+}
+
+// finishSaga removes a saga that reached its end
+func (s *Service) finishSaga(ctx context.Context, saga *Saga) {
+	if err := s.sagas.Delete(ctx, saga.ID); err != nil {
+		// A leftover finished saga is harmless: recovery completes it again
+		log.Printf("Saga %s: failed to delete finished saga: %v", saga.ID, err)
+	}
+}
+
+// RecoverSagas finishes the sagas a previous run left in flight. Sagas whose
+// order was stored are resumed and their reservations committed; the others
+// are rolled back. Cancelled orders get their stock back. It must run before
+// the service accepts requests.
+func (s *Service) RecoverSagas(ctx context.Context) error {
+	sagas, err := s.sagas.List(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list order sagas: %w", err)
+	}
+
+	for _, saga := range sagas {
+		log.Printf("Recovering saga %s in state %s", saga.ID, saga.State)
+		order, err, unfinished := s.resumeSaga(ctx, saga, "interrupted by order service restart")
+		switch {
+		case unfinished != nil:
+			log.Printf("Saga %s: unfinished in state %s, retrying in the background: %v", saga.ID, saga.State, unfinished)
+			go s.retrySaga(saga)
+		case err == nil:
+			log.Printf("Saga %s: resumed, order %s is %s", saga.ID, order.ID, order.Status)
+		default:
+			log.Printf("Saga %s: rolled back: %v", saga.ID, err)
+		}
+	}
+
+	return nil
+}
+
+// reservationError turns a failed stock reservation into the error CreateOrder returns
+func reservationError(item *OrderItem, err error) error {
+	switch status.Code(err) {
+	case codes.InvalidArgument:
+		return NewValidationError(fmt.Sprintf("insufficient stock for product %s", item.ProductName))
+	case codes.NotFound:
+		return NewValidationError(fmt.Sprintf("product %s not found", item.ProductID))
+	default:
+		return fmt.Errorf("failed to reserve stock: %w", err)
+	}
+}
@@ -0,0 +1,157 @@
+package order
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+
+	"learning/internal/common"
+)
+
+// InMemorySagaStore implements SagaStore using in-memory storage
+type InMemorySagaStore struct {
+	sagas map[string]*Saga
+	mutex sync.Mutex
+
+	// journal persists changes for file-backed stores; nil otherwise
+	journal *common.Journal
+}
+
+// sagaRecord is a single logged change to the saga set.
+// Records carry the whole saga so replaying one twice is harmless.
+type sagaRecord struct {
+	Op   string `json:"op"`
+	Saga *Saga  `json:"saga,omitempty"`
+	ID   string `json:"id,omitempty"`
+}
+
+const opDelete = "delete"
+
+// NewInMemorySagaStore creates a new in-memory saga store
+func NewInMemorySagaStore() *InMemorySagaStore {
+	return &InMemorySagaStore{
+		sagas: make(map[string]*Saga),
+	}
+}
+
+// NewFileBackedSagaStore creates an in-memory saga store whose contents are
+// persisted to a snapshot and append-only log inside dataDir
+func NewFileBackedSagaStore(dataDir string) (*InMemorySagaStore, error) {
+	journal, err := common.OpenJournal(dataDir, "sagas", common.DefaultCompactEvery)
+	if err != nil {
+		return nil, err
+	}
+
+	store := NewInMemorySagaStore()
+	err = journal.Replay(
+		func(data []byte) error {
+			return json.Unmarshal(data, &store.sagas)
+		},
+		func(data []byte) error {
+			var record sagaRecord
+			if err := json.Unmarshal(data, &record); err != nil {
+				return err
+			}
+			switch record.Op {
+			case opPut:
+				if record.Saga == nil {
+					return fmt.Errorf("put record without saga")
+				}
+				store.sagas[record.Saga.ID] = record.Saga
+			case opDelete:
+				delete(store.sagas, record.ID)
+			default:
+				return fmt.Errorf("unknown journal op %q", record.Op)
+			}
+			return nil
+		},
+	)
+	if err != nil {
+		journal.Close()
+		return nil, err
+	}
+
+	store.journal = journal
+	return store, nil
+}
+
+// Save stores a copy of the saga's current state
+func (s *InMemorySagaStore) Save(ctx context.Context, saga *Saga) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	saved := saga.clone()
+	if err := s.persist(sagaRecord{Op: opPut, Saga: saved}); err != nil {
+		return err
+	}
+	s.sagas[saga.ID] = saved
+	s.compact()
+
+	return nil
+}
+
+// Delete removes a saga; deleting an unknown saga is not an error
+func (s *InMemorySagaStore) Delete(ctx context.Context, id string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, exists := s.sagas[id]; !exists {
+		return nil
+	}
+
+	if err := s.persist(sagaRecord{Op: opDelete, ID: id}); err != nil {
+		return err
+	}
+	delete(s.sagas, id)
+	s.compact()
+
+	return nil
+}
+
+// List returns copies of all stored sagas
+func (s *InMemorySagaStore) List(ctx context.Context) ([]*Saga, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	sagas := make([]*Saga, 0, len(s.sagas))
+	for _, saga := range s.sagas {
+		sagas = append(sagas, saga.clone())
+	}
+	return sagas, nil
+}
+
+// Close closes the journal of a file-backed store
+func (s *InMemorySagaStore) Close() error {
+	if s.journal == nil {
+		return nil
+	}
+	return s.journal.Close()
+}
+
+// persist logs a change before it is applied to the map.
+// The caller must hold the lock.
+func (s *InMemorySagaStore) persist(record sagaRecord) error {
+	if s.journal == nil {
+		return nil
+	}
+
+	if err := s.journal.Append(record); err != nil {
+		return fmt.Errorf("failed to persist saga: %w", err)
+	}
+	return nil
+}
+
+// compact writes a fresh snapshot once the log is long enough.
+// The caller must hold the lock and have applied the latest change.
+func (s *InMemorySagaStore) compact() {
+	if s.journal == nil || !s.journal.NeedsCompaction() {
+		return
+	}
+
+	if err := s.journal.Compact(s.sagas); err != nil {
+		// The log still holds every change, so this only delays compaction
+		log.Printf("Failed to compact saga journal: %v", err)
+	}
+}
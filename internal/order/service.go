@@ -2,13 +2,33 @@ package order
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"sync"
 
+	"github.com/google/uuid"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+
+	"learning/internal/auth"
+	"learning/internal/common"
+	productpb "learning/pkg/product/pb"
 )
 
+// ServiceName is the name the order service signs its own calls with, and
+// records status changes made without a caller identity as made by
+const ServiceName = "order-service"
+
+// maxStatusUpdateAttempts bounds retries of a status update that lost a race
+const maxStatusUpdateAttempts = 3
+
+// MaxBatchGetIDs caps the number of orders fetched by one batch lookup
+const MaxBatchGetIDs = 1000
+
+// MaxBatchListUsers caps the number of users whose orders one batch lookup lists
+const MaxBatchListUsers = 100
+
 // OrderItemRequest represents a request to add an item to an order
 type OrderItemRequest struct {
 	ProductID string
@@ -18,20 +38,64 @@ type OrderItemRequest struct {
 // Service handles business logic for order operations
 type Service struct {
 	repo          Repository
+	sagas         SagaStore
 	userClient    *UserServiceClient
 	productClient *ProductServiceClient
+	cancelling    orderLocks
+}
+
+// orderLocks serializes work on the same order within the service
+type orderLocks struct {
+	mutex sync.Mutex
+	locks map[string]*orderLock
+}
+
+type orderLock struct {
+	sync.Mutex
+	holders int
+}
+
+// lock blocks until no one else holds the lock of the order and returns
+// the function that releases it
+func (l *orderLocks) lock(orderID string) func() {
+	l.mutex.Lock()
+	if l.locks == nil {
+		l.locks = make(map[string]*orderLock)
+	}
+	lock, ok := l.locks[orderID]
+	if !ok {
+		lock = &orderLock{}
+		l.locks[orderID] = lock
+	}
+	lock.holders++
+	l.mutex.Unlock()
+
+	lock.Lock()
+	return func() {
+		lock.Unlock()
+		l.mutex.Lock()
+		lock.holders--
+		if lock.holders == 0 {
+			delete(l.locks, orderID)
+		}
+		l.mutex.Unlock()
+	}
 }
 
 // NewService creates a new order service
-func NewService(repo Repository, userClient *UserServiceClient, productClient *ProductServiceClient) *Service {
+func NewService(repo Repository, sagas SagaStore, userClient *UserServiceClient, productClient *ProductServiceClient) *Service {
 	return &Service{
 		repo:          repo,
+		sagas:         sagas,
 		userClient:    userClient,
 		productClient: productClient,
 	}
 }
 
-// CreateOrder creates a new order with validation and stock management
+// CreateOrder creates a new order with validation and stock management.
+// It runs as a saga: stock is reserved for every item first, reservations
+// are released if a later step fails, and the order is only stored, already
+// CONFIRMED, once all of them succeeded. The reservations are then committed.
 func (s *Service) CreateOrder(ctx context.Context, userID string, items []*OrderItemRequest) (*Order, error) {
 	log.Printf("Creating order for user %s with %d items", userID, len(items))
 
@@ -39,6 +103,9 @@ func (s *Service) CreateOrder(ctx context.Context, userID string, items []*Order
 	if userID == "" {
 		return nil, NewValidationError("user ID is required")
 	}
+	if !auth.CanActFor(ctx, userID, auth.RoleManager) {
+		return nil, auth.ErrPermissionDenied
+	}
 
 	if len(items) == 0 {
 		return nil, NewValidationError("at least one item is required")
@@ -54,31 +121,42 @@ func (s *Service) CreateOrder(ctx context.Context, userID string, items []*Order
 		return nil, fmt.Errorf("failed to verify user: %w", err)
 	}
 
-	// Process order items
-	var orderItems []*OrderItem
-	var totalAmount float64
-
-	for _, itemReq := range items {
-		// Validate item
+	// Validate items
+	productIDs := make([]string, len(items))
+	for i, itemReq := range items {
 		if itemReq.ProductID == "" {
 			return nil, NewValidationError("product ID is required for all items")
 		}
 		if itemReq.Quantity <= 0 {
 			return nil, NewValidationError("quantity must be greater than 0")
 		}
+		productIDs[i] = itemReq.ProductID
+	}
 
-		// Get product details
-		product, err := s.productClient.GetProduct(ctx, itemReq.ProductID)
-		if err != nil {
-			log.Printf("Failed to get product %s: %v", itemReq.ProductID, err)
-			if status.Code(err) == codes.NotFound {
-				return nil, NewValidationError(fmt.Sprintf("product %s not found", itemReq.ProductID))
-			}
-			return nil, fmt.Errorf("failed to get product: %w", err)
-		}
+	// Get product details for all items in one call
+	products, notFound, err := s.productClient.BatchGetProducts(ctx, productIDs)
+	if err != nil {
+		log.Printf("Failed to get products: %v", err)
+		return nil, fmt.Errorf("failed to get products: %w", err)
+	}
+	if len(notFound) > 0 {
+		return nil, NewValidationError(fmt.Sprintf("product %s not found", notFound[0]))
+	}
+
+	productsByID := make(map[string]*productpb.Product, len(products))
+	for _, product := range products {
+		productsByID[product.Id] = product
+	}
+
+	// Process order items
+	var orderItems []*OrderItem
+	var totalAmount float64
+
+	for _, itemReq := range items {
+		product := productsByID[itemReq.ProductID]
 
-		// Check stock availability
-		if product.Stock < itemReq.Quantity {
+		// Check stock availability; stock held by other reservations doesn't count
+		if product.AvailableStock < itemReq.Quantity {
 			return nil, NewValidationError(fmt.Sprintf("insufficient stock for product %s", product.Name))
 		}
 
@@ -98,96 +176,332 @@ func (s *Service) CreateOrder(ctx context.Context, userID string, items []*Order
 		orderItems = append(orderItems, orderItem)
 	}
 
-	// Create order
+	// Create order; the ID is fixed up front so the saga and order share it
 	order := &Order{
+		ID:          uuid.New().String(),
 		UserID:      userID,
 		Items:       orderItems,
 		TotalAmount: totalAmount,
-		Status:      OrderStatusPending,
+		Status:      OrderStatusConfirmed,
 	}
 
-	// Save order
-	savedOrder, err := s.repo.Create(ctx, order)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create order: %w", err)
+	// Record the saga before touching any stock
+	saga := newOrderSaga(order)
+	if err := s.saveSaga(ctx, saga); err != nil {
+		return nil, err
 	}
 
-	// Update stock for all items (reduce stock)
-	for _, item := range orderItems {
-		err := s.productClient.UpdateStock(ctx, item.ProductID, -item.Quantity)
-		if err != nil {
-			log.Printf("Failed to update stock for product %s: %v", item.ProductID, err)
-			// In a real system, we would need compensation logic here
-			// For simplicity, we'll just log the error
-		}
+	savedOrder, err := s.runSaga(ctx, saga)
+	if err != nil {
+		return nil, err
 	}
 
 	log.Printf("Order %s created successfully", savedOrder.ID)
 	return savedOrder, nil
 }
 
-// GetOrder retrieves an order by ID
+// GetOrder retrieves an order by ID. Callers below MANAGER only find their
+// own orders.
 func (s *Service) GetOrder(ctx context.Context, id string) (*Order, error) {
 	if id == "" {
 		return nil, ErrOrderNotFound
 	}
-	return s.repo.GetByID(ctx, id)
+
+	order, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !auth.CanActFor(ctx, order.UserID, auth.RoleManager) {
+		return nil, ErrOrderNotFound
+	}
+	return order, nil
 }
 
-// UpdateOrderStatus updates the status of an order
-func (s *Service) UpdateOrderStatus(ctx context.Context, id string, status OrderStatus) (*Order, error) {
+// BatchGetOrders retrieves several orders by ID, in request order, along
+// with the IDs that matched no order. Callers below MANAGER only find their
+// own orders.
+func (s *Service) BatchGetOrders(ctx context.Context, ids []string) ([]*Order, []string, error) {
+	if len(ids) > MaxBatchGetIDs {
+		return nil, nil, NewValidationError(fmt.Sprintf("at most %d IDs are allowed", MaxBatchGetIDs))
+	}
+
+	orders, notFound, err := s.repo.GetByIDs(ctx, ids)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	visible := orders[:0]
+	for _, order := range orders {
+		if auth.CanActFor(ctx, order.UserID, auth.RoleManager) {
+			visible = append(visible, order)
+		} else {
+			notFound = append(notFound, order.ID)
+		}
+	}
+	return visible, notFound, nil
+}
+
+// UpdateOrderStatus moves an order to a new status, enforcing the order
+// state machine and recording who made the change and why.
+// Moving an order to CANCELLED goes through CancelOrder.
+func (s *Service) UpdateOrderStatus(ctx context.Context, id string, status OrderStatus, reason string) (*Order, error) {
 	if id == "" {
 		return nil, ErrOrderNotFound
 	}
 
-	// Validate status transition (simplified)
-	if status == OrderStatusUnspecified {
+	if !status.IsValid() {
 		return nil, NewValidationError("invalid order status")
 	}
 
-	return s.repo.UpdateStatus(ctx, id, status)
+	if status == OrderStatusCancelled {
+		return s.CancelOrder(ctx, id, reason)
+	}
+
+	order, _, err := s.changeStatus(ctx, id, status, reason)
+	return order, err
 }
 
-// ListOrdersByUser retrieves orders for a specific user with pagination
-func (s *Service) ListOrdersByUser(ctx context.Context, userID string, page, pageSize int) ([]*Order, int, error) {
-	if userID == "" {
-		return nil, 0, NewValidationError("user ID is required")
+// CancelOrder cancels an order that is still cancellable and puts its items
+// back in stock. Cancelling an already cancelled order returns it unchanged.
+// Callers below MANAGER may only cancel their own orders.
+func (s *Service) CancelOrder(ctx context.Context, id, reason string) (*Order, error) {
+	// Concurrent cancels of one order would share its saga
+	unlock := s.cancelling.lock(id)
+	defer unlock()
+
+	current, err := s.GetOrder(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if current.Status == OrderStatusCancelled {
+		return current, nil
 	}
 
-	// Set default page size if not provided
-	if pageSize <= 0 {
-		pageSize = 10
+	// Record the saga before cancelling, so the stock can't be lost to a
+	// failure or crash between cancelling and restoring it
+	saga := newCancelSaga(current)
+	if err := s.saveSaga(ctx, saga); err != nil {
+		return nil, err
 	}
-	if pageSize > 100 {
-		pageSize = 100 // Max page size
+
+	order, changed, err := s.changeStatus(ctx, id, OrderStatusCancelled, reason)
+	if err != nil || !changed {
+		// Only the call that actually cancelled the order restores stock
+		s.finishSaga(ctx, saga)
+		return order, err
 	}
 
-	// Set default page if not provided
-	if page <= 0 {
-		page = 1
+	saga.Order = order
+	err = s.advanceSaga(ctx, saga, SagaRestoring, nil)
+	if err == nil {
+		err = s.driveCancelSaga(ctx, saga)
 	}
+	if err != nil {
+		// The order is cancelled either way; its stock comes back later
+		log.Printf("Saga %s: stock of cancelled order %s not restored yet, retrying in the background: %v", saga.ID, id, err)
+		go s.retrySaga(saga)
+	}
+
+	return order, nil
+}
 
-	offset := (page - 1) * pageSize
-	return s.repo.ListByUser(ctx, userID, offset, pageSize)
+// changeStatus applies a status change allowed by the state machine and
+// reports whether the order changed. An order already in the final
+// CANCELLED status is returned as is when cancelling, so cancellation is
+// idempotent.
+func (s *Service) changeStatus(ctx context.Context, id string, status OrderStatus, reason string) (*Order, bool, error) {
+	return s.changeStatusAs(ctx, id, status, actorOf(ctx), reason)
 }
 
-// ListOrders retrieves orders with pagination and optional status filter
-func (s *Service) ListOrders(ctx context.Context, page, pageSize int, status OrderStatus) ([]*Order, int, error) {
+// changeStatusAs is changeStatus recorded as made by the given actor
+func (s *Service) changeStatusAs(ctx context.Context, id string, status OrderStatus, actor, reason string) (*Order, bool, error) {
+	// Retry if another update changes the status between the check and the write
+	for attempt := 1; ; attempt++ {
+		order, err := s.repo.GetByID(ctx, id)
+		if err != nil {
+			return nil, false, err
+		}
+
+		if status == OrderStatusCancelled && order.Status == OrderStatusCancelled {
+			return order, false, nil
+		}
+
+		if !order.Status.CanTransitionTo(status) {
+			return nil, false, invalidTransition(order.Status, status)
+		}
+
+		updated, err := s.repo.UpdateStatus(ctx, id, &StatusTransition{
+			From:   order.Status,
+			To:     status,
+			Actor:  actor,
+			Reason: reason,
+		})
+		if err == ErrStatusConflict && attempt < maxStatusUpdateAttempts {
+			continue
+		}
+		if err != nil {
+			return nil, false, err
+		}
+
+		log.Printf("Order %s moved from %s to %s", id, order.Status, status)
+		return updated, true, nil
+	}
+}
+
+// actorOf returns who a status change is recorded as made by: the caller
+// the token was issued to, or the order service itself when authorization
+// is off
+func actorOf(ctx context.Context) string {
+	if claims, ok := auth.FromContext(ctx); ok {
+		return claims.Subject
+	}
+	return ServiceName
+}
+
+// restoreStock puts the items of a saga's cancelled order back in stock. A
+// voided order only gets back the items whose stock its saga committed.
+func (s *Service) restoreStock(ctx context.Context, saga *Saga) error {
+	var changes []*productpb.StockChange
+	for i, item := range saga.Order.Items {
+		if len(saga.Steps) > 0 && saga.Steps[i].Status != StepCommitted {
+			continue
+		}
+		changes = append(changes, &productpb.StockChange{
+			ProductId: item.ProductID,
+			Quantity:  item.Quantity,
+		})
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+
+	for len(changes) > 0 {
+		err := s.productClient.BatchUpdateStock(ctx, changes)
+		var rejected *StockUpdateError
+		if !errors.As(err, &rejected) {
+			if err != nil {
+				return fmt.Errorf("failed to restore stock of order %s: %w", saga.Order.ID, err)
+			}
+			return nil
+		}
+
+		// A rejected change (e.g. the product was deleted) fails on every
+		// retry, so it is dropped and the rest of the batch tried again
+		var remaining []*productpb.StockChange
+		for i, change := range changes {
+			if !rejected.Rejected(i) {
+				remaining = append(remaining, change)
+				continue
+			}
+			log.Printf("Order %s: stock of product %s can't be restored: %v", saga.Order.ID, change.ProductId, err)
+		}
+		changes = remaining
+	}
+	return nil
+}
+
+// GetOrderHistory retrieves the status transitions of an order, oldest
+// first. Callers below MANAGER only find their own orders.
+func (s *Service) GetOrderHistory(ctx context.Context, id string) ([]*StatusTransition, error) {
+	if _, err := s.GetOrder(ctx, id); err != nil {
+		return nil, err
+	}
+	return s.repo.GetHistory(ctx, id)
+}
+
+// BatchGetOrderHistory retrieves the status transitions of several orders,
+// keyed by order ID, along with the IDs that matched no order. Callers
+// below MANAGER only find their own orders.
+func (s *Service) BatchGetOrderHistory(ctx context.Context, ids []string) (map[string][]*StatusTransition, []string, error) {
+	orders, notFound, err := s.BatchGetOrders(ctx, ids)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	visible := make([]string, len(orders))
+	for i, order := range orders {
+		visible[i] = order.ID
+	}
+	histories, err := s.repo.GetHistories(ctx, visible)
+	if err != nil {
+		return nil, nil, err
+	}
+	return histories, notFound, nil
+}
+
+// ListOrdersByUser retrieves orders for a specific user with pagination,
+// oldest first
+func (s *Service) ListOrdersByUser(ctx context.Context, userID string, query common.PageQuery) ([]*Order, common.PageInfo, error) {
+	if userID == "" {
+		return nil, common.PageInfo{}, NewValidationError("user ID is required")
+	}
+	if !auth.CanActFor(ctx, userID, auth.RoleManager) {
+		return nil, common.PageInfo{}, auth.ErrPermissionDenied
+	}
+
+	// Set default page size if not provided
+	query.Limit = common.PageSize(query.Limit)
+
+	return s.repo.ListByUser(ctx, userID, query)
+}
+
+// BatchListOrdersByUser retrieves one page of orders for each of several
+// users with an optional status filter
+func (s *Service) BatchListOrdersByUser(ctx context.Context, userIDs []string, status OrderStatus, query common.PageQuery) ([]*UserOrders, error) {
+	if len(userIDs) > MaxBatchListUsers {
+		return nil, NewValidationError(fmt.Sprintf("at most %d user IDs are allowed", MaxBatchListUsers))
+	}
+	for _, userID := range userIDs {
+		if userID == "" {
+			return nil, NewValidationError("user ID is required")
+		}
+		if !auth.CanActFor(ctx, userID, auth.RoleManager) {
+			return nil, auth.ErrPermissionDenied
+		}
+	}
+
 	// Set default page size if not provided
-	if pageSize <= 0 {
-		pageSize = 10
+	query.Limit = common.PageSize(query.Limit)
+
+	return s.repo.ListByUsers(ctx, userIDs, status, query)
+}
+
+// ListOrders retrieves orders with pagination and optional filters, oldest
+// first. Callers below MANAGER only list their own orders.
+func (s *Service) ListOrders(ctx context.Context, query common.PageQuery, filter ListFilter) ([]*Order, common.PageInfo, error) {
+	userID, err := auth.OwnUserID(ctx, filter.UserID, auth.RoleManager)
+	if err != nil {
+		return nil, common.PageInfo{}, err
 	}
-	if pageSize > 100 {
-		pageSize = 100 // Max page size
+	filter.UserID = userID
+
+	if filter.Status != OrderStatusUnspecified && !filter.Status.IsValid() {
+		return nil, common.PageInfo{}, NewValidationError("invalid order status")
+	}
+	if !filter.CreatedFrom.IsZero() && !filter.CreatedTo.IsZero() && filter.CreatedFrom.After(filter.CreatedTo) {
+		return nil, common.PageInfo{}, NewValidationError("created from must not be after created to")
 	}
 
-	// Set default page if not provided
-	if page <= 0 {
-		page = 1
+	// Set default page size if not provided
+	query.Limit = common.PageSize(query.Limit)
+
+	return s.repo.List(ctx, query, filter)
+}
+
+// WatchOrders watches the orders of one user, or of every user when userID
+// is empty. Callers below MANAGER only watch their own orders. The caller
+// must close the subscription.
+func (s *Service) WatchOrders(ctx context.Context, userID string) (*common.Subscription[*Order], error) {
+	userID, err := auth.OwnUserID(ctx, userID, auth.RoleManager)
+	if err != nil {
+		return nil, err
 	}
 
-	offset := (page - 1) * pageSize
-	return s.repo.List(ctx, offset, pageSize, status)
+	notifier, ok := s.repo.(OrderNotifier)
+	if !ok {
+		return nil, ErrWatchUnavailable
+	}
+	return notifier.SubscribeOrders(userID), nil
 }
 
 // Close closes external service connections
@@ -0,0 +1,540 @@
+package order
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"learning/internal/common"
+)
+
+const orderColumns = "id, user_id, total_amount, status, created_at, updated_at"
+
+// rowQuerier is satisfied by both the database and a transaction
+type rowQuerier interface {
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// SQLRepository implements Repository interface using a SQL database
+type SQLRepository struct {
+	db *common.Database
+}
+
+// NewSQLRepository creates a new SQL repository.
+// The schema is managed by the migrations package.
+func NewSQLRepository(db *common.Database) *SQLRepository {
+	return &SQLRepository{db: db}
+}
+
+// Create creates a new order together with its items
+func (r *SQLRepository) Create(ctx context.Context, order *Order) (*Order, error) {
+	// Generate ID if not provided
+	if order.ID == "" {
+		order.ID = uuid.New().String()
+	}
+
+	// Generate IDs for order items
+	for _, item := range order.Items {
+		if item.ID == "" {
+			item.ID = uuid.New().String()
+		}
+	}
+
+	// Set timestamps
+	now := time.Now().UTC()
+	order.CreatedAt = now
+	order.UpdatedAt = now
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, r.db.Rebind(
+		"INSERT INTO orders ("+orderColumns+") VALUES (?, ?, ?, ?, ?, ?)"),
+		order.ID, order.UserID, order.TotalAmount, order.Status, order.CreatedAt, order.UpdatedAt,
+	)
+	if err != nil {
+		if common.IsUniqueViolation(err) {
+			return nil, ErrOrderAlreadyExists
+		}
+		return nil, fmt.Errorf("failed to insert order: %w", err)
+	}
+
+	insertItem := r.db.Rebind(
+		"INSERT INTO order_items (id, order_id, position, product_id, product_name, product_price, quantity, total) " +
+			"VALUES (?, ?, ?, ?, ?, ?, ?, ?)")
+	for i, item := range order.Items {
+		_, err := tx.ExecContext(ctx, insertItem,
+			item.ID, order.ID, i, item.ProductID, item.ProductName, item.ProductPrice, item.Quantity, item.Total,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to insert order item: %w", err)
+		}
+	}
+
+	if err := r.insertTransition(ctx, tx, order.ID, newOrderTransition(order)); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit order: %w", err)
+	}
+
+	return order, nil
+}
+
+// GetByID retrieves an order by ID
+func (r *SQLRepository) GetByID(ctx context.Context, id string) (*Order, error) {
+	row := r.db.QueryRowContext(ctx, r.db.Rebind(
+		"SELECT "+orderColumns+" FROM orders WHERE id = ?"), id)
+
+	order, err := scanOrder(row)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.loadItems(ctx, []*Order{order}); err != nil {
+		return nil, err
+	}
+
+	return order, nil
+}
+
+// GetByIDs retrieves the orders with the given IDs in the order the IDs were
+// requested, each at most once, along with the IDs that matched no order
+func (r *SQLRepository) GetByIDs(ctx context.Context, ids []string) ([]*Order, []string, error) {
+	if len(ids) == 0 {
+		return []*Order{}, nil, nil
+	}
+
+	args := make([]any, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+
+	rows, err := r.db.QueryContext(ctx, r.db.Rebind(
+		"SELECT "+orderColumns+" FROM orders WHERE id IN ("+common.Placeholders(len(ids))+")"),
+		args...,
+	)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get orders: %w", err)
+	}
+	defer rows.Close()
+
+	byID := make(map[string]*Order, len(ids))
+	for rows.Next() {
+		order, err := scanOrder(rows)
+		if err != nil {
+			return nil, nil, err
+		}
+		byID[order.ID] = order
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("failed to get orders: %w", err)
+	}
+
+	// Rows come back in no particular order; restore the requested one
+	found := make([]*Order, 0, len(byID))
+	var notFound []string
+	seen := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		if order, exists := byID[id]; exists {
+			found = append(found, order)
+		} else {
+			notFound = append(notFound, id)
+		}
+	}
+
+	if err := r.loadItems(ctx, found); err != nil {
+		return nil, nil, err
+	}
+
+	return found, notFound, nil
+}
+
+// UpdateStatus moves an order from transition.From to transition.To and
+// appends the transition to its history in one transaction. It fails with
+// ErrStatusConflict if the order is no longer in transition.From.
+func (r *SQLRepository) UpdateStatus(ctx context.Context, id string, transition *StatusTransition) (*Order, error) {
+	transition.Timestamp = time.Now().UTC()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	row := tx.QueryRowContext(ctx, r.db.Rebind(
+		"UPDATE orders SET status = ?, updated_at = ? WHERE id = ? AND status = ? RETURNING "+orderColumns),
+		transition.To, transition.Timestamp, id, transition.From,
+	)
+
+	order, err := scanOrder(row)
+	if err == ErrOrderNotFound {
+		// Nothing was updated: tell a missing order apart from a lost race
+		if err := r.checkExists(ctx, tx, id); err != nil {
+			return nil, err
+		}
+		return nil, ErrStatusConflict
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.insertTransition(ctx, tx, id, transition); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit status update: %w", err)
+	}
+
+	if err := r.loadItems(ctx, []*Order{order}); err != nil {
+		return nil, err
+	}
+
+	return order, nil
+}
+
+// GetHistory retrieves the status transitions of an order, oldest first
+func (r *SQLRepository) GetHistory(ctx context.Context, orderID string) ([]*StatusTransition, error) {
+	if err := r.checkExists(ctx, r.db, orderID); err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.QueryContext(ctx, r.db.Rebind(
+		"SELECT from_status, to_status, actor, reason, created_at FROM order_status_history "+
+			"WHERE order_id = ? ORDER BY seq"),
+		orderID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load order history: %w", err)
+	}
+	defer rows.Close()
+
+	history := []*StatusTransition{}
+	for rows.Next() {
+		var t StatusTransition
+		if err := rows.Scan(&t.From, &t.To, &t.Actor, &t.Reason, &t.Timestamp); err != nil {
+			return nil, fmt.Errorf("failed to scan order history: %w", err)
+		}
+		history = append(history, &t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to load order history: %w", err)
+	}
+
+	return history, nil
+}
+
+// GetHistories retrieves the status transitions of several orders, oldest
+// first, keyed by order ID; IDs that match no order are left out
+func (r *SQLRepository) GetHistories(ctx context.Context, orderIDs []string) (map[string][]*StatusTransition, error) {
+	histories := make(map[string][]*StatusTransition, len(orderIDs))
+	if len(orderIDs) == 0 {
+		return histories, nil
+	}
+
+	args := make([]any, len(orderIDs))
+	for i, id := range orderIDs {
+		args[i] = id
+	}
+	in := " IN (" + common.Placeholders(len(orderIDs)) + ")"
+
+	rows, err := r.db.QueryContext(ctx, r.db.Rebind("SELECT id FROM orders WHERE id"+in), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get orders: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan order: %w", err)
+		}
+		histories[id] = []*StatusTransition{}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get orders: %w", err)
+	}
+
+	rows, err = r.db.QueryContext(ctx, r.db.Rebind(
+		"SELECT order_id, from_status, to_status, actor, reason, created_at FROM order_status_history "+
+			"WHERE order_id"+in+" ORDER BY order_id, seq"),
+		args...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load order history: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var orderID string
+		var t StatusTransition
+		if err := rows.Scan(&orderID, &t.From, &t.To, &t.Actor, &t.Reason, &t.Timestamp); err != nil {
+			return nil, fmt.Errorf("failed to scan order history: %w", err)
+		}
+		histories[orderID] = append(histories[orderID], &t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to load order history: %w", err)
+	}
+
+	return histories, nil
+}
+
+// ListByUser retrieves orders for a specific user with pagination, oldest first
+func (r *SQLRepository) ListByUser(ctx context.Context, userID string, query common.PageQuery) ([]*Order, common.PageInfo, error) {
+	return r.list(ctx, " WHERE user_id = ?", []any{userID}, query)
+}
+
+// ListByUsers retrieves one page of orders for each of several users, oldest
+// first, with an optional status filter. Results are in request order with
+// one entry per distinct user ID.
+func (r *SQLRepository) ListByUsers(ctx context.Context, userIDs []string, status OrderStatus, query common.PageQuery) ([]*UserOrders, error) {
+	results := make([]*UserOrders, 0, len(userIDs))
+	byUser := make(map[string]*UserOrders, len(userIDs))
+	args := make([]any, 0, len(userIDs)+1)
+	for _, userID := range userIDs {
+		if _, seen := byUser[userID]; seen {
+			continue
+		}
+		result := &UserOrders{UserID: userID, Orders: []*Order{}}
+		byUser[userID] = result
+		results = append(results, result)
+		args = append(args, userID)
+	}
+	if len(results) == 0 {
+		return results, nil
+	}
+
+	where := " WHERE user_id IN (" + common.Placeholders(len(args)) + ")"
+	if status != OrderStatusUnspecified {
+		where += " AND status = ?"
+		args = append(args, status)
+	}
+
+	if !query.SkipTotal {
+		if err := r.countByUser(ctx, where, args, byUser); err != nil {
+			return nil, err
+		}
+	}
+
+	conditions, rangeArgs := query.RangeConditions()
+	for _, condition := range conditions {
+		where += " AND " + condition
+	}
+	args = append(args, rangeArgs...)
+
+	// Number each user's orders in the range so one query can page them all,
+	// taking one order more than the page to tell if the range goes on; a
+	// page from the end numbers them backwards
+	orderBy := "created_at, id"
+	first, last := query.Offset, query.Offset+query.Limit+1
+	if query.FromEnd {
+		orderBy = "created_at DESC, id DESC"
+		first, last = 0, query.Limit+1
+	}
+	rows, err := r.db.QueryContext(ctx, r.db.Rebind(
+		"SELECT "+orderColumns+" FROM ("+
+			"SELECT "+orderColumns+", ROW_NUMBER() OVER (PARTITION BY user_id ORDER BY "+orderBy+") AS position "+
+			"FROM orders"+where+") numbered "+
+			"WHERE position > ? AND position <= ? ORDER BY user_id, created_at, id"),
+		append(args, first, last)...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list orders: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		order, err := scanOrder(rows)
+		if err != nil {
+			return nil, err
+		}
+		byUser[order.UserID].Orders = append(byUser[order.UserID].Orders, order)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list orders: %w", err)
+	}
+
+	var orders []*Order
+	for _, result := range results {
+		more := len(result.Orders) > query.Limit
+		if more && query.FromEnd {
+			result.Orders = result.Orders[1:]
+		} else if more {
+			result.Orders = result.Orders[:query.Limit]
+		}
+		result.Page = query.PageInfo(result.Page.Total, more)
+		orders = append(orders, result.Orders...)
+	}
+
+	if err := r.loadItems(ctx, orders); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// countByUser sets the total of each user's orders matching where
+func (r *SQLRepository) countByUser(ctx context.Context, where string, args []any, byUser map[string]*UserOrders) error {
+	rows, err := r.db.QueryContext(ctx, r.db.Rebind("SELECT user_id, COUNT(*) FROM orders"+where+" GROUP BY user_id"), args...)
+	if err != nil {
+		return fmt.Errorf("failed to count orders: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var userID string
+		var total int
+		if err := rows.Scan(&userID, &total); err != nil {
+			return fmt.Errorf("failed to scan order count: %w", err)
+		}
+		byUser[userID].Page.Total = total
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to count orders: %w", err)
+	}
+	return nil
+}
+
+// List retrieves orders with pagination and optional filters, oldest first
+func (r *SQLRepository) List(ctx context.Context, query common.PageQuery, filter ListFilter) ([]*Order, common.PageInfo, error) {
+	var conditions []string
+	var args []any
+	if filter.UserID != "" {
+		conditions = append(conditions, "user_id = ?")
+		args = append(args, filter.UserID)
+	}
+	if filter.Status != OrderStatusUnspecified {
+		conditions = append(conditions, "status = ?")
+		args = append(args, filter.Status)
+	}
+	if !filter.CreatedFrom.IsZero() {
+		conditions = append(conditions, "created_at >= ?")
+		args = append(args, filter.CreatedFrom.UTC())
+	}
+	if !filter.CreatedTo.IsZero() {
+		conditions = append(conditions, "created_at <= ?")
+		args = append(args, filter.CreatedTo.UTC())
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = " WHERE " + strings.Join(conditions, " AND ")
+	}
+	return r.list(ctx, where, args, query)
+}
+
+// list runs a paginated order query with the given WHERE clause
+func (r *SQLRepository) list(ctx context.Context, where string, args []any, query common.PageQuery) ([]*Order, common.PageInfo, error) {
+	orders := []*Order{}
+	info, err := r.db.ListPage(ctx, "orders", orderColumns, where, args, query, func(rows *sql.Rows) error {
+		order, err := scanOrder(rows)
+		if err != nil {
+			return err
+		}
+		orders = append(orders, order)
+		return nil
+	})
+	if err != nil {
+		return nil, common.PageInfo{}, err
+	}
+
+	if err := r.loadItems(ctx, orders); err != nil {
+		return nil, common.PageInfo{}, err
+	}
+
+	return orders, info, nil
+}
+
+// insertTransition appends a transition to an order's history
+func (r *SQLRepository) insertTransition(ctx context.Context, tx *sql.Tx, orderID string, transition *StatusTransition) error {
+	_, err := tx.ExecContext(ctx, r.db.Rebind(
+		"INSERT INTO order_status_history (order_id, seq, from_status, to_status, actor, reason, created_at) "+
+			"SELECT ?, COALESCE(MAX(seq), 0) + 1, ?, ?, ?, ?, ? FROM order_status_history WHERE order_id = ?"),
+		orderID, transition.From, transition.To, transition.Actor, transition.Reason,
+		transition.Timestamp.UTC(), orderID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record status transition: %w", err)
+	}
+	return nil
+}
+
+// checkExists returns ErrOrderNotFound unless the order exists
+func (r *SQLRepository) checkExists(ctx context.Context, q rowQuerier, id string) error {
+	var exists int
+	err := q.QueryRowContext(ctx, r.db.Rebind("SELECT 1 FROM orders WHERE id = ?"), id).Scan(&exists)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrOrderNotFound
+		}
+		return fmt.Errorf("failed to check order: %w", err)
+	}
+	return nil
+}
+
+// loadItems fills in the items of the given orders with a single query
+func (r *SQLRepository) loadItems(ctx context.Context, orders []*Order) error {
+	if len(orders) == 0 {
+		return nil
+	}
+
+	byID := make(map[string]*Order, len(orders))
+	args := make([]any, len(orders))
+	for i, order := range orders {
+		order.Items = []*OrderItem{}
+		byID[order.ID] = order
+		args[i] = order.ID
+	}
+
+	rows, err := r.db.QueryContext(ctx, r.db.Rebind(
+		"SELECT order_id, id, product_id, product_name, product_price, quantity, total FROM order_items "+
+			"WHERE order_id IN ("+common.Placeholders(len(args))+") ORDER BY order_id, position"),
+		args...,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to load order items: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var orderID string
+		var item OrderItem
+		err := rows.Scan(&orderID, &item.ID, &item.ProductID, &item.ProductName,
+			&item.ProductPrice, &item.Quantity, &item.Total)
+		if err != nil {
+			return fmt.Errorf("failed to scan order item: %w", err)
+		}
+		byID[orderID].Items = append(byID[orderID].Items, &item)
+	}
+
+	return rows.Err()
+}
+
+// scanOrder reads an order (without items) from a row selected with orderColumns
+func scanOrder(row interface{ Scan(...any) error }) (*Order, error) {
+	var order Order
+	err := row.Scan(&order.ID, &order.UserID, &order.TotalAmount, &order.Status, &order.CreatedAt, &order.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrOrderNotFound
+		}
+		return nil, err
+	}
+	return &order, nil
+}
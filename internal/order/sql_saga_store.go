@@ -0,0 +1,75 @@
+package order
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"learning/internal/common"
+)
+
+// SQLSagaStore implements SagaStore using a SQL database.
+// Each saga is stored as a JSON document next to its state.
+type SQLSagaStore struct {
+	db *common.Database
+}
+
+// NewSQLSagaStore creates a new SQL saga store.
+// The schema is managed by the migrations package.
+func NewSQLSagaStore(db *common.Database) *SQLSagaStore {
+	return &SQLSagaStore{db: db}
+}
+
+// Save inserts or replaces the saga's current state
+func (s *SQLSagaStore) Save(ctx context.Context, saga *Saga) error {
+	data, err := json.Marshal(saga)
+	if err != nil {
+		return fmt.Errorf("failed to encode saga: %w", err)
+	}
+
+	_, err = s.db.ExecContext(ctx, s.db.Rebind(
+		"INSERT INTO order_sagas (id, state, data, created_at, updated_at) VALUES (?, ?, ?, ?, ?) "+
+			"ON CONFLICT (id) DO UPDATE SET state = excluded.state, data = excluded.data, updated_at = excluded.updated_at"),
+		saga.ID, saga.State, string(data), saga.CreatedAt.UTC(), saga.UpdatedAt.UTC(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save saga: %w", err)
+	}
+	return nil
+}
+
+// Delete removes a saga; deleting an unknown saga is not an error
+func (s *SQLSagaStore) Delete(ctx context.Context, id string) error {
+	if _, err := s.db.ExecContext(ctx, s.db.Rebind("DELETE FROM order_sagas WHERE id = ?"), id); err != nil {
+		return fmt.Errorf("failed to delete saga: %w", err)
+	}
+	return nil
+}
+
+// List returns all stored sagas, oldest first
+func (s *SQLSagaStore) List(ctx context.Context) ([]*Saga, error) {
+	rows, err := s.db.QueryContext(ctx, "SELECT data FROM order_sagas ORDER BY created_at, id")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sagas: %w", err)
+	}
+	defer rows.Close()
+
+	sagas := []*Saga{}
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, fmt.Errorf("failed to scan saga: %w", err)
+		}
+
+		var saga Saga
+		if err := json.Unmarshal([]byte(data), &saga); err != nil {
+			return nil, fmt.Errorf("failed to decode saga: %w", err)
+		}
+		sagas = append(sagas, &saga)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list sagas: %w", err)
+	}
+
+	return sagas, nil
+}
@@ -0,0 +1,84 @@
+package order
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	ErrInvalidStatusTransition = errors.New("invalid order status transition")
+	ErrStatusConflict          = errors.New("order status changed concurrently")
+)
+
+// StatusTransition records a single change of an order's status
+type StatusTransition struct {
+	From      OrderStatus
+	To        OrderStatus
+	Actor     string
+	Reason    string
+	Timestamp time.Time
+}
+
+// transitions lists the statuses each status may move to.
+// DELIVERED and CANCELLED are final.
+var transitions = map[OrderStatus][]OrderStatus{
+	OrderStatusPending:    {OrderStatusConfirmed, OrderStatusCancelled},
+	OrderStatusConfirmed:  {OrderStatusProcessing, OrderStatusCancelled},
+	OrderStatusProcessing: {OrderStatusShipped},
+	OrderStatusShipped:    {OrderStatusDelivered},
+}
+
+var statusNames = map[OrderStatus]string{
+	OrderStatusUnspecified: "UNSPECIFIED",
+	OrderStatusPending:     "PENDING",
+	OrderStatusConfirmed:   "CONFIRMED",
+	OrderStatusProcessing:  "PROCESSING",
+	OrderStatusShipped:     "SHIPPED",
+	OrderStatusDelivered:   "DELIVERED",
+	OrderStatusCancelled:   "CANCELLED",
+}
+
+// String returns the status name
+func (s OrderStatus) String() string {
+	if name, ok := statusNames[s]; ok {
+		return name
+	}
+	return fmt.Sprintf("OrderStatus(%d)", int32(s))
+}
+
+// IsValid reports whether s is a known status other than unspecified
+func (s OrderStatus) IsValid() bool {
+	return s >= OrderStatusPending && s <= OrderStatusCancelled
+}
+
+// CanTransitionTo reports whether an order may move from s to next
+func (s OrderStatus) CanTransitionTo(next OrderStatus) bool {
+	for _, allowed := range transitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// IsCancellable reports whether an order in status s may still be cancelled
+func (s OrderStatus) IsCancellable() bool {
+	return s.CanTransitionTo(OrderStatusCancelled)
+}
+
+// newOrderTransition is the history entry recorded when an order is created
+func newOrderTransition(order *Order) *StatusTransition {
+	return &StatusTransition{
+		From:      OrderStatusUnspecified,
+		To:        order.Status,
+		Actor:     order.UserID,
+		Reason:    "order created",
+		Timestamp: order.CreatedAt,
+	}
+}
+
+// invalidTransition builds the error returned for a forbidden status change
+func invalidTransition(from, to OrderStatus) error {
+	return fmt.Errorf("%w from %s to %s", ErrInvalidStatusTransition, from, to)
+}
@@ -0,0 +1,124 @@
+package product
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"learning/internal/common"
+)
+
+const (
+	opPut               = "put"
+	opDelete            = "delete"
+	opDeleteReservation = "delete_reservation"
+)
+
+// journalRecord is a single logged change to the product set.
+// Records carry whole products and reservations so replaying one twice is
+// harmless; a change touching several of them carries all of them so they
+// are applied together.
+type journalRecord struct {
+	Op          string       `json:"op"`
+	Product     *Product     `json:"product,omitempty"`
+	Products    []*Product   `json:"products,omitempty"`
+	Reservation *Reservation `json:"reservation,omitempty"`
+	ID          string       `json:"id,omitempty"`
+}
+
+// snapshot is the persisted form of the whole product set
+type snapshot struct {
+	Products     map[string]*Product     `json:"products"`
+	Reservations map[string]*Reservation `json:"reservations"`
+}
+
+// NewFileBackedRepository creates an in-memory repository whose contents are
+// persisted to a snapshot and append-only log inside dataDir
+func NewFileBackedRepository(dataDir string) (*InMemoryRepository, error) {
+	journal, err := common.OpenJournal(dataDir, "products", common.DefaultCompactEvery)
+	if err != nil {
+		return nil, err
+	}
+
+	repo := NewInMemoryRepository()
+	err = journal.Replay(
+		func(data []byte) error {
+			return json.Unmarshal(data, &snapshot{Products: repo.products, Reservations: repo.reservations})
+		},
+		func(data []byte) error {
+			var record journalRecord
+			if err := json.Unmarshal(data, &record); err != nil {
+				return err
+			}
+			return repo.apply(record)
+		},
+	)
+	if err != nil {
+		journal.Close()
+		return nil, err
+	}
+
+	repo.journal = journal
+	log.Printf("Loaded %d products from %s", len(repo.products), dataDir)
+	return repo, nil
+}
+
+// Close closes the journal of a file-backed repository
+func (r *InMemoryRepository) Close() error {
+	if r.journal == nil {
+		return nil
+	}
+	return r.journal.Close()
+}
+
+// apply replays a journal record against the product and reservation maps
+func (r *InMemoryRepository) apply(record journalRecord) error {
+	switch record.Op {
+	case opPut:
+		if record.Product == nil && len(record.Products) == 0 && record.Reservation == nil {
+			return fmt.Errorf("put record without product or reservation")
+		}
+		if record.Product != nil {
+			r.products[record.Product.ID] = record.Product
+		}
+		for _, product := range record.Products {
+			r.products[product.ID] = product
+		}
+		if record.Reservation != nil {
+			r.reservations[record.Reservation.ID] = record.Reservation
+		}
+	case opDelete:
+		r.deleteProduct(record.ID)
+	case opDeleteReservation:
+		delete(r.reservations, record.ID)
+	default:
+		return fmt.Errorf("unknown journal op %q", record.Op)
+	}
+	return nil
+}
+
+// persist logs a change before it is applied to the map.
+// The caller must hold the write lock.
+func (r *InMemoryRepository) persist(record journalRecord) error {
+	if r.journal == nil {
+		return nil
+	}
+
+	if err := r.journal.Append(record); err != nil {
+		return fmt.Errorf("failed to persist product: %w", err)
+	}
+	return nil
+}
+
+// compact writes a fresh snapshot once the log is long enough.
+// The caller must hold the write lock and have applied the latest change.
+func (r *InMemoryRepository) compact() {
+	if r.journal == nil || !r.journal.NeedsCompaction() {
+		return
+	}
+
+	if err := r.journal.Compact(snapshot{Products: r.products, Reservations: r.reservations}); err != nil {
+		// The log still holds every change, so this only delays compaction
+		log.Printf("Failed to compact product journal: %v", err)
+	}
+}
@@ -0,0 +1,366 @@
+package product
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+
+	"learning/internal/common"
+	pb "learning/pkg/product/pb"
+)
+
+// GRPCRepository implements Repository on top of the product service gRPC API.
+// It lets other processes (e.g. the API gateway) share the product service's data.
+type GRPCRepository struct {
+	client pb.ProductServiceClient
+}
+
+// NewGRPCRepository creates a repository backed by a product service connection
+func NewGRPCRepository(conn grpc.ClientConnInterface) *GRPCRepository {
+	return &GRPCRepository{
+		client: pb.NewProductServiceClient(conn),
+	}
+}
+
+// Create creates a new product
+func (r *GRPCRepository) Create(ctx context.Context, product *Product) (*Product, error) {
+	resp, err := r.client.CreateProduct(ctx, &pb.CreateProductRequest{
+		Name:        product.Name,
+		Description: product.Description,
+		Price:       product.Price,
+		Stock:       product.Stock,
+		Category:    product.Category,
+	})
+	if err != nil {
+		return nil, fromStatus(err)
+	}
+
+	return protoToProduct(resp.Product), nil
+}
+
+// GetByID retrieves a product by ID
+func (r *GRPCRepository) GetByID(ctx context.Context, id string) (*Product, error) {
+	resp, err := r.client.GetProduct(ctx, &pb.GetProductRequest{Id: id})
+	if err != nil {
+		return nil, fromStatus(err)
+	}
+
+	return protoToProduct(resp.Product), nil
+}
+
+// GetByIDs retrieves several products by ID in one call
+func (r *GRPCRepository) GetByIDs(ctx context.Context, ids []string) ([]*Product, []string, error) {
+	resp, err := r.client.BatchGetProducts(ctx, &pb.BatchGetProductsRequest{Ids: ids})
+	if err != nil {
+		return nil, nil, fromStatus(err)
+	}
+
+	products := make([]*Product, len(resp.Products))
+	for i, p := range resp.Products {
+		products[i] = protoToProduct(p)
+	}
+
+	return products, resp.NotFoundIds, nil
+}
+
+// Update updates an existing product
+func (r *GRPCRepository) Update(ctx context.Context, product *Product) (*Product, error) {
+	resp, err := r.client.UpdateProduct(ctx, &pb.UpdateProductRequest{
+		Id:          product.ID,
+		Name:        product.Name,
+		Description: product.Description,
+		Price:       product.Price,
+		Stock:       product.Stock,
+		Category:    product.Category,
+	})
+	if err != nil {
+		return nil, fromStatus(err)
+	}
+
+	return protoToProduct(resp.Product), nil
+}
+
+// Patch changes only the listed fields of a product through the product service
+func (r *GRPCRepository) Patch(ctx context.Context, product *Product, fields []string) (*Product, error) {
+	resp, err := r.client.UpdateProduct(ctx, &pb.UpdateProductRequest{
+		Id:          product.ID,
+		Name:        product.Name,
+		Description: product.Description,
+		Price:       product.Price,
+		Stock:       product.Stock,
+		Category:    product.Category,
+		UpdateMask:  &fieldmaskpb.FieldMask{Paths: fields},
+	})
+	if err != nil {
+		return nil, fromStatus(err)
+	}
+
+	return protoToProduct(resp.Product), nil
+}
+
+// Delete deletes a product by ID
+func (r *GRPCRepository) Delete(ctx context.Context, id string) error {
+	_, err := r.client.DeleteProduct(ctx, &pb.DeleteProductRequest{Id: id})
+	if err != nil {
+		return fromStatus(err)
+	}
+	return nil
+}
+
+// List retrieves products with pagination and optional filters.
+// An offset is converted to a page number, so it should be a multiple of the
+// limit; cursors are sent as a page token.
+func (r *GRPCRepository) List(ctx context.Context, query common.PageQuery, filter ListFilter) ([]*Product, common.PageInfo, error) {
+	if query.Limit <= 0 {
+		return []*Product{}, common.PageInfo{}, nil
+	}
+
+	req := &pb.ListProductsRequest{
+		PageSize:  int32(query.Limit),
+		Category:  filter.Category,
+		Search:    filter.Search,
+		InStock:   filter.InStock,
+		SkipTotal: query.SkipTotal,
+	}
+	if query.IsKeyset() {
+		req.PageToken = query.PageToken()
+	} else {
+		req.Page = int32(query.Offset/query.Limit + 1)
+	}
+
+	resp, err := r.client.ListProducts(ctx, req)
+	if err != nil {
+		return nil, common.PageInfo{}, fromStatus(err)
+	}
+
+	products := make([]*Product, len(resp.Products))
+	for i, p := range resp.Products {
+		products[i] = protoToProduct(p)
+	}
+
+	return products, common.PageInfo{
+		Total:       int(resp.Total),
+		HasPrevious: resp.PreviousPageToken != "",
+		HasNext:     resp.NextPageToken != "",
+	}, nil
+}
+
+// Categories counts the products in each category, ordered by name
+func (r *GRPCRepository) Categories(ctx context.Context) ([]*CategoryCount, error) {
+	resp, err := r.client.ListCategories(ctx, &pb.ListCategoriesRequest{})
+	if err != nil {
+		return nil, fromStatus(err)
+	}
+
+	categories := make([]*CategoryCount, len(resp.Categories))
+	for i, category := range resp.Categories {
+		categories[i] = &CategoryCount{
+			Name:  category.Name,
+			Count: int(category.ProductCount),
+		}
+	}
+	return categories, nil
+}
+
+// UpdateStock updates product stock
+func (r *GRPCRepository) UpdateStock(ctx context.Context, productID string, quantity int32) (*Product, error) {
+	resp, err := r.client.UpdateStock(ctx, &pb.UpdateStockRequest{
+		ProductId: productID,
+		Quantity:  quantity,
+	})
+	if err != nil {
+		return nil, fromStatus(err)
+	}
+
+	return protoToProduct(resp.Product), nil
+}
+
+// BatchUpdateStock updates the stock of several products, all or none
+func (r *GRPCRepository) BatchUpdateStock(ctx context.Context, changes []*StockChange) ([]*Product, error) {
+	protoChanges := make([]*pb.StockChange, len(changes))
+	for i, change := range changes {
+		protoChanges[i] = &pb.StockChange{
+			ProductId: change.ProductID,
+			Quantity:  change.Quantity,
+		}
+	}
+
+	resp, err := r.client.BatchUpdateStock(ctx, &pb.BatchUpdateStockRequest{Changes: protoChanges})
+	if err != nil {
+		return nil, fromStatus(err)
+	}
+
+	if !resp.Success {
+		failures := make([]*StockChangeFailure, len(resp.Failures))
+		for i, failure := range resp.Failures {
+			failures[i] = &StockChangeFailure{
+				Index:     int(failure.Index),
+				ProductID: failure.ProductId,
+				Err:       ErrInsufficientStock,
+			}
+			if failure.Error == pb.StockChangeError_STOCK_CHANGE_ERROR_PRODUCT_NOT_FOUND {
+				failures[i].Err = ErrProductNotFound
+			}
+		}
+		return nil, &BatchStockError{Failures: failures}
+	}
+
+	products := make([]*Product, len(resp.Products))
+	for i, p := range resp.Products {
+		products[i] = protoToProduct(p)
+	}
+
+	return products, nil
+}
+
+// Reserve holds stock of a product until the reservation expires
+func (r *GRPCRepository) Reserve(ctx context.Context, reservation *Reservation) (*Reservation, error) {
+	var ttlSeconds int32
+	if !reservation.ExpiresAt.IsZero() {
+		ttlSeconds = int32(time.Until(reservation.ExpiresAt).Round(time.Second) / time.Second)
+		if ttlSeconds < 1 {
+			ttlSeconds = 1
+		}
+	}
+
+	resp, err := r.client.ReserveStock(ctx, &pb.ReserveStockRequest{
+		ReservationId: reservation.ID,
+		ProductId:     reservation.ProductID,
+		Quantity:      reservation.Quantity,
+		TtlSeconds:    ttlSeconds,
+	})
+	if err != nil {
+		if status.Code(err) == codes.AlreadyExists {
+			return nil, ErrReservationConflict
+		}
+		return nil, fromStatus(err)
+	}
+
+	return protoToReservation(resp.Reservation), nil
+}
+
+// CommitReservation turns a reservation into a permanent stock reduction
+func (r *GRPCRepository) CommitReservation(ctx context.Context, id string) (*Reservation, error) {
+	resp, err := r.client.CommitReservation(ctx, &pb.CommitReservationRequest{ReservationId: id})
+	if err != nil {
+		return nil, fromReservationStatus(err)
+	}
+
+	return protoToReservation(resp.Reservation), nil
+}
+
+// ReleaseReservation gives a reservation's stock back
+func (r *GRPCRepository) ReleaseReservation(ctx context.Context, id string) (*Reservation, error) {
+	resp, err := r.client.ReleaseReservation(ctx, &pb.ReleaseReservationRequest{ReservationId: id})
+	if err != nil {
+		return nil, fromReservationStatus(err)
+	}
+
+	return protoToReservation(resp.Reservation), nil
+}
+
+// WatchStock calls fn with each change to one product, or to every product
+// when productID is empty, until ctx is done or the stream breaks
+func (r *GRPCRepository) WatchStock(ctx context.Context, productID string, fn func(*Product)) error {
+	stream, err := r.client.WatchStock(ctx, &pb.WatchStockRequest{ProductId: productID})
+	if err != nil {
+		return fromStatus(err)
+	}
+
+	for {
+		resp, err := stream.Recv()
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return fromStatus(err)
+		}
+		fn(protoToProduct(resp.Product))
+	}
+}
+
+// protoToProduct converts protobuf product to domain product
+func protoToProduct(p *pb.Product) *Product {
+	if p == nil {
+		return nil
+	}
+
+	return &Product{
+		ID:          p.Id,
+		Name:        p.Name,
+		Description: p.Description,
+		Price:       p.Price,
+		Stock:       p.Stock,
+		Reserved:    p.ReservedStock,
+		Category:    p.Category,
+		CreatedAt:   p.CreatedAt.AsTime(),
+		UpdatedAt:   p.UpdatedAt.AsTime(),
+	}
+}
+
+// fromStatus converts a gRPC status error back into the matching domain error
+func fromStatus(err error) error {
+	st, ok := status.FromError(err)
+	if !ok {
+		return err
+	}
+
+	switch st.Code() {
+	case codes.NotFound:
+		return ErrProductNotFound
+	case codes.AlreadyExists:
+		return ErrProductAlreadyExists
+	case codes.InvalidArgument:
+		if st.Message() == ErrInsufficientStock.Error() {
+			return ErrInsufficientStock
+		}
+		return NewValidationError(st.Message())
+	case codes.Unauthenticated, codes.PermissionDenied:
+		// The caller's token or role falls short; pass the reason on as is
+		return err
+	default:
+		return fmt.Errorf("product service: %w", err)
+	}
+}
+
+// protoToReservation converts protobuf reservation to domain reservation
+func protoToReservation(r *pb.Reservation) *Reservation {
+	if r == nil {
+		return nil
+	}
+
+	var reservationStatus ReservationStatus
+	for domain, proto := range reservationStatusToProto {
+		if proto == r.Status {
+			reservationStatus = domain
+		}
+	}
+
+	return &Reservation{
+		ID:        r.Id,
+		ProductID: r.ProductId,
+		Quantity:  r.Quantity,
+		Status:    reservationStatus,
+		ExpiresAt: r.ExpiresAt.AsTime(),
+		CreatedAt: r.CreatedAt.AsTime(),
+		UpdatedAt: r.UpdatedAt.AsTime(),
+	}
+}
+
+// fromReservationStatus converts a gRPC status error from a reservation call
+// back into the matching domain error
+func fromReservationStatus(err error) error {
+	switch status.Code(err) {
+	case codes.NotFound:
+		return ErrReservationNotFound
+	case codes.FailedPrecondition:
+		return ErrReservationClosed
+	default:
+		return fromStatus(err)
+	}
+}
@@ -3,11 +3,13 @@ package product
 import (
 	"context"
 	"log"
+	"time"
 
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/timestamppb"
 
+	"learning/internal/common"
 	pb "learning/pkg/product/pb"
 )
 
@@ -70,11 +72,50 @@ func (h *Handler) GetProduct(ctx context.Context, req *pb.GetProductRequest) (*p
 	}, nil
 }
 
-// UpdateProduct updates an existing product
+// BatchGetProducts retrieves several products by ID
+func (h *Handler) BatchGetProducts(ctx context.Context, req *pb.BatchGetProductsRequest) (*pb.BatchGetProductsResponse, error) {
+	log.Printf("BatchGetProducts request: %d IDs", len(req.Ids))
+
+	products, notFound, err := h.service.BatchGetProducts(ctx, req.Ids)
+	if err != nil {
+		log.Printf("BatchGetProducts error: %v", err)
+
+		if validationErr, ok := err.(*ValidationError); ok {
+			return nil, status.Error(codes.InvalidArgument, validationErr.Message)
+		}
+
+		return nil, status.Error(codes.Internal, "failed to get products")
+	}
+
+	protoProducts := make([]*pb.Product, len(products))
+	for i, product := range products {
+		protoProducts[i] = h.productToProto(product)
+	}
+
+	return &pb.BatchGetProductsResponse{
+		Products:    protoProducts,
+		NotFoundIds: notFound,
+	}, nil
+}
+
+// UpdateProduct updates an existing product, or only the fields in the update mask
 func (h *Handler) UpdateProduct(ctx context.Context, req *pb.UpdateProductRequest) (*pb.UpdateProductResponse, error) {
 	log.Printf("UpdateProduct request: %+v", req)
 
-	product, err := h.service.UpdateProduct(ctx, req.Id, req.Name, req.Description, req.Category, req.Price, req.Stock)
+	name, description, category, price, stock := req.Name, req.Description, req.Category, req.Price, req.Stock
+	if p := req.GetProduct(); p != nil {
+		name, description, category, price, stock = p.Name, p.Description, p.Category, p.Price, p.Stock
+	}
+
+	var product *Product
+	var err error
+	// Requests carrying a product come from PATCH, which must never replace
+	// the whole product: an empty mask fails validation instead
+	if fields := req.GetUpdateMask().GetPaths(); len(fields) > 0 || req.Product != nil {
+		product, err = h.service.PatchProduct(ctx, req.Id, name, description, category, price, stock, fields)
+	} else {
+		product, err = h.service.UpdateProduct(ctx, req.Id, req.Name, req.Description, req.Category, req.Price, req.Stock)
+	}
 	if err != nil {
 		log.Printf("UpdateProduct error: %v", err)
 
@@ -88,6 +129,10 @@ func (h *Handler) UpdateProduct(ctx context.Context, req *pb.UpdateProductReques
 			return nil, status.Error(codes.NotFound, "product not found")
 		}
 
+		if err == ErrInsufficientStock {
+			return nil, status.Error(codes.InvalidArgument, "insufficient stock")
+		}
+
 		return nil, status.Error(codes.Internal, "failed to update product")
 	}
 
@@ -116,11 +161,22 @@ func (h *Handler) DeleteProduct(ctx context.Context, req *pb.DeleteProductReques
 	}, nil
 }
 
-// ListProducts retrieves products with pagination and optional category filter
+// ListProducts retrieves products with pagination and optional filters
 func (h *Handler) ListProducts(ctx context.Context, req *pb.ListProductsRequest) (*pb.ListProductsResponse, error) {
 	log.Printf("ListProducts request: %+v", req)
 
-	products, total, err := h.service.ListProducts(ctx, int(req.Page), int(req.PageSize), req.Category)
+	query, err := common.NewPageQuery(int(req.Page), int(req.PageSize), req.PageToken)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	query.SkipTotal = req.SkipTotal
+
+	filter := ListFilter{
+		Category: req.Category,
+		Search:   req.Search,
+		InStock:  req.InStock,
+	}
+	products, info, err := h.service.ListProducts(ctx, query, filter)
 	if err != nil {
 		log.Printf("ListProducts error: %v", err)
 		return nil, status.Error(codes.Internal, "failed to list products")
@@ -131,11 +187,38 @@ func (h *Handler) ListProducts(ctx context.Context, req *pb.ListProductsRequest)
 		protoProducts[i] = h.productToProto(product)
 	}
 
-	return &pb.ListProductsResponse{
+	resp := &pb.ListProductsResponse{
 		Products: protoProducts,
-		Total:    int32(total),
+		Total:    int32(info.Total),
 		Page:     req.Page,
 		PageSize: req.PageSize,
+	}
+	if len(products) > 0 {
+		resp.PreviousPageToken, resp.NextPageToken = info.Tokens(products[0].Cursor(), products[len(products)-1].Cursor())
+	}
+	return resp, nil
+}
+
+// ListCategories counts the products in each category
+func (h *Handler) ListCategories(ctx context.Context, req *pb.ListCategoriesRequest) (*pb.ListCategoriesResponse, error) {
+	log.Printf("ListCategories request: %+v", req)
+
+	categories, err := h.service.ListCategories(ctx)
+	if err != nil {
+		log.Printf("ListCategories error: %v", err)
+		return nil, status.Error(codes.Internal, "failed to list categories")
+	}
+
+	protoCategories := make([]*pb.Category, len(categories))
+	for i, category := range categories {
+		protoCategories[i] = &pb.Category{
+			Name:         category.Name,
+			ProductCount: int32(category.Count),
+		}
+	}
+
+	return &pb.ListCategoriesResponse{
+		Categories: protoCategories,
 	}, nil
 }
 
@@ -161,19 +244,210 @@ func (h *Handler) UpdateStock(ctx context.Context, req *pb.UpdateStockRequest) (
 	return &pb.UpdateStockResponse{
 		Success:  true,
 		NewStock: product.Stock,
+		Product:  h.productToProto(product),
 	}, nil
 }
 
+// BatchUpdateStock updates the stock of several products, all or none.
+// Rejected changes are reported in the response rather than as an error.
+func (h *Handler) BatchUpdateStock(ctx context.Context, req *pb.BatchUpdateStockRequest) (*pb.BatchUpdateStockResponse, error) {
+	log.Printf("BatchUpdateStock request: %d changes", len(req.Changes))
+
+	changes := make([]*StockChange, len(req.Changes))
+	for i, change := range req.Changes {
+		changes[i] = &StockChange{
+			ProductID: change.ProductId,
+			Quantity:  change.Quantity,
+		}
+	}
+
+	products, err := h.service.BatchUpdateStock(ctx, changes)
+	if err != nil {
+		log.Printf("BatchUpdateStock error: %v", err)
+
+		if validationErr, ok := err.(*ValidationError); ok {
+			return nil, status.Error(codes.InvalidArgument, validationErr.Message)
+		}
+
+		if batchErr, ok := err.(*BatchStockError); ok {
+			failures := make([]*pb.StockChangeFailure, len(batchErr.Failures))
+			for i, failure := range batchErr.Failures {
+				failures[i] = &pb.StockChangeFailure{
+					Index:     int32(failure.Index),
+					ProductId: failure.ProductID,
+					Error:     stockChangeErrorToProto[failure.Err],
+				}
+			}
+			return &pb.BatchUpdateStockResponse{
+				Success:  false,
+				Failures: failures,
+			}, nil
+		}
+
+		return nil, status.Error(codes.Internal, "failed to update stock")
+	}
+
+	protoProducts := make([]*pb.Product, len(products))
+	for i, product := range products {
+		protoProducts[i] = h.productToProto(product)
+	}
+
+	return &pb.BatchUpdateStockResponse{
+		Success:  true,
+		Products: protoProducts,
+	}, nil
+}
+
+// ReserveStock holds stock for a limited time
+func (h *Handler) ReserveStock(ctx context.Context, req *pb.ReserveStockRequest) (*pb.ReserveStockResponse, error) {
+	log.Printf("ReserveStock request: %+v", req)
+
+	ttl := time.Duration(req.TtlSeconds) * time.Second
+	reservation, err := h.service.ReserveStock(ctx, req.ReservationId, req.ProductId, req.Quantity, ttl)
+	if err != nil {
+		log.Printf("ReserveStock error: %v", err)
+
+		if validationErr, ok := err.(*ValidationError); ok {
+			return nil, status.Error(codes.InvalidArgument, validationErr.Message)
+		}
+
+		if err == ErrProductNotFound {
+			return nil, status.Error(codes.NotFound, "product not found")
+		}
+
+		if err == ErrInsufficientStock {
+			return nil, status.Error(codes.InvalidArgument, "insufficient stock")
+		}
+
+		if err == ErrReservationConflict {
+			return nil, status.Error(codes.AlreadyExists, err.Error())
+		}
+
+		return nil, status.Error(codes.Internal, "failed to reserve stock")
+	}
+
+	return &pb.ReserveStockResponse{
+		Reservation: h.reservationToProto(reservation),
+	}, nil
+}
+
+// CommitReservation turns a reservation into a permanent stock reduction
+func (h *Handler) CommitReservation(ctx context.Context, req *pb.CommitReservationRequest) (*pb.CommitReservationResponse, error) {
+	log.Printf("CommitReservation request: %+v", req)
+
+	reservation, err := h.service.CommitReservation(ctx, req.ReservationId)
+	if err != nil {
+		log.Printf("CommitReservation error: %v", err)
+		return nil, reservationStatusError(err, "failed to commit reservation")
+	}
+
+	return &pb.CommitReservationResponse{
+		Reservation: h.reservationToProto(reservation),
+	}, nil
+}
+
+// ReleaseReservation gives a reservation's stock back
+func (h *Handler) ReleaseReservation(ctx context.Context, req *pb.ReleaseReservationRequest) (*pb.ReleaseReservationResponse, error) {
+	log.Printf("ReleaseReservation request: %+v", req)
+
+	reservation, err := h.service.ReleaseReservation(ctx, req.ReservationId)
+	if err != nil {
+		log.Printf("ReleaseReservation error: %v", err)
+		return nil, reservationStatusError(err, "failed to release reservation")
+	}
+
+	return &pb.ReleaseReservationResponse{
+		Reservation: h.reservationToProto(reservation),
+	}, nil
+}
+
+// WatchStock streams products as their stock or details change
+func (h *Handler) WatchStock(req *pb.WatchStockRequest, stream pb.ProductService_WatchStockServer) error {
+	log.Printf("WatchStock request: %+v", req)
+
+	subscription, err := h.service.WatchStock(stream.Context(), req.ProductId)
+	if err != nil {
+		log.Printf("WatchStock error: %v", err)
+
+		if err == ErrProductNotFound {
+			return status.Error(codes.NotFound, "product not found")
+		}
+
+		if err == ErrWatchUnavailable {
+			return status.Error(codes.Unimplemented, "product changes can't be watched")
+		}
+
+		return status.Error(codes.Internal, "failed to watch stock")
+	}
+	defer subscription.Close()
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case product, ok := <-subscription.Events():
+			if !ok {
+				if subscription.Dropped() {
+					return status.Error(codes.ResourceExhausted, "watcher fell too far behind")
+				}
+				return status.Error(codes.Unavailable, "service is shutting down")
+			}
+			if err := stream.Send(&pb.WatchStockResponse{Product: h.productToProto(product)}); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// reservationStatusError maps reservation errors to gRPC status errors
+func reservationStatusError(err error, internalMessage string) error {
+	switch err {
+	case ErrReservationNotFound:
+		return status.Error(codes.NotFound, "reservation not found")
+	case ErrReservationClosed:
+		return status.Error(codes.FailedPrecondition, "reservation is no longer active")
+	default:
+		return status.Error(codes.Internal, internalMessage)
+	}
+}
+
 // productToProto converts domain product to protobuf product
 func (h *Handler) productToProto(product *Product) *pb.Product {
 	return &pb.Product{
-		Id:          product.ID,
-		Name:        product.Name,
-		Description: product.Description,
-		Price:       product.Price,
-		Stock:       product.Stock,
-		Category:    product.Category,
-		CreatedAt:   timestamppb.New(product.CreatedAt),
-		UpdatedAt:   timestamppb.New(product.UpdatedAt),
+		Id:             product.ID,
+		Name:           product.Name,
+		Description:    product.Description,
+		Price:          product.Price,
+		Stock:          product.Stock,
+		Category:       product.Category,
+		CreatedAt:      timestamppb.New(product.CreatedAt),
+		UpdatedAt:      timestamppb.New(product.UpdatedAt),
+		ReservedStock:  product.Reserved,
+		AvailableStock: product.Available(),
 	}
 }
+
+// reservationToProto converts domain reservation to protobuf reservation
+func (h *Handler) reservationToProto(reservation *Reservation) *pb.Reservation {
+	return &pb.Reservation{
+		Id:        reservation.ID,
+		ProductId: reservation.ProductID,
+		Quantity:  reservation.Quantity,
+		Status:    reservationStatusToProto[reservation.Status],
+		ExpiresAt: timestamppb.New(reservation.ExpiresAt),
+		CreatedAt: timestamppb.New(reservation.CreatedAt),
+		UpdatedAt: timestamppb.New(reservation.UpdatedAt),
+	}
+}
+
+var stockChangeErrorToProto = map[error]pb.StockChangeError{
+	ErrProductNotFound:   pb.StockChangeError_STOCK_CHANGE_ERROR_PRODUCT_NOT_FOUND,
+	ErrInsufficientStock: pb.StockChangeError_STOCK_CHANGE_ERROR_INSUFFICIENT_STOCK,
+}
+
+var reservationStatusToProto = map[ReservationStatus]pb.ReservationStatus{
+	ReservationActive:    pb.ReservationStatus_RESERVATION_STATUS_ACTIVE,
+	ReservationCommitted: pb.ReservationStatus_RESERVATION_STATUS_COMMITTED,
+	ReservationReleased:  pb.ReservationStatus_RESERVATION_STATUS_RELEASED,
+	ReservationExpired:   pb.ReservationStatus_RESERVATION_STATUS_EXPIRED,
+}
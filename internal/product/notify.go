@@ -0,0 +1,169 @@
+package product
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"learning/internal/common"
+)
+
+// ErrWatchUnavailable is returned when the repository doesn't announce changes
+var ErrWatchUnavailable = errors.New("product changes can't be watched")
+
+// ChangeBufferSize is how many product changes a watcher may fall behind
+// before it is dropped
+const ChangeBufferSize = 256
+
+// StockNotifier is implemented by repositories that announce product changes
+type StockNotifier interface {
+	// SubscribeStock watches one product, or every product when productID is empty
+	SubscribeStock(productID string) *common.Subscription[*Product]
+}
+
+// NotifyingRepository wraps a repository and announces every product whose
+// stock or details it changes
+type NotifyingRepository struct {
+	Repository
+	changes *common.Broadcaster[*Product]
+}
+
+// NewNotifyingRepository wraps repo so its product changes can be watched;
+// each watcher buffers up to bufferSize changes
+func NewNotifyingRepository(repo Repository, bufferSize int) *NotifyingRepository {
+	return &NotifyingRepository{
+		Repository: repo,
+		changes:    common.NewBroadcaster[*Product](bufferSize),
+	}
+}
+
+// SubscribeStock watches one product, or every product when productID is empty
+func (r *NotifyingRepository) SubscribeStock(productID string) *common.Subscription[*Product] {
+	if productID == "" {
+		return r.changes.Subscribe(nil)
+	}
+	return r.changes.Subscribe(func(p *Product) bool {
+		return p.ID == productID
+	})
+}
+
+// Close ends every watch
+func (r *NotifyingRepository) Close() {
+	r.changes.Close()
+}
+
+// Create creates a new product
+func (r *NotifyingRepository) Create(ctx context.Context, product *Product) (*Product, error) {
+	created, err := r.Repository.Create(ctx, product)
+	if err == nil {
+		r.changes.Publish(created)
+	}
+	return created, err
+}
+
+// Update updates an existing product
+func (r *NotifyingRepository) Update(ctx context.Context, product *Product) (*Product, error) {
+	updated, err := r.Repository.Update(ctx, product)
+	if err == nil {
+		r.changes.Publish(updated)
+	}
+	return updated, err
+}
+
+// Patch changes only the listed fields of a product
+func (r *NotifyingRepository) Patch(ctx context.Context, product *Product, fields []string) (*Product, error) {
+	updated, err := r.Repository.Patch(ctx, product, fields)
+	if err == nil {
+		r.changes.Publish(updated)
+	}
+	return updated, err
+}
+
+// UpdateStock updates product stock
+func (r *NotifyingRepository) UpdateStock(ctx context.Context, productID string, quantity int32) (*Product, error) {
+	updated, err := r.Repository.UpdateStock(ctx, productID, quantity)
+	if err == nil {
+		r.changes.Publish(updated)
+	}
+	return updated, err
+}
+
+// BatchUpdateStock updates the stock of several products, all or none
+func (r *NotifyingRepository) BatchUpdateStock(ctx context.Context, changes []*StockChange) ([]*Product, error) {
+	results, err := r.Repository.BatchUpdateStock(ctx, changes)
+	if err != nil {
+		return nil, err
+	}
+
+	// A product changed several times is announced once, as it ended up
+	announced := make(map[string]bool, len(results))
+	for i := len(results) - 1; i >= 0; i-- {
+		if !announced[results[i].ID] {
+			announced[results[i].ID] = true
+			r.changes.Publish(results[i])
+		}
+	}
+	return results, nil
+}
+
+// Reserve holds stock of a product until the reservation expires
+func (r *NotifyingRepository) Reserve(ctx context.Context, reservation *Reservation) (*Reservation, error) {
+	reserved, err := r.Repository.Reserve(ctx, reservation)
+	if err == nil {
+		r.publishProduct(ctx, reserved.ProductID)
+	}
+	return reserved, err
+}
+
+// CommitReservation turns a reservation into a permanent stock reduction
+func (r *NotifyingRepository) CommitReservation(ctx context.Context, id string) (*Reservation, error) {
+	committed, err := r.Repository.CommitReservation(ctx, id)
+	if err == nil {
+		r.publishProduct(ctx, committed.ProductID)
+	}
+	return committed, err
+}
+
+// ReleaseReservation gives a reservation's stock back
+func (r *NotifyingRepository) ReleaseReservation(ctx context.Context, id string) (*Reservation, error) {
+	released, err := r.Repository.ReleaseReservation(ctx, id)
+	if err == nil {
+		r.publishProduct(ctx, released.ProductID)
+	}
+	return released, err
+}
+
+// ExpireReservations expires reservations through the wrapped repository,
+// if it supports that, and announces the products whose stock came back
+func (r *NotifyingRepository) ExpireReservations(ctx context.Context, now time.Time) ([]*Reservation, error) {
+	expirer, ok := r.Repository.(ReservationExpirer)
+	if !ok {
+		return nil, nil
+	}
+
+	expired, err := expirer.ExpireReservations(ctx, now)
+
+	announced := make(map[string]bool, len(expired))
+	for _, reservation := range expired {
+		if !announced[reservation.ProductID] {
+			announced[reservation.ProductID] = true
+			r.publishProduct(ctx, reservation.ProductID)
+		}
+	}
+	return expired, err
+}
+
+// publishProduct announces the current state of a product changed through
+// its reservations
+func (r *NotifyingRepository) publishProduct(ctx context.Context, productID string) {
+	product, err := r.Repository.GetByID(ctx, productID)
+	if err == ErrProductNotFound {
+		return // deleted since it was reserved
+	}
+	if err != nil {
+		log.Printf("Failed to load changed product %s: %v", productID, err)
+		return
+	}
+	r.changes.Publish(product)
+}
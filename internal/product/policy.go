@@ -0,0 +1,25 @@
+package product
+
+import (
+	"learning/internal/auth"
+	pb "learning/pkg/product/pb"
+)
+
+// Policy says who may call each RPC of the product service. The catalogue
+// is public; reservations are only made by the order service, which calls
+// as an ADMIN.
+var Policy = auth.Policy{
+	pb.ProductService_CreateProduct_FullMethodName:      auth.Require(auth.RoleManager),
+	pb.ProductService_GetProduct_FullMethodName:         auth.Public,
+	pb.ProductService_BatchGetProducts_FullMethodName:   auth.Public,
+	pb.ProductService_UpdateProduct_FullMethodName:      auth.Require(auth.RoleManager),
+	pb.ProductService_DeleteProduct_FullMethodName:      auth.Require(auth.RoleManager),
+	pb.ProductService_ListProducts_FullMethodName:       auth.Public,
+	pb.ProductService_ListCategories_FullMethodName:     auth.Public,
+	pb.ProductService_UpdateStock_FullMethodName:        auth.Require(auth.RoleManager),
+	pb.ProductService_BatchUpdateStock_FullMethodName:   auth.Require(auth.RoleManager),
+	pb.ProductService_ReserveStock_FullMethodName:       auth.Require(auth.RoleAdmin),
+	pb.ProductService_CommitReservation_FullMethodName:  auth.Require(auth.RoleAdmin),
+	pb.ProductService_ReleaseReservation_FullMethodName: auth.Require(auth.RoleAdmin),
+	pb.ProductService_WatchStock_FullMethodName:         auth.Public,
+}
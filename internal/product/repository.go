@@ -3,10 +3,15 @@ package product
 import (
 	"context"
 	"errors"
+	"fmt"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/google/uuid"
+
+	"learning/internal/common"
 )
 
 var (
@@ -22,31 +27,124 @@ type Product struct {
 	Description string
 	Price       float64
 	Stock       int32
+	Reserved    int32 // held by active reservations, part of Stock
 	Category    string
 	CreatedAt   time.Time
 	UpdatedAt   time.Time
 }
 
+// Cursor returns the product's position in lists
+func (p *Product) Cursor() common.Cursor {
+	return common.Cursor{CreatedAt: p.CreatedAt, ID: p.ID}
+}
+
+// Available returns the stock not held by reservations
+func (p *Product) Available() int32 {
+	return p.Stock - p.Reserved
+}
+
+// Fields of a product that an update can be limited to
+const (
+	FieldName        = "name"
+	FieldDescription = "description"
+	FieldCategory    = "category"
+	FieldPrice       = "price"
+	FieldStock       = "stock"
+)
+
+// UpdatableFields lists every field an update can be limited to
+var UpdatableFields = []string{FieldName, FieldDescription, FieldCategory, FieldPrice, FieldStock}
+
+// applyFields copies the listed fields from src to dst
+func applyFields(dst, src *Product, fields []string) error {
+	for _, field := range fields {
+		switch field {
+		case FieldName:
+			dst.Name = src.Name
+		case FieldDescription:
+			dst.Description = src.Description
+		case FieldCategory:
+			dst.Category = src.Category
+		case FieldPrice:
+			dst.Price = src.Price
+		case FieldStock:
+			dst.Stock = src.Stock
+		default:
+			return unknownFieldError(field)
+		}
+	}
+	return nil
+}
+
+// unknownFieldError reports a field that updates can't be limited to
+func unknownFieldError(field string) error {
+	return NewValidationError(fmt.Sprintf("unknown field %q", field))
+}
+
+// ListFilter narrows down the products returned by List; zero fields match
+// every product
+type ListFilter struct {
+	Category string // exact category
+	Search   string // case-insensitive substring of the name or description
+	InStock  *bool  // whether any stock is available
+}
+
+// Matches reports whether a product passes the filter
+func (f ListFilter) Matches(p *Product) bool {
+	if f.Category != "" && p.Category != f.Category {
+		return false
+	}
+	if f.InStock != nil && (p.Available() > 0) != *f.InStock {
+		return false
+	}
+	if f.Search != "" {
+		search := strings.ToLower(f.Search)
+		if !strings.Contains(strings.ToLower(p.Name), search) &&
+			!strings.Contains(strings.ToLower(p.Description), search) {
+			return false
+		}
+	}
+	return true
+}
+
+// CategoryCount is a product category and how many products are in it
+type CategoryCount struct {
+	Name  string
+	Count int
+}
+
 // Repository interface for product operations
 type Repository interface {
 	Create(ctx context.Context, product *Product) (*Product, error)
 	GetByID(ctx context.Context, id string) (*Product, error)
+	GetByIDs(ctx context.Context, ids []string) ([]*Product, []string, error)
 	Update(ctx context.Context, product *Product) (*Product, error)
+	Patch(ctx context.Context, product *Product, fields []string) (*Product, error)
 	Delete(ctx context.Context, id string) error
-	List(ctx context.Context, offset, limit int, category string) ([]*Product, int, error)
+	List(ctx context.Context, query common.PageQuery, filter ListFilter) ([]*Product, common.PageInfo, error)
+	Categories(ctx context.Context) ([]*CategoryCount, error)
 	UpdateStock(ctx context.Context, productID string, quantity int32) (*Product, error)
+	BatchUpdateStock(ctx context.Context, changes []*StockChange) ([]*Product, error)
+	Reserve(ctx context.Context, reservation *Reservation) (*Reservation, error)
+	CommitReservation(ctx context.Context, id string) (*Reservation, error)
+	ReleaseReservation(ctx context.Context, id string) (*Reservation, error)
 }
 
 // InMemoryRepository implements Repository interface using in-memory storage
 type InMemoryRepository struct {
-	products map[string]*Product
-	mutex    sync.RWMutex
+	products     map[string]*Product
+	reservations map[string]*Reservation
+	mutex        sync.RWMutex
+
+	// journal persists changes for file-backed repositories; nil otherwise
+	journal *common.Journal
 }
 
 // NewInMemoryRepository creates a new in-memory repository
 func NewInMemoryRepository() *InMemoryRepository {
 	return &InMemoryRepository{
-		products: make(map[string]*Product),
+		products:     make(map[string]*Product),
+		reservations: make(map[string]*Reservation),
 	}
 }
 
@@ -71,7 +169,11 @@ func (r *InMemoryRepository) Create(ctx context.Context, product *Product) (*Pro
 	product.UpdatedAt = now
 
 	// Store product
+	if err := r.persist(journalRecord{Op: opPut, Product: product}); err != nil {
+		return nil, err
+	}
 	r.products[product.ID] = product
+	r.compact()
 
 	return product, nil
 }
@@ -89,6 +191,31 @@ func (r *InMemoryRepository) GetByID(ctx context.Context, id string) (*Product,
 	return product, nil
 }
 
+// GetByIDs retrieves the products with the given IDs in the order the IDs were
+// requested, each at most once, along with the IDs that matched no product
+func (r *InMemoryRepository) GetByIDs(ctx context.Context, ids []string) ([]*Product, []string, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	found := make([]*Product, 0, len(ids))
+	var notFound []string
+	seen := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		if product, exists := r.products[id]; exists {
+			found = append(found, product)
+		} else {
+			notFound = append(notFound, id)
+		}
+	}
+
+	return found, notFound, nil
+}
+
 // Update updates an existing product
 func (r *InMemoryRepository) Update(ctx context.Context, product *Product) (*Product, error) {
 	r.mutex.Lock()
@@ -99,16 +226,59 @@ func (r *InMemoryRepository) Update(ctx context.Context, product *Product) (*Pro
 		return nil, ErrProductNotFound
 	}
 
-	// Update fields
+	// Stock held by reservations can't be taken away
+	if product.Stock < existingProduct.Reserved {
+		return nil, ErrInsufficientStock
+	}
+
+	// Update fields; reservations are only changed through their own methods
 	product.CreatedAt = existingProduct.CreatedAt
 	product.UpdatedAt = time.Now()
+	product.Reserved = existingProduct.Reserved
 
 	// Store updated product
+	if err := r.persist(journalRecord{Op: opPut, Product: product}); err != nil {
+		return nil, err
+	}
 	r.products[product.ID] = product
+	r.compact()
 
 	return product, nil
 }
 
+// Patch changes only the listed fields of a stored product to their values
+// in product
+func (r *InMemoryRepository) Patch(ctx context.Context, product *Product, fields []string) (*Product, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	existingProduct, exists := r.products[product.ID]
+	if !exists {
+		return nil, ErrProductNotFound
+	}
+
+	updatedProduct := *existingProduct
+	if err := applyFields(&updatedProduct, product, fields); err != nil {
+		return nil, err
+	}
+
+	// Stock held by reservations can't be taken away
+	if updatedProduct.Stock < updatedProduct.Reserved {
+		return nil, ErrInsufficientStock
+	}
+
+	updatedProduct.UpdatedAt = time.Now()
+
+	// Store updated product
+	if err := r.persist(journalRecord{Op: opPut, Product: &updatedProduct}); err != nil {
+		return nil, err
+	}
+	r.products[product.ID] = &updatedProduct
+	r.compact()
+
+	return &updatedProduct, nil
+}
+
 // Delete deletes a product by ID
 func (r *InMemoryRepository) Delete(ctx context.Context, id string) error {
 	r.mutex.Lock()
@@ -118,41 +288,51 @@ func (r *InMemoryRepository) Delete(ctx context.Context, id string) error {
 		return ErrProductNotFound
 	}
 
-	delete(r.products, id)
+	if err := r.persist(journalRecord{Op: opDelete, ID: id}); err != nil {
+		return err
+	}
+	r.deleteProduct(id)
+	r.compact()
+
 	return nil
 }
 
-// List retrieves products with pagination and optional category filter
-func (r *InMemoryRepository) List(ctx context.Context, offset, limit int, category string) ([]*Product, int, error) {
+// List retrieves products with pagination and optional filters, oldest first
+func (r *InMemoryRepository) List(ctx context.Context, query common.PageQuery, filter ListFilter) ([]*Product, common.PageInfo, error) {
 	r.mutex.RLock()
 	defer r.mutex.RUnlock()
 
-	// Convert map to slice and filter by category if specified
-	var products []*Product
+	// Convert map to slice and apply the filter
+	products := []*Product{}
 	for _, product := range r.products {
-		if category == "" || product.Category == category {
+		if filter.Matches(product) {
 			products = append(products, product)
 		}
 	}
 
-	total := len(products)
+	page, info := common.Paginate(products, (*Product).Cursor, query)
+	return page, info, nil
+}
 
-	// Apply pagination
-	start := offset
-	if start > total {
-		start = total
-	}
+// Categories counts the products in each category, ordered by name
+func (r *InMemoryRepository) Categories(ctx context.Context) ([]*CategoryCount, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
 
-	end := start + limit
-	if end > total {
-		end = total
+	counts := make(map[string]int)
+	for _, product := range r.products {
+		counts[product.Category]++
 	}
 
-	if start >= total {
-		return []*Product{}, total, nil
+	categories := make([]*CategoryCount, 0, len(counts))
+	for name, count := range counts {
+		categories = append(categories, &CategoryCount{Name: name, Count: count})
 	}
+	sort.Slice(categories, func(i, j int) bool {
+		return categories[i].Name < categories[j].Name
+	})
 
-	return products[start:end], total, nil
+	return categories, nil
 }
 
 // UpdateStock updates product stock
@@ -165,8 +345,9 @@ func (r *InMemoryRepository) UpdateStock(ctx context.Context, productID string,
 		return nil, ErrProductNotFound
 	}
 
+	// Stock held by reservations can't be taken away
 	newStock := product.Stock + quantity
-	if newStock < 0 {
+	if newStock < product.Reserved {
 		return nil, ErrInsufficientStock
 	}
 
@@ -175,8 +356,222 @@ func (r *InMemoryRepository) UpdateStock(ctx context.Context, productID string,
 	updatedProduct.Stock = newStock
 	updatedProduct.UpdatedAt = time.Now()
 
-	// Store updated product
+	// Store updated product; the log records the resulting product rather
+	// than the delta so replays stay idempotent
+	if err := r.persist(journalRecord{Op: opPut, Product: &updatedProduct}); err != nil {
+		return nil, err
+	}
 	r.products[productID] = &updatedProduct
+	r.compact()
 
 	return &updatedProduct, nil
 }
+
+// BatchUpdateStock applies every change or, if any of them fails, none of
+// them; the failures are reported in a *BatchStockError. It returns the
+// resulting product for each change, in order.
+func (r *InMemoryRepository) BatchUpdateStock(ctx context.Context, changes []*StockChange) ([]*Product, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	// Work on copies so a rejected batch leaves the products untouched
+	now := time.Now()
+	updated := make(map[string]*Product)
+	var touched []*Product
+	var failures []*StockChangeFailure
+
+	for i, change := range changes {
+		product, exists := updated[change.ProductID]
+		if !exists {
+			existing, ok := r.products[change.ProductID]
+			if !ok {
+				failures = append(failures, &StockChangeFailure{Index: i, ProductID: change.ProductID, Err: ErrProductNotFound})
+				continue
+			}
+			copied := *existing
+			copied.UpdatedAt = now
+			product = &copied
+			updated[change.ProductID] = product
+			touched = append(touched, product)
+		}
+
+		// Stock held by reservations can't be taken away
+		if product.Stock+change.Quantity < product.Reserved {
+			failures = append(failures, &StockChangeFailure{Index: i, ProductID: change.ProductID, Err: ErrInsufficientStock})
+			continue
+		}
+		product.Stock += change.Quantity
+	}
+
+	if len(failures) > 0 {
+		return nil, &BatchStockError{Failures: failures}
+	}
+
+	// One record for the whole batch, so it is replayed all or nothing
+	if err := r.persist(journalRecord{Op: opPut, Products: touched}); err != nil {
+		return nil, err
+	}
+	for _, product := range touched {
+		r.products[product.ID] = product
+	}
+	r.compact()
+
+	results := make([]*Product, len(changes))
+	for i, change := range changes {
+		result := *updated[change.ProductID]
+		results[i] = &result
+	}
+	return results, nil
+}
+
+// Reserve holds stock of a product until the reservation expires.
+// Reserving with the ID of an existing reservation returns it unchanged, so
+// callers can safely retry, provided it is for the same product and quantity.
+func (r *InMemoryRepository) Reserve(ctx context.Context, reservation *Reservation) (*Reservation, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if existing, exists := r.reservations[reservation.ID]; exists {
+		return reservation.checkRetry(existing)
+	}
+
+	product, exists := r.products[reservation.ProductID]
+	if !exists {
+		return nil, ErrProductNotFound
+	}
+
+	if product.Available() < reservation.Quantity {
+		return nil, ErrInsufficientStock
+	}
+
+	// Generate ID if not provided
+	if reservation.ID == "" {
+		reservation.ID = uuid.New().String()
+	}
+
+	now := time.Now()
+	reservation.Status = ReservationActive
+	reservation.CreatedAt = now
+	reservation.UpdatedAt = now
+
+	updatedProduct := *product
+	updatedProduct.Reserved += reservation.Quantity
+	updatedProduct.UpdatedAt = now
+
+	if err := r.persist(journalRecord{Op: opPut, Product: &updatedProduct, Reservation: reservation}); err != nil {
+		return nil, err
+	}
+	r.products[product.ID] = &updatedProduct
+	r.reservations[reservation.ID] = reservation
+	r.compact()
+
+	return reservation, nil
+}
+
+// CommitReservation turns a reservation into a permanent stock reduction.
+// Committing an already committed reservation returns it unchanged.
+func (r *InMemoryRepository) CommitReservation(ctx context.Context, id string) (*Reservation, error) {
+	return r.closeReservation(id, ReservationCommitted, time.Now())
+}
+
+// ReleaseReservation gives a reservation's stock back.
+// Releasing an already released or expired reservation returns it unchanged.
+func (r *InMemoryRepository) ReleaseReservation(ctx context.Context, id string) (*Reservation, error) {
+	return r.closeReservation(id, ReservationReleased, time.Now())
+}
+
+// ExpireReservations expires active reservations past their TTL and forgets
+// closed ones older than the retention period
+func (r *InMemoryRepository) ExpireReservations(ctx context.Context, now time.Time) ([]*Reservation, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	var expired []*Reservation
+	for id, reservation := range r.reservations {
+		switch {
+		case reservation.Status == ReservationActive && !now.Before(reservation.ExpiresAt):
+			closed, err := r.closeReservationLocked(reservation, ReservationExpired, now)
+			if err != nil {
+				return expired, err
+			}
+			expired = append(expired, closed)
+
+		case reservation.Status != ReservationActive && now.Sub(reservation.UpdatedAt) > ReservationRetention:
+			if err := r.persist(journalRecord{Op: opDeleteReservation, ID: id}); err != nil {
+				return expired, err
+			}
+			delete(r.reservations, id)
+			r.compact()
+		}
+	}
+
+	return expired, nil
+}
+
+// closeReservation moves an active reservation to a final status
+func (r *InMemoryRepository) closeReservation(id string, status ReservationStatus, now time.Time) (*Reservation, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	reservation, exists := r.reservations[id]
+	if !exists {
+		return nil, ErrReservationNotFound
+	}
+
+	return r.closeReservationLocked(reservation, status, now)
+}
+
+// closeReservationLocked applies a final status and adjusts the product's
+// stock. The caller must hold the write lock.
+func (r *InMemoryRepository) closeReservationLocked(reservation *Reservation, status ReservationStatus, now time.Time) (*Reservation, error) {
+	if reservation.Status != ReservationActive {
+		// Repeating the same outcome is fine; anything else is a conflict.
+		// Releasing an expired reservation counts as the same outcome.
+		if reservation.Status == status || (status == ReservationReleased && reservation.Status == ReservationExpired) {
+			return reservation, nil
+		}
+		return nil, ErrReservationClosed
+	}
+
+	if status == ReservationCommitted && !now.Before(reservation.ExpiresAt) {
+		return nil, ErrReservationClosed
+	}
+
+	updated := *reservation
+	updated.Status = status
+	updated.UpdatedAt = now
+
+	// The product may have been deleted since; then only the reservation changes
+	var updatedProduct *Product
+	if product, exists := r.products[reservation.ProductID]; exists {
+		copied := *product
+		copied.Reserved -= reservation.Quantity
+		if status == ReservationCommitted {
+			copied.Stock -= reservation.Quantity
+		}
+		copied.UpdatedAt = now
+		updatedProduct = &copied
+	}
+
+	if err := r.persist(journalRecord{Op: opPut, Product: updatedProduct, Reservation: &updated}); err != nil {
+		return nil, err
+	}
+	if updatedProduct != nil {
+		r.products[updatedProduct.ID] = updatedProduct
+	}
+	r.reservations[updated.ID] = &updated
+	r.compact()
+
+	return &updated, nil
+}
+
+// deleteProduct removes a product together with its reservations.
+// The caller must hold the write lock.
+func (r *InMemoryRepository) deleteProduct(id string) {
+	delete(r.products, id)
+	for reservationID, reservation := range r.reservations {
+		if reservation.ProductID == id {
+			delete(r.reservations, reservationID)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package product
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+)
+
+var (
+	ErrReservationNotFound = errors.New("reservation not found")
+	ErrReservationClosed   = errors.New("reservation is no longer active")
+	// ErrReservationConflict is returned when a reservation ID is reused for
+	// a different product or quantity
+	ErrReservationConflict = errors.New("reservation ID is already used for a different product or quantity")
+)
+
+const (
+	// DefaultReservationTTL is used when a reservation asks for no TTL
+	DefaultReservationTTL = 15 * time.Minute
+	// MaxReservationTTL caps how long stock can be held by a reservation
+	MaxReservationTTL = 24 * time.Hour
+	// ReservationRetention is how long closed reservations are kept so
+	// repeated commit and release calls stay idempotent
+	ReservationRetention = 24 * time.Hour
+	// ReservationSweepInterval is how often expired reservations are released
+	ReservationSweepInterval = 30 * time.Second
+
+	maxReservationIDLength = 64
+)
+
+// ReservationStatus is the lifecycle state of a stock reservation
+type ReservationStatus string
+
+const (
+	ReservationActive    ReservationStatus = "ACTIVE"
+	ReservationCommitted ReservationStatus = "COMMITTED"
+	ReservationReleased  ReservationStatus = "RELEASED"
+	ReservationExpired   ReservationStatus = "EXPIRED"
+)
+
+// Reservation holds stock of a product for a limited time. While active it
+// counts against the product's available stock; committing it removes the
+// stock for good, releasing or expiring it gives the stock back.
+type Reservation struct {
+	ID        string
+	ProductID string
+	Quantity  int32
+	Status    ReservationStatus
+	ExpiresAt time.Time
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// checkRetry returns the existing reservation a reservation with the same ID
+// retries, or ErrReservationConflict if it asks for different stock
+func (r *Reservation) checkRetry(existing *Reservation) (*Reservation, error) {
+	if existing.ProductID != r.ProductID || existing.Quantity != r.Quantity {
+		return nil, ErrReservationConflict
+	}
+	return existing, nil
+}
+
+// ReservationExpirer is implemented by repositories the sweeper can clean up
+type ReservationExpirer interface {
+	// ExpireReservations expires active reservations past their TTL and
+	// forgets closed ones older than the retention period. It returns the
+	// reservations it expired.
+	ExpireReservations(ctx context.Context, now time.Time) ([]*Reservation, error)
+}
+
+// RunReservationSweeper expires reservations every interval until ctx is done
+func RunReservationSweeper(ctx context.Context, repo ReservationExpirer, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			expired, err := repo.ExpireReservations(ctx, now)
+			if err != nil {
+				log.Printf("Reservation sweep failed: %v", err)
+				continue
+			}
+			if len(expired) > 0 {
+				log.Printf("Expired %d stock reservations", len(expired))
+			}
+		}
+	}
+}
@@ -2,9 +2,17 @@ package product
 
 import (
 	"context"
+	"fmt"
+	"slices"
 	"strings"
+	"time"
+
+	"learning/internal/common"
 )
 
+// MaxBatchGetIDs caps the number of products fetched by one batch lookup
+const MaxBatchGetIDs = 1000
+
 // Service handles business logic for product operations
 type Service struct {
 	repo Repository
@@ -43,6 +51,15 @@ func (s *Service) GetProduct(ctx context.Context, id string) (*Product, error) {
 	return s.repo.GetByID(ctx, id)
 }
 
+// BatchGetProducts retrieves several products by ID, in request order, along
+// with the IDs that matched no product
+func (s *Service) BatchGetProducts(ctx context.Context, ids []string) ([]*Product, []string, error) {
+	if len(ids) > MaxBatchGetIDs {
+		return nil, nil, NewValidationError(fmt.Sprintf("at most %d IDs are allowed", MaxBatchGetIDs))
+	}
+	return s.repo.GetByIDs(ctx, ids)
+}
+
 // UpdateProduct updates an existing product
 func (s *Service) UpdateProduct(ctx context.Context, id, name, description, category string, price float64, stock int32) (*Product, error) {
 	if id == "" {
@@ -66,6 +83,35 @@ func (s *Service) UpdateProduct(ctx context.Context, id, name, description, cate
 	return s.repo.Update(ctx, product)
 }
 
+// PatchProduct updates only the listed fields of an existing product, leaving
+// the others as they are. Only the listed fields are validated.
+func (s *Service) PatchProduct(ctx context.Context, id, name, description, category string, price float64, stock int32, fields []string) (*Product, error) {
+	if id == "" {
+		return nil, ErrProductNotFound
+	}
+
+	fields, err := normalizeFields(fields)
+	if err != nil {
+		return nil, err
+	}
+
+	product := &Product{
+		ID:          id,
+		Name:        strings.TrimSpace(name),
+		Description: strings.TrimSpace(description),
+		Category:    strings.TrimSpace(category),
+		Price:       price,
+		Stock:       stock,
+	}
+
+	// Validate input
+	if err := s.validateFields(product, fields); err != nil {
+		return nil, err
+	}
+
+	return s.repo.Patch(ctx, product, fields)
+}
+
 // DeleteProduct deletes a product by ID
 func (s *Service) DeleteProduct(ctx context.Context, id string) error {
 	if id == "" {
@@ -74,23 +120,21 @@ func (s *Service) DeleteProduct(ctx context.Context, id string) error {
 	return s.repo.Delete(ctx, id)
 }
 
-// ListProducts retrieves products with pagination and optional category filter
-func (s *Service) ListProducts(ctx context.Context, page, pageSize int, category string) ([]*Product, int, error) {
+// ListProducts retrieves products with pagination and optional filters,
+// oldest first
+func (s *Service) ListProducts(ctx context.Context, query common.PageQuery, filter ListFilter) ([]*Product, common.PageInfo, error) {
 	// Set default page size if not provided
-	if pageSize <= 0 {
-		pageSize = 10
-	}
-	if pageSize > 100 {
-		pageSize = 100 // Max page size
-	}
+	query.Limit = common.PageSize(query.Limit)
 
-	// Set default page if not provided
-	if page <= 0 {
-		page = 1
-	}
+	filter.Category = strings.TrimSpace(filter.Category)
+	filter.Search = strings.TrimSpace(filter.Search)
+
+	return s.repo.List(ctx, query, filter)
+}
 
-	offset := (page - 1) * pageSize
-	return s.repo.List(ctx, offset, pageSize, category)
+// ListCategories counts the products in each category
+func (s *Service) ListCategories(ctx context.Context) ([]*CategoryCount, error) {
+	return s.repo.Categories(ctx)
 }
 
 // UpdateStock updates product stock
@@ -101,31 +145,156 @@ func (s *Service) UpdateStock(ctx context.Context, productID string, quantity in
 	return s.repo.UpdateStock(ctx, productID, quantity)
 }
 
-// validateProduct validates product input
-func (s *Service) validateProduct(name, description, category string, price float64, stock int32) error {
-	if strings.TrimSpace(name) == "" {
-		return NewValidationError("name is required")
+// BatchUpdateStock updates the stock of several products, all or none
+func (s *Service) BatchUpdateStock(ctx context.Context, changes []*StockChange) ([]*Product, error) {
+	if len(changes) == 0 {
+		return nil, NewValidationError("at least one stock change is required")
 	}
 
-	if strings.TrimSpace(description) == "" {
-		return NewValidationError("description is required")
+	if len(changes) > MaxStockChanges {
+		return nil, NewValidationError(fmt.Sprintf("at most %d stock changes are allowed", MaxStockChanges))
 	}
 
-	if strings.TrimSpace(category) == "" {
-		return NewValidationError("category is required")
+	for _, change := range changes {
+		if change.ProductID == "" {
+			return nil, NewValidationError("product ID is required for all stock changes")
+		}
 	}
 
-	if price <= 0 {
-		return NewValidationError("price must be greater than 0")
+	return s.repo.BatchUpdateStock(ctx, changes)
+}
+
+// WatchStock watches the changes of one product, or of every product when
+// productID is empty. The caller must close the subscription.
+func (s *Service) WatchStock(ctx context.Context, productID string) (*common.Subscription[*Product], error) {
+	notifier, ok := s.repo.(StockNotifier)
+	if !ok {
+		return nil, ErrWatchUnavailable
 	}
 
-	if stock < 0 {
-		return NewValidationError("stock cannot be negative")
+	if productID != "" {
+		if _, err := s.repo.GetByID(ctx, productID); err != nil {
+			return nil, err
+		}
+	}
+
+	return notifier.SubscribeStock(productID), nil
+}
+
+// ReserveStock holds stock of a product for ttl, or the default TTL when ttl is 0
+func (s *Service) ReserveStock(ctx context.Context, reservationID, productID string, quantity int32, ttl time.Duration) (*Reservation, error) {
+	if productID == "" {
+		return nil, ErrProductNotFound
+	}
+
+	if quantity <= 0 {
+		return nil, NewValidationError("quantity must be greater than 0")
+	}
+
+	reservationID = strings.TrimSpace(reservationID)
+	if len(reservationID) > maxReservationIDLength {
+		return nil, NewValidationError("reservation ID is too long")
+	}
+
+	if ttl < 0 {
+		return nil, NewValidationError("ttl cannot be negative")
+	}
+	if ttl == 0 {
+		ttl = DefaultReservationTTL
+	}
+	if ttl > MaxReservationTTL {
+		ttl = MaxReservationTTL
+	}
+
+	return s.repo.Reserve(ctx, &Reservation{
+		ID:        reservationID,
+		ProductID: productID,
+		Quantity:  quantity,
+		ExpiresAt: time.Now().Add(ttl),
+	})
+}
+
+// CommitReservation turns a reservation into a permanent stock reduction
+func (s *Service) CommitReservation(ctx context.Context, id string) (*Reservation, error) {
+	if id == "" {
+		return nil, ErrReservationNotFound
+	}
+	return s.repo.CommitReservation(ctx, id)
+}
+
+// ReleaseReservation gives a reservation's stock back
+func (s *Service) ReleaseReservation(ctx context.Context, id string) (*Reservation, error) {
+	if id == "" {
+		return nil, ErrReservationNotFound
+	}
+	return s.repo.ReleaseReservation(ctx, id)
+}
+
+// validateProduct validates product input
+func (s *Service) validateProduct(name, description, category string, price float64, stock int32) error {
+	product := &Product{
+		Name:        name,
+		Description: description,
+		Category:    category,
+		Price:       price,
+		Stock:       stock,
+	}
+	return s.validateFields(product, UpdatableFields)
+}
+
+// validateFields validates the listed fields of product input
+func (s *Service) validateFields(product *Product, fields []string) error {
+	for _, field := range fields {
+		switch field {
+		case FieldName:
+			if strings.TrimSpace(product.Name) == "" {
+				return NewValidationError("name is required")
+			}
+		case FieldDescription:
+			if strings.TrimSpace(product.Description) == "" {
+				return NewValidationError("description is required")
+			}
+		case FieldCategory:
+			if strings.TrimSpace(product.Category) == "" {
+				return NewValidationError("category is required")
+			}
+		case FieldPrice:
+			if product.Price <= 0 {
+				return NewValidationError("price must be greater than 0")
+			}
+		case FieldStock:
+			if product.Stock < 0 {
+				return NewValidationError("stock cannot be negative")
+			}
+		default:
+			return unknownFieldError(field)
+		}
 	}
 
 	return nil
 }
 
+// normalizeFields checks that fields lists at least one field, all known, and
+// drops repeats
+func normalizeFields(fields []string) ([]string, error) {
+	if len(fields) == 0 {
+		return nil, NewValidationError("at least one field to update is required")
+	}
+
+	seen := make(map[string]bool, len(fields))
+	normalized := make([]string, 0, len(fields))
+	for _, field := range fields {
+		if !slices.Contains(UpdatableFields, field) {
+			return nil, unknownFieldError(field)
+		}
+		if !seen[field] {
+			seen[field] = true
+			normalized = append(normalized, field)
+		}
+	}
+	return normalized, nil
+}
+
 // ValidationError represents a validation error
 type ValidationError struct {
 	Message string
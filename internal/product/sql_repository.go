@@ -0,0 +1,613 @@
+package product
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"learning/internal/common"
+)
+
+const productColumns = "id, name, description, price, stock, reserved, category, created_at, updated_at"
+
+const reservationColumns = "id, product_id, quantity, status, expires_at, created_at, updated_at"
+
+// rowQuerier is satisfied by both the database and a transaction
+type rowQuerier interface {
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// SQLRepository implements Repository interface using a SQL database
+type SQLRepository struct {
+	db *common.Database
+}
+
+// NewSQLRepository creates a new SQL repository.
+// The schema is managed by the migrations package.
+func NewSQLRepository(db *common.Database) *SQLRepository {
+	return &SQLRepository{db: db}
+}
+
+// Create creates a new product
+func (r *SQLRepository) Create(ctx context.Context, product *Product) (*Product, error) {
+	// Generate ID if not provided
+	if product.ID == "" {
+		product.ID = uuid.New().String()
+	}
+
+	// Set timestamps
+	now := time.Now().UTC()
+	product.CreatedAt = now
+	product.UpdatedAt = now
+
+	_, err := r.db.ExecContext(ctx, r.db.Rebind(
+		"INSERT INTO products ("+productColumns+") VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)"),
+		product.ID, product.Name, product.Description, product.Price, product.Stock, 0,
+		product.Category, product.CreatedAt, product.UpdatedAt,
+	)
+	if err != nil {
+		if common.IsUniqueViolation(err) {
+			return nil, ErrProductAlreadyExists
+		}
+		return nil, fmt.Errorf("failed to insert product: %w", err)
+	}
+
+	return product, nil
+}
+
+// GetByID retrieves a product by ID
+func (r *SQLRepository) GetByID(ctx context.Context, id string) (*Product, error) {
+	row := r.db.QueryRowContext(ctx, r.db.Rebind(
+		"SELECT "+productColumns+" FROM products WHERE id = ?"), id)
+	return scanProduct(row)
+}
+
+// GetByIDs retrieves the products with the given IDs in the order the IDs were
+// requested, each at most once, along with the IDs that matched no product
+func (r *SQLRepository) GetByIDs(ctx context.Context, ids []string) ([]*Product, []string, error) {
+	if len(ids) == 0 {
+		return []*Product{}, nil, nil
+	}
+
+	args := make([]any, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+
+	rows, err := r.db.QueryContext(ctx, r.db.Rebind(
+		"SELECT "+productColumns+" FROM products WHERE id IN ("+common.Placeholders(len(ids))+")"),
+		args...,
+	)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get products: %w", err)
+	}
+	defer rows.Close()
+
+	byID := make(map[string]*Product, len(ids))
+	for rows.Next() {
+		product, err := scanProduct(rows)
+		if err != nil {
+			return nil, nil, err
+		}
+		byID[product.ID] = product
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("failed to get products: %w", err)
+	}
+
+	// Rows come back in no particular order; restore the requested one
+	found := make([]*Product, 0, len(byID))
+	var notFound []string
+	seen := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		if product, exists := byID[id]; exists {
+			found = append(found, product)
+		} else {
+			notFound = append(notFound, id)
+		}
+	}
+
+	return found, notFound, nil
+}
+
+// Update updates an existing product
+func (r *SQLRepository) Update(ctx context.Context, product *Product) (*Product, error) {
+	product.UpdatedAt = time.Now().UTC()
+
+	row := r.db.QueryRowContext(ctx, r.db.Rebind(
+		"UPDATE products SET name = ?, description = ?, price = ?, stock = ?, category = ?, updated_at = ? "+
+			"WHERE id = ? AND ? >= reserved RETURNING "+productColumns),
+		product.Name, product.Description, product.Price, product.Stock,
+		product.Category, product.UpdatedAt, product.ID, product.Stock,
+	)
+
+	updated, err := scanProduct(row)
+	if err != ErrProductNotFound {
+		return updated, err
+	}
+
+	// Nothing was updated: the new stock may be below what is reserved
+	if err := r.checkExists(ctx, r.db, product.ID); err != nil {
+		return nil, err
+	}
+	return nil, ErrInsufficientStock
+}
+
+// Patch changes only the listed fields of a stored product to their values
+// in product
+func (r *SQLRepository) Patch(ctx context.Context, product *Product, fields []string) (*Product, error) {
+	var set []string
+	var args []any
+	where := " WHERE id = ?"
+	for _, field := range fields {
+		switch field {
+		case FieldName:
+			args = append(args, product.Name)
+		case FieldDescription:
+			args = append(args, product.Description)
+		case FieldCategory:
+			args = append(args, product.Category)
+		case FieldPrice:
+			args = append(args, product.Price)
+		case FieldStock:
+			args = append(args, product.Stock)
+			where += " AND ? >= reserved"
+		default:
+			return nil, unknownFieldError(field)
+		}
+		set = append(set, field+" = ?")
+	}
+
+	args = append(args, time.Now().UTC(), product.ID)
+	if slices.Contains(fields, FieldStock) {
+		args = append(args, product.Stock)
+	}
+
+	row := r.db.QueryRowContext(ctx, r.db.Rebind(
+		"UPDATE products SET "+strings.Join(append(set, "updated_at = ?"), ", ")+where+" RETURNING "+productColumns),
+		args...,
+	)
+
+	updated, err := scanProduct(row)
+	if err != ErrProductNotFound {
+		return updated, err
+	}
+
+	// Nothing was updated: the new stock may be below what is reserved
+	if err := r.checkExists(ctx, r.db, product.ID); err != nil {
+		return nil, err
+	}
+	return nil, ErrInsufficientStock
+}
+
+// Delete deletes a product by ID
+func (r *SQLRepository) Delete(ctx context.Context, id string) error {
+	result, err := r.db.ExecContext(ctx, r.db.Rebind("DELETE FROM products WHERE id = ?"), id)
+	if err != nil {
+		return fmt.Errorf("failed to delete product: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to delete product: %w", err)
+	}
+	if affected == 0 {
+		return ErrProductNotFound
+	}
+
+	return nil
+}
+
+// List retrieves products with pagination and optional filters, oldest first
+func (r *SQLRepository) List(ctx context.Context, query common.PageQuery, filter ListFilter) ([]*Product, common.PageInfo, error) {
+	var conditions []string
+	var args []any
+	if filter.Category != "" {
+		conditions = append(conditions, "category = ?")
+		args = append(args, filter.Category)
+	}
+	if filter.Search != "" {
+		conditions = append(conditions, "(LOWER(name) LIKE ? ESCAPE '\\' OR LOWER(description) LIKE ? ESCAPE '\\')")
+		pattern := "%" + common.EscapeLike(strings.ToLower(filter.Search)) + "%"
+		args = append(args, pattern, pattern)
+	}
+	if filter.InStock != nil {
+		if *filter.InStock {
+			conditions = append(conditions, "stock > reserved")
+		} else {
+			conditions = append(conditions, "stock <= reserved")
+		}
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	products := []*Product{}
+	info, err := r.db.ListPage(ctx, "products", productColumns, where, args, query, func(rows *sql.Rows) error {
+		product, err := scanProduct(rows)
+		if err != nil {
+			return err
+		}
+		products = append(products, product)
+		return nil
+	})
+	if err != nil {
+		return nil, common.PageInfo{}, err
+	}
+
+	return products, info, nil
+}
+
+// Categories counts the products in each category, ordered by name
+func (r *SQLRepository) Categories(ctx context.Context) ([]*CategoryCount, error) {
+	rows, err := r.db.QueryContext(ctx,
+		"SELECT category, COUNT(*) FROM products GROUP BY category ORDER BY category")
+	if err != nil {
+		return nil, fmt.Errorf("failed to count categories: %w", err)
+	}
+	defer rows.Close()
+
+	categories := []*CategoryCount{}
+	for rows.Next() {
+		var category CategoryCount
+		if err := rows.Scan(&category.Name, &category.Count); err != nil {
+			return nil, fmt.Errorf("failed to scan category: %w", err)
+		}
+		categories = append(categories, &category)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to count categories: %w", err)
+	}
+
+	return categories, nil
+}
+
+// UpdateStock updates product stock.
+// The change is a single conditional UPDATE, so concurrent callers can never
+// drive stock below what active reservations hold.
+func (r *SQLRepository) UpdateStock(ctx context.Context, productID string, quantity int32) (*Product, error) {
+	row := r.db.QueryRowContext(ctx, r.db.Rebind(
+		"UPDATE products SET stock = stock + ?, updated_at = ? "+
+			"WHERE id = ? AND stock + ? >= reserved RETURNING "+productColumns),
+		quantity, time.Now().UTC(), productID, quantity,
+	)
+
+	product, err := scanProduct(row)
+	if err != ErrProductNotFound {
+		return product, err
+	}
+
+	// Nothing was updated: tell a missing product apart from insufficient stock
+	if err := r.checkExists(ctx, r.db, productID); err != nil {
+		return nil, err
+	}
+
+	return nil, ErrInsufficientStock
+}
+
+// BatchUpdateStock applies every change or, if any of them fails, none of
+// them; the failures are reported in a *BatchStockError. It returns the
+// resulting product for each change, in order.
+func (r *SQLRepository) BatchUpdateStock(ctx context.Context, changes []*StockChange) ([]*Product, error) {
+	// Update rows in product ID order so concurrent batches can't deadlock
+	indexes := make([]int, len(changes))
+	for i := range indexes {
+		indexes[i] = i
+	}
+	sort.SliceStable(indexes, func(a, b int) bool {
+		return changes[indexes[a]].ProductID < changes[indexes[b]].ProductID
+	})
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now().UTC()
+	updated := make(map[string]*Product)
+	var failures []*StockChangeFailure
+
+	for _, i := range indexes {
+		change := changes[i]
+
+		// Same conditional update as UpdateStock; the row stays locked until
+		// the transaction ends
+		row := tx.QueryRowContext(ctx, r.db.Rebind(
+			"UPDATE products SET stock = stock + ?, updated_at = ? "+
+				"WHERE id = ? AND stock + ? >= reserved RETURNING "+productColumns),
+			change.Quantity, now, change.ProductID, change.Quantity,
+		)
+
+		product, err := scanProduct(row)
+		if err == ErrProductNotFound {
+			// Nothing was updated: tell a missing product apart from insufficient stock
+			cause := r.checkExists(ctx, tx, change.ProductID)
+			if cause == nil {
+				cause = ErrInsufficientStock
+			} else if cause != ErrProductNotFound {
+				return nil, cause
+			}
+			failures = append(failures, &StockChangeFailure{Index: i, ProductID: change.ProductID, Err: cause})
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to update stock: %w", err)
+		}
+		updated[product.ID] = product
+	}
+
+	if len(failures) > 0 {
+		sort.Slice(failures, func(a, b int) bool { return failures[a].Index < failures[b].Index })
+		return nil, &BatchStockError{Failures: failures}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit stock update: %w", err)
+	}
+
+	results := make([]*Product, len(changes))
+	for i, change := range changes {
+		result := *updated[change.ProductID]
+		results[i] = &result
+	}
+	return results, nil
+}
+
+// Reserve holds stock of a product until the reservation expires.
+// Reserving with the ID of an existing reservation returns it unchanged, so
+// callers can safely retry, provided it is for the same product and quantity.
+func (r *SQLRepository) Reserve(ctx context.Context, reservation *Reservation) (*Reservation, error) {
+	if reservation.ID != "" {
+		existing, err := r.getReservation(ctx, r.db, reservation.ID)
+		if err == nil {
+			return reservation.checkRetry(existing)
+		}
+		if err != ErrReservationNotFound {
+			return nil, err
+		}
+	} else {
+		reservation.ID = uuid.New().String()
+	}
+
+	now := time.Now().UTC()
+	reservation.Status = ReservationActive
+	reservation.ExpiresAt = reservation.ExpiresAt.UTC()
+	reservation.CreatedAt = now
+	reservation.UpdatedAt = now
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Conditional update, like UpdateStock, so reservations never overbook
+	result, err := tx.ExecContext(ctx, r.db.Rebind(
+		"UPDATE products SET reserved = reserved + ?, updated_at = ? WHERE id = ? AND stock - reserved >= ?"),
+		reservation.Quantity, now, reservation.ProductID, reservation.Quantity,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reserve stock: %w", err)
+	}
+	if affected, err := result.RowsAffected(); err != nil {
+		return nil, fmt.Errorf("failed to reserve stock: %w", err)
+	} else if affected == 0 {
+		if err := r.checkExists(ctx, tx, reservation.ProductID); err != nil {
+			return nil, err
+		}
+		return nil, ErrInsufficientStock
+	}
+
+	_, err = tx.ExecContext(ctx, r.db.Rebind(
+		"INSERT INTO stock_reservations ("+reservationColumns+") VALUES (?, ?, ?, ?, ?, ?, ?)"),
+		reservation.ID, reservation.ProductID, reservation.Quantity, reservation.Status,
+		reservation.ExpiresAt, reservation.CreatedAt, reservation.UpdatedAt,
+	)
+	if err != nil {
+		if common.IsUniqueViolation(err) {
+			// Lost a race with a retry of the same reservation
+			tx.Rollback()
+			existing, err := r.getReservation(ctx, r.db, reservation.ID)
+			if err != nil {
+				return nil, err
+			}
+			return reservation.checkRetry(existing)
+		}
+		return nil, fmt.Errorf("failed to insert reservation: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit reservation: %w", err)
+	}
+
+	return reservation, nil
+}
+
+// CommitReservation turns a reservation into a permanent stock reduction.
+// Committing an already committed reservation returns it unchanged.
+func (r *SQLRepository) CommitReservation(ctx context.Context, id string) (*Reservation, error) {
+	return r.closeReservation(ctx, id, ReservationCommitted)
+}
+
+// ReleaseReservation gives a reservation's stock back.
+// Releasing an already released or expired reservation returns it unchanged.
+func (r *SQLRepository) ReleaseReservation(ctx context.Context, id string) (*Reservation, error) {
+	return r.closeReservation(ctx, id, ReservationReleased)
+}
+
+// ExpireReservations expires active reservations past their TTL and forgets
+// closed ones older than the retention period
+func (r *SQLRepository) ExpireReservations(ctx context.Context, now time.Time) ([]*Reservation, error) {
+	now = now.UTC()
+
+	rows, err := r.db.QueryContext(ctx, r.db.Rebind(
+		"SELECT id FROM stock_reservations WHERE status = ? AND expires_at <= ?"),
+		ReservationActive, now,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find expired reservations: %w", err)
+	}
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan reservation: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to find expired reservations: %w", err)
+	}
+
+	var expired []*Reservation
+	for _, id := range ids {
+		reservation, err := r.closeReservationAt(ctx, id, ReservationExpired, now)
+		if err == ErrReservationClosed || err == ErrReservationNotFound {
+			continue // committed or released meanwhile
+		}
+		if err != nil {
+			return expired, err
+		}
+		if reservation.Status == ReservationExpired {
+			expired = append(expired, reservation)
+		}
+	}
+
+	_, err = r.db.ExecContext(ctx, r.db.Rebind(
+		"DELETE FROM stock_reservations WHERE status <> ? AND updated_at < ?"),
+		ReservationActive, now.Add(-ReservationRetention),
+	)
+	if err != nil {
+		return expired, fmt.Errorf("failed to purge reservations: %w", err)
+	}
+
+	return expired, nil
+}
+
+// closeReservation moves an active reservation to a final status
+func (r *SQLRepository) closeReservation(ctx context.Context, id string, status ReservationStatus) (*Reservation, error) {
+	return r.closeReservationAt(ctx, id, status, time.Now().UTC())
+}
+
+// closeReservationAt moves an active reservation to a final status and
+// adjusts the product's stock in one transaction
+func (r *SQLRepository) closeReservationAt(ctx context.Context, id string, status ReservationStatus, now time.Time) (*Reservation, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Only one caller can win the ACTIVE -> final status change
+	query := "UPDATE stock_reservations SET status = ?, updated_at = ? WHERE id = ? AND status = ?"
+	args := []any{status, now, id, ReservationActive}
+	if status == ReservationCommitted {
+		query += " AND expires_at > ?"
+		args = append(args, now)
+	}
+
+	row := tx.QueryRowContext(ctx, r.db.Rebind(query+" RETURNING "+reservationColumns), args...)
+	reservation, err := scanReservation(row)
+	if err == ErrReservationNotFound {
+		// Nothing changed: missing, expired, or already closed
+		existing, err := r.getReservation(ctx, tx, id)
+		if err != nil {
+			return nil, err
+		}
+		if existing.Status == status || (status == ReservationReleased && existing.Status == ReservationExpired) {
+			return existing, nil
+		}
+		return nil, ErrReservationClosed
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	stockChange := int32(0)
+	if status == ReservationCommitted {
+		stockChange = -reservation.Quantity
+	}
+	_, err = tx.ExecContext(ctx, r.db.Rebind(
+		"UPDATE products SET stock = stock + ?, reserved = reserved - ?, updated_at = ? WHERE id = ?"),
+		stockChange, reservation.Quantity, now, reservation.ProductID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update reserved stock: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit reservation change: %w", err)
+	}
+
+	return reservation, nil
+}
+
+// getReservation retrieves a reservation by ID
+func (r *SQLRepository) getReservation(ctx context.Context, q rowQuerier, id string) (*Reservation, error) {
+	row := q.QueryRowContext(ctx, r.db.Rebind(
+		"SELECT "+reservationColumns+" FROM stock_reservations WHERE id = ?"), id)
+	return scanReservation(row)
+}
+
+// checkExists returns ErrProductNotFound unless the product exists
+func (r *SQLRepository) checkExists(ctx context.Context, q rowQuerier, id string) error {
+	var exists int
+	err := q.QueryRowContext(ctx, r.db.Rebind("SELECT 1 FROM products WHERE id = ?"), id).Scan(&exists)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrProductNotFound
+		}
+		return fmt.Errorf("failed to check product: %w", err)
+	}
+	return nil
+}
+
+// scanProduct reads a product from a row selected with productColumns
+func scanProduct(row interface{ Scan(...any) error }) (*Product, error) {
+	var product Product
+	err := row.Scan(
+		&product.ID, &product.Name, &product.Description, &product.Price,
+		&product.Stock, &product.Reserved, &product.Category, &product.CreatedAt, &product.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrProductNotFound
+		}
+		return nil, err
+	}
+	return &product, nil
+}
+
+// scanReservation reads a reservation from a row selected with reservationColumns
+func scanReservation(row interface{ Scan(...any) error }) (*Reservation, error) {
+	var reservation Reservation
+	err := row.Scan(
+		&reservation.ID, &reservation.ProductID, &reservation.Quantity, &reservation.Status,
+		&reservation.ExpiresAt, &reservation.CreatedAt, &reservation.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrReservationNotFound
+		}
+		return nil, err
+	}
+	return &reservation, nil
+}
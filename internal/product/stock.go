@@ -0,0 +1,37 @@
+package product
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MaxStockChanges caps the number of changes in one batch stock update
+const MaxStockChanges = 1000
+
+// StockChange adjusts the stock of one product by Quantity, positive to add
+// and negative to reduce
+type StockChange struct {
+	ProductID string
+	Quantity  int32
+}
+
+// StockChangeFailure reports why one change of a batch could not be applied
+type StockChangeFailure struct {
+	Index     int // position of the change in the batch
+	ProductID string
+	Err       error // ErrProductNotFound or ErrInsufficientStock
+}
+
+// BatchStockError is returned when a batch stock update was rejected.
+// None of the batch's changes are applied in that case.
+type BatchStockError struct {
+	Failures []*StockChangeFailure
+}
+
+func (e *BatchStockError) Error() string {
+	parts := make([]string, len(e.Failures))
+	for i, failure := range e.Failures {
+		parts[i] = fmt.Sprintf("change %d (product %s): %v", failure.Index, failure.ProductID, failure.Err)
+	}
+	return "stock update rejected: " + strings.Join(parts, "; ")
+}
@@ -0,0 +1,261 @@
+package user
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"learning/internal/auth"
+)
+
+var (
+	ErrInvalidCredentials  = errors.New("invalid email or password")
+	ErrInvalidRefreshToken = errors.New("invalid refresh token")
+	// ErrTokensUnavailable is returned when the service has no key to sign
+	// access tokens with
+	ErrTokensUnavailable = errors.New("token signing is not configured")
+)
+
+// Tokens are what a login is carried on with: a short lived access token
+// to call the services with, and a refresh token to get new tokens with
+// once it expires
+type Tokens struct {
+	AccessToken           string
+	AccessTokenExpiresAt  time.Time
+	RefreshToken          string
+	RefreshTokenExpiresAt time.Time
+}
+
+// Authenticator logs users in with their passwords and issues their tokens.
+// Access tokens are signed, so services verify them offline; refresh tokens
+// are random, and name a session stored here. Each refresh token is used up
+// by trading it for new tokens.
+type Authenticator struct {
+	service    *Service
+	repo       Repository
+	sessions   SessionStore
+	signer     *auth.Signer
+	accessTTL  time.Duration
+	refreshTTL time.Duration
+}
+
+// NewAuthenticator creates an authenticator signing access tokens valid for
+// accessTTL with signer, which may be nil if tokens can't be issued, and
+// ending sessions whose refresh token goes unused for refreshTTL
+func NewAuthenticator(service *Service, repo Repository, sessions SessionStore, signer *auth.Signer, accessTTL, refreshTTL time.Duration) *Authenticator {
+	return &Authenticator{
+		service:    service,
+		repo:       repo,
+		sessions:   sessions,
+		signer:     signer,
+		accessTTL:  accessTTL,
+		refreshTTL: refreshTTL,
+	}
+}
+
+// Register creates a USER with a password and logs them in
+func (a *Authenticator) Register(ctx context.Context, name, email, phone, password string) (*User, *Tokens, error) {
+	if a.signer == nil {
+		return nil, nil, ErrTokensUnavailable
+	}
+	if password == "" {
+		return nil, nil, NewValidationError("password is required")
+	}
+
+	user, err := a.service.CreateAccount(ctx, name, email, phone, password, auth.RoleUser)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tokens, err := a.startSession(ctx, user)
+	if err != nil {
+		return nil, nil, err
+	}
+	return user, tokens, nil
+}
+
+// Login checks a user's email and password and starts a session
+func (a *Authenticator) Login(ctx context.Context, email, password string) (*User, *Tokens, error) {
+	if a.signer == nil {
+		return nil, nil, ErrTokensUnavailable
+	}
+
+	user, err := a.repo.GetByEmail(ctx, strings.ToLower(strings.TrimSpace(email)))
+	if err != nil && !errors.Is(err, ErrUserNotFound) {
+		return nil, nil, err
+	}
+	if user == nil || user.PasswordHash == "" {
+		checkNoPassword(password)
+		return nil, nil, ErrInvalidCredentials
+	}
+	if !CheckPassword(user.PasswordHash, password) {
+		return nil, nil, ErrInvalidCredentials
+	}
+
+	tokens, err := a.startSession(ctx, user)
+	if err != nil {
+		return nil, nil, err
+	}
+	return user, tokens, nil
+}
+
+// RefreshToken trades a refresh token for new tokens, extending its
+// session. A refresh token that was already used ends its session: it only
+// turns up again when it leaked, and whoever holds the current one can't be
+// told apart from whoever stole it.
+func (a *Authenticator) RefreshToken(ctx context.Context, refreshToken string) (*Tokens, error) {
+	if a.signer == nil {
+		return nil, ErrTokensUnavailable
+	}
+
+	sessionID, ok := parseRefreshToken(refreshToken)
+	if !ok {
+		return nil, ErrInvalidRefreshToken
+	}
+	session, err := a.sessions.Get(ctx, sessionID)
+	if err != nil {
+		if errors.Is(err, ErrSessionNotFound) {
+			return nil, ErrInvalidRefreshToken
+		}
+		return nil, err
+	}
+
+	tokenHash := hashRefreshToken(refreshToken)
+	if subtle.ConstantTimeCompare([]byte(session.TokenHash), []byte(tokenHash)) != 1 || !time.Now().Before(session.ExpiresAt) {
+		return nil, a.endSession(ctx, sessionID)
+	}
+
+	// Roles are read again, so changes apply from the next refresh
+	user, err := a.repo.GetByID(ctx, session.UserID)
+	if err != nil {
+		if errors.Is(err, ErrUserNotFound) {
+			return nil, a.endSession(ctx, sessionID)
+		}
+		return nil, err
+	}
+
+	newToken, err := newRefreshToken(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	expiresAt := time.Now().Add(a.refreshTTL)
+	if err := a.sessions.Rotate(ctx, sessionID, tokenHash, hashRefreshToken(newToken), expiresAt); err != nil {
+		if errors.Is(err, ErrSessionNotFound) {
+			// Another refresh with the same token got there first
+			return nil, a.endSession(ctx, sessionID)
+		}
+		return nil, err
+	}
+
+	return a.issue(user, newToken, expiresAt)
+}
+
+// Logout ends the session a refresh token belongs to; logging out of an
+// ended session is not an error. Only the session's current token is
+// accepted: a rotated one can't be told apart from a forged one, and the
+// session is left alone for either.
+func (a *Authenticator) Logout(ctx context.Context, refreshToken string) error {
+	sessionID, ok := parseRefreshToken(refreshToken)
+	if !ok {
+		return ErrInvalidRefreshToken
+	}
+	session, err := a.sessions.Get(ctx, sessionID)
+	if err != nil {
+		if errors.Is(err, ErrSessionNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	if subtle.ConstantTimeCompare([]byte(session.TokenHash), []byte(hashRefreshToken(refreshToken))) != 1 {
+		return ErrInvalidRefreshToken
+	}
+	return a.sessions.Delete(ctx, sessionID)
+}
+
+// startSession stores a new session for user and issues its first tokens
+func (a *Authenticator) startSession(ctx context.Context, user *User) (*Tokens, error) {
+	sessionID := uuid.New().String()
+	refreshToken, err := newRefreshToken(sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	session := &Session{
+		ID:        sessionID,
+		UserID:    user.ID,
+		TokenHash: hashRefreshToken(refreshToken),
+		ExpiresAt: now.Add(a.refreshTTL),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := a.sessions.Create(ctx, session); err != nil {
+		return nil, err
+	}
+
+	return a.issue(user, refreshToken, session.ExpiresAt)
+}
+
+// endSession deletes a session whose refresh token can't be accepted and
+// returns ErrInvalidRefreshToken
+func (a *Authenticator) endSession(ctx context.Context, sessionID string) error {
+	if err := a.sessions.Delete(ctx, sessionID); err != nil {
+		return err
+	}
+	return ErrInvalidRefreshToken
+}
+
+// issue signs an access token for user to go with a refresh token
+func (a *Authenticator) issue(user *User, refreshToken string, refreshExpiresAt time.Time) (*Tokens, error) {
+	accessToken, claims, err := a.signer.Sign(user.ID, user.roles(), a.accessTTL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign access token: %w", err)
+	}
+
+	return &Tokens{
+		AccessToken:           accessToken,
+		AccessTokenExpiresAt:  claims.ExpiresAt,
+		RefreshToken:          refreshToken,
+		RefreshTokenExpiresAt: refreshExpiresAt,
+	}, nil
+}
+
+// refreshSecretLength is the number of random bytes in a refresh token
+const refreshSecretLength = 32
+
+// newRefreshToken creates a refresh token for a session: the session ID and
+// a random secret
+func newRefreshToken(sessionID string) (string, error) {
+	secret := make([]byte, refreshSecretLength)
+	if _, err := rand.Read(secret); err != nil {
+		return "", fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+	return sessionID + "." + base64.RawURLEncoding.EncodeToString(secret), nil
+}
+
+// parseRefreshToken returns the session ID of a refresh token
+func parseRefreshToken(token string) (string, bool) {
+	sessionID, secret, ok := strings.Cut(token, ".")
+	if !ok || sessionID == "" || secret == "" {
+		return "", false
+	}
+	return sessionID, true
+}
+
+// hashRefreshToken hashes a refresh token for storage. The secret is random
+// and long, so a plain hash is enough to keep stored hashes from being used
+// as tokens.
+func hashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,103 @@
+package user
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"learning/internal/common"
+)
+
+const (
+	opPut    = "put"
+	opDelete = "delete"
+)
+
+// journalRecord is a single logged change to the user set.
+// Records carry the whole user so replaying one twice is harmless.
+type journalRecord struct {
+	Op   string `json:"op"`
+	User *User  `json:"user,omitempty"`
+	ID   string `json:"id,omitempty"`
+}
+
+// NewFileBackedRepository creates an in-memory repository whose contents are
+// persisted to a snapshot and append-only log inside dataDir
+func NewFileBackedRepository(dataDir string) (*InMemoryRepository, error) {
+	journal, err := common.OpenJournal(dataDir, "users", common.DefaultCompactEvery)
+	if err != nil {
+		return nil, err
+	}
+
+	repo := NewInMemoryRepository()
+	err = journal.Replay(
+		func(data []byte) error {
+			return json.Unmarshal(data, &repo.users)
+		},
+		func(data []byte) error {
+			var record journalRecord
+			if err := json.Unmarshal(data, &record); err != nil {
+				return err
+			}
+			return repo.apply(record)
+		},
+	)
+	if err != nil {
+		journal.Close()
+		return nil, err
+	}
+
+	repo.journal = journal
+	log.Printf("Loaded %d users from %s", len(repo.users), dataDir)
+	return repo, nil
+}
+
+// Close closes the journal of a file-backed repository
+func (r *InMemoryRepository) Close() error {
+	if r.journal == nil {
+		return nil
+	}
+	return r.journal.Close()
+}
+
+// apply replays a journal record against the user map
+func (r *InMemoryRepository) apply(record journalRecord) error {
+	switch record.Op {
+	case opPut:
+		if record.User == nil {
+			return fmt.Errorf("put record without user")
+		}
+		r.users[record.User.ID] = record.User
+	case opDelete:
+		delete(r.users, record.ID)
+	default:
+		return fmt.Errorf("unknown journal op %q", record.Op)
+	}
+	return nil
+}
+
+// persist logs a change before it is applied to the map.
+// The caller must hold the write lock.
+func (r *InMemoryRepository) persist(record journalRecord) error {
+	if r.journal == nil {
+		return nil
+	}
+
+	if err := r.journal.Append(record); err != nil {
+		return fmt.Errorf("failed to persist user: %w", err)
+	}
+	return nil
+}
+
+// compact writes a fresh snapshot once the log is long enough.
+// The caller must hold the write lock and have applied the latest change.
+func (r *InMemoryRepository) compact() {
+	if r.journal == nil || !r.journal.NeedsCompaction() {
+		return
+	}
+
+	if err := r.journal.Compact(r.users); err != nil {
+		// The log still holds every change, so this only delays compaction
+		log.Printf("Failed to compact user journal: %v", err)
+	}
+}
@@ -0,0 +1,203 @@
+package user
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+
+	"learning/internal/auth"
+	"learning/internal/common"
+	pb "learning/pkg/user/pb"
+)
+
+// GRPCRepository implements Repository on top of the user service gRPC API.
+// It lets other processes (e.g. the API gateway) share the user service's data.
+type GRPCRepository struct {
+	client pb.UserServiceClient
+}
+
+// NewGRPCRepository creates a repository backed by a user service connection
+func NewGRPCRepository(conn grpc.ClientConnInterface) *GRPCRepository {
+	return &GRPCRepository{
+		client: pb.NewUserServiceClient(conn),
+	}
+}
+
+// Create creates a new user
+func (r *GRPCRepository) Create(ctx context.Context, user *User) (*User, error) {
+	resp, err := r.client.CreateUser(ctx, &pb.CreateUserRequest{
+		Name:  user.Name,
+		Email: user.Email,
+		Phone: user.Phone,
+		Role:  string(user.Role),
+	})
+	if err != nil {
+		return nil, fromStatus(err)
+	}
+
+	return protoToUser(resp.User), nil
+}
+
+// GetByID retrieves a user by ID
+func (r *GRPCRepository) GetByID(ctx context.Context, id string) (*User, error) {
+	resp, err := r.client.GetUser(ctx, &pb.GetUserRequest{Id: id})
+	if err != nil {
+		return nil, fromStatus(err)
+	}
+
+	return protoToUser(resp.User), nil
+}
+
+// GetByEmail retrieves a user by email.
+// The user service has no lookup by email, so this pages through ListUsers.
+func (r *GRPCRepository) GetByEmail(ctx context.Context, email string) (*User, error) {
+	req := &pb.ListUsersRequest{PageSize: common.MaxPageSize}
+
+	for {
+		resp, err := r.client.ListUsers(ctx, req)
+		if err != nil {
+			return nil, fromStatus(err)
+		}
+
+		for _, u := range resp.Users {
+			if u.Email == email {
+				return protoToUser(u), nil
+			}
+		}
+
+		if resp.NextPageToken == "" {
+			return nil, ErrUserNotFound
+		}
+		req.PageToken = resp.NextPageToken
+	}
+}
+
+// GetByIDs retrieves several users by ID in one call
+func (r *GRPCRepository) GetByIDs(ctx context.Context, ids []string) ([]*User, []string, error) {
+	resp, err := r.client.BatchGetUsers(ctx, &pb.BatchGetUsersRequest{Ids: ids})
+	if err != nil {
+		return nil, nil, fromStatus(err)
+	}
+
+	users := make([]*User, len(resp.Users))
+	for i, u := range resp.Users {
+		users[i] = protoToUser(u)
+	}
+
+	return users, resp.NotFoundIds, nil
+}
+
+// Update updates an existing user
+func (r *GRPCRepository) Update(ctx context.Context, user *User) (*User, error) {
+	resp, err := r.client.UpdateUser(ctx, &pb.UpdateUserRequest{
+		Id:    user.ID,
+		Name:  user.Name,
+		Email: user.Email,
+		Phone: user.Phone,
+	})
+	if err != nil {
+		return nil, fromStatus(err)
+	}
+
+	return protoToUser(resp.User), nil
+}
+
+// Patch changes only the listed fields of a user through the user service
+func (r *GRPCRepository) Patch(ctx context.Context, user *User, fields []string) (*User, error) {
+	resp, err := r.client.UpdateUser(ctx, &pb.UpdateUserRequest{
+		Id:         user.ID,
+		Name:       user.Name,
+		Email:      user.Email,
+		Phone:      user.Phone,
+		UpdateMask: &fieldmaskpb.FieldMask{Paths: fields},
+	})
+	if err != nil {
+		return nil, fromStatus(err)
+	}
+
+	return protoToUser(resp.User), nil
+}
+
+// Delete deletes a user by ID
+func (r *GRPCRepository) Delete(ctx context.Context, id string) error {
+	_, err := r.client.DeleteUser(ctx, &pb.DeleteUserRequest{Id: id})
+	if err != nil {
+		return fromStatus(err)
+	}
+	return nil
+}
+
+// List retrieves users with pagination.
+// An offset is converted to a page number, so it should be a multiple of the
+// limit; cursors are sent as a page token.
+func (r *GRPCRepository) List(ctx context.Context, query common.PageQuery) ([]*User, common.PageInfo, error) {
+	if query.Limit <= 0 {
+		return []*User{}, common.PageInfo{}, nil
+	}
+
+	req := &pb.ListUsersRequest{PageSize: int32(query.Limit), SkipTotal: query.SkipTotal}
+	if query.IsKeyset() {
+		req.PageToken = query.PageToken()
+	} else {
+		req.Page = int32(query.Offset/query.Limit + 1)
+	}
+
+	resp, err := r.client.ListUsers(ctx, req)
+	if err != nil {
+		return nil, common.PageInfo{}, fromStatus(err)
+	}
+
+	users := make([]*User, len(resp.Users))
+	for i, u := range resp.Users {
+		users[i] = protoToUser(u)
+	}
+
+	return users, common.PageInfo{
+		Total:       int(resp.Total),
+		HasPrevious: resp.PreviousPageToken != "",
+		HasNext:     resp.NextPageToken != "",
+	}, nil
+}
+
+// protoToUser converts protobuf user to domain user
+func protoToUser(u *pb.User) *User {
+	if u == nil {
+		return nil
+	}
+
+	return &User{
+		ID:        u.Id,
+		Name:      u.Name,
+		Email:     u.Email,
+		Phone:     u.Phone,
+		Role:      auth.Role(u.Role),
+		CreatedAt: u.CreatedAt.AsTime(),
+		UpdatedAt: u.UpdatedAt.AsTime(),
+	}
+}
+
+// fromStatus converts a gRPC status error back into the matching domain error
+func fromStatus(err error) error {
+	st, ok := status.FromError(err)
+	if !ok {
+		return err
+	}
+
+	switch st.Code() {
+	case codes.NotFound:
+		return ErrUserNotFound
+	case codes.AlreadyExists:
+		return ErrUserAlreadyExists
+	case codes.InvalidArgument:
+		return NewValidationError(st.Message())
+	case codes.Unauthenticated, codes.PermissionDenied:
+		// The caller's token or role falls short; pass the reason on as is
+		return err
+	default:
+		return fmt.Errorf("user service: %w", err)
+	}
+}
@@ -8,27 +8,32 @@ import (
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/timestamppb"
 
+	"learning/internal/auth"
+	"learning/internal/common"
 	pb "learning/pkg/user/pb"
 )
 
 // Handler implements the UserService gRPC server
 type Handler struct {
 	pb.UnimplementedUserServiceServer
-	service *Service
+	service       *Service
+	authenticator *Authenticator
 }
 
 // NewHandler creates a new gRPC handler for user service
-func NewHandler(service *Service) *Handler {
+func NewHandler(service *Service, authenticator *Authenticator) *Handler {
 	return &Handler{
-		service: service,
+		service:       service,
+		authenticator: authenticator,
 	}
 }
 
 // CreateUser creates a new user
 func (h *Handler) CreateUser(ctx context.Context, req *pb.CreateUserRequest) (*pb.CreateUserResponse, error) {
-	log.Printf("CreateUser request: %+v", req)
+	// The password is left out of the log
+	log.Printf("CreateUser request: name=%q email=%q phone=%q role=%q", req.Name, req.Email, req.Phone, req.Role)
 
-	user, err := h.service.CreateUser(ctx, req.Name, req.Email, req.Phone)
+	user, err := h.service.CreateAccount(ctx, req.Name, req.Email, req.Phone, req.Password, auth.Role(req.Role))
 	if err != nil {
 		log.Printf("CreateUser error: %v", err)
 
@@ -37,6 +42,10 @@ func (h *Handler) CreateUser(ctx context.Context, req *pb.CreateUserRequest) (*p
 			return nil, status.Error(codes.InvalidArgument, validationErr.Message)
 		}
 
+		if err == auth.ErrPermissionDenied {
+			return nil, status.Error(codes.PermissionDenied, "users can't be given a role above your own")
+		}
+
 		// Handle already exists error
 		if err == ErrUserAlreadyExists {
 			return nil, status.Error(codes.AlreadyExists, "user with this email already exists")
@@ -70,11 +79,50 @@ func (h *Handler) GetUser(ctx context.Context, req *pb.GetUserRequest) (*pb.GetU
 	}, nil
 }
 
-// UpdateUser updates an existing user
+// BatchGetUsers retrieves several users by ID
+func (h *Handler) BatchGetUsers(ctx context.Context, req *pb.BatchGetUsersRequest) (*pb.BatchGetUsersResponse, error) {
+	log.Printf("BatchGetUsers request: %d IDs", len(req.Ids))
+
+	users, notFound, err := h.service.BatchGetUsers(ctx, req.Ids)
+	if err != nil {
+		log.Printf("BatchGetUsers error: %v", err)
+
+		if validationErr, ok := err.(*ValidationError); ok {
+			return nil, status.Error(codes.InvalidArgument, validationErr.Message)
+		}
+
+		return nil, status.Error(codes.Internal, "failed to get users")
+	}
+
+	protoUsers := make([]*pb.User, len(users))
+	for i, user := range users {
+		protoUsers[i] = h.userToProto(user)
+	}
+
+	return &pb.BatchGetUsersResponse{
+		Users:       protoUsers,
+		NotFoundIds: notFound,
+	}, nil
+}
+
+// UpdateUser updates an existing user, or only the fields in the update mask
 func (h *Handler) UpdateUser(ctx context.Context, req *pb.UpdateUserRequest) (*pb.UpdateUserResponse, error) {
 	log.Printf("UpdateUser request: %+v", req)
 
-	user, err := h.service.UpdateUser(ctx, req.Id, req.Name, req.Email, req.Phone)
+	name, email, phone := req.Name, req.Email, req.Phone
+	if u := req.GetUser(); u != nil {
+		name, email, phone = u.Name, u.Email, u.Phone
+	}
+
+	var user *User
+	var err error
+	// Requests carrying a user come from PATCH, which must never replace
+	// the whole user: an empty mask fails validation instead
+	if fields := req.GetUpdateMask().GetPaths(); len(fields) > 0 || req.User != nil {
+		user, err = h.service.PatchUser(ctx, req.Id, name, email, phone, fields)
+	} else {
+		user, err = h.service.UpdateUser(ctx, req.Id, req.Name, req.Email, req.Phone)
+	}
 	if err != nil {
 		log.Printf("UpdateUser error: %v", err)
 
@@ -125,7 +173,13 @@ func (h *Handler) DeleteUser(ctx context.Context, req *pb.DeleteUserRequest) (*p
 func (h *Handler) ListUsers(ctx context.Context, req *pb.ListUsersRequest) (*pb.ListUsersResponse, error) {
 	log.Printf("ListUsers request: %+v", req)
 
-	users, total, err := h.service.ListUsers(ctx, int(req.Page), int(req.PageSize))
+	query, err := common.NewPageQuery(int(req.Page), int(req.PageSize), req.PageToken)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	query.SkipTotal = req.SkipTotal
+
+	users, info, err := h.service.ListUsers(ctx, query)
 	if err != nil {
 		log.Printf("ListUsers error: %v", err)
 		return nil, status.Error(codes.Internal, "failed to list users")
@@ -136,12 +190,16 @@ func (h *Handler) ListUsers(ctx context.Context, req *pb.ListUsersRequest) (*pb.
 		protoUsers[i] = h.userToProto(user)
 	}
 
-	return &pb.ListUsersResponse{
+	resp := &pb.ListUsersResponse{
 		Users:    protoUsers,
-		Total:    int32(total),
+		Total:    int32(info.Total),
 		Page:     req.Page,
 		PageSize: req.PageSize,
-	}, nil
+	}
+	if len(users) > 0 {
+		resp.PreviousPageToken, resp.NextPageToken = info.Tokens(users[0].Cursor(), users[len(users)-1].Cursor())
+	}
+	return resp, nil
 }
 
 // userToProto converts domain user to protobuf user
@@ -151,7 +209,110 @@ func (h *Handler) userToProto(user *User) *pb.User {
 		Name:      user.Name,
 		Email:     user.Email,
 		Phone:     user.Phone,
+		Role:      string(user.roles()[0]),
 		CreatedAt: timestamppb.New(user.CreatedAt),
 		UpdatedAt: timestamppb.New(user.UpdatedAt),
 	}
 }
+
+// Register signs up a new user
+func (h *Handler) Register(ctx context.Context, req *pb.RegisterRequest) (*pb.RegisterResponse, error) {
+	log.Printf("Register request: name=%q email=%q phone=%q", req.Name, req.Email, req.Phone)
+
+	user, tokens, err := h.authenticator.Register(ctx, req.Name, req.Email, req.Phone, req.Password)
+	if err != nil {
+		log.Printf("Register error: %v", err)
+
+		if validationErr, ok := err.(*ValidationError); ok {
+			return nil, status.Error(codes.InvalidArgument, validationErr.Message)
+		}
+
+		if err == ErrUserAlreadyExists {
+			return nil, status.Error(codes.AlreadyExists, "user with this email already exists")
+		}
+
+		return nil, h.tokenError(err, "failed to register")
+	}
+
+	return &pb.RegisterResponse{
+		User:   h.userToProto(user),
+		Tokens: h.tokensToProto(tokens),
+	}, nil
+}
+
+// Login signs a user in with email and password
+func (h *Handler) Login(ctx context.Context, req *pb.LoginRequest) (*pb.LoginResponse, error) {
+	log.Printf("Login request: email=%q", req.Email)
+
+	user, tokens, err := h.authenticator.Login(ctx, req.Email, req.Password)
+	if err != nil {
+		log.Printf("Login error: %v", err)
+
+		if err == ErrInvalidCredentials {
+			return nil, status.Error(codes.Unauthenticated, "invalid email or password")
+		}
+
+		return nil, h.tokenError(err, "failed to log in")
+	}
+
+	return &pb.LoginResponse{
+		User:   h.userToProto(user),
+		Tokens: h.tokensToProto(tokens),
+	}, nil
+}
+
+// RefreshToken trades a refresh token for new tokens
+func (h *Handler) RefreshToken(ctx context.Context, req *pb.RefreshTokenRequest) (*pb.RefreshTokenResponse, error) {
+	log.Printf("RefreshToken request")
+
+	tokens, err := h.authenticator.RefreshToken(ctx, req.RefreshToken)
+	if err != nil {
+		log.Printf("RefreshToken error: %v", err)
+
+		if err == ErrInvalidRefreshToken {
+			return nil, status.Error(codes.Unauthenticated, "invalid refresh token")
+		}
+
+		return nil, h.tokenError(err, "failed to refresh token")
+	}
+
+	return &pb.RefreshTokenResponse{
+		Tokens: h.tokensToProto(tokens),
+	}, nil
+}
+
+// Logout revokes a refresh token
+func (h *Handler) Logout(ctx context.Context, req *pb.LogoutRequest) (*pb.LogoutResponse, error) {
+	log.Printf("Logout request")
+
+	if err := h.authenticator.Logout(ctx, req.RefreshToken); err != nil {
+		log.Printf("Logout error: %v", err)
+
+		if err == ErrInvalidRefreshToken {
+			return nil, status.Error(codes.Unauthenticated, "invalid refresh token")
+		}
+
+		return nil, status.Error(codes.Internal, "failed to log out")
+	}
+
+	return &pb.LogoutResponse{}, nil
+}
+
+// tokenError maps the errors left over from issuing tokens to a status
+func (h *Handler) tokenError(err error, message string) error {
+	if err == ErrTokensUnavailable {
+		return status.Error(codes.FailedPrecondition, "the user service has no key to sign tokens with")
+	}
+	return status.Error(codes.Internal, message)
+}
+
+// tokensToProto converts issued tokens to protobuf
+func (h *Handler) tokensToProto(tokens *Tokens) *pb.AuthTokens {
+	return &pb.AuthTokens{
+		AccessToken:           tokens.AccessToken,
+		TokenType:             "Bearer",
+		AccessTokenExpiresAt:  timestamppb.New(tokens.AccessTokenExpiresAt),
+		RefreshToken:          tokens.RefreshToken,
+		RefreshTokenExpiresAt: timestamppb.New(tokens.RefreshTokenExpiresAt),
+	}
+}
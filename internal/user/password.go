@@ -0,0 +1,101 @@
+package user
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"sync"
+
+	"golang.org/x/crypto/argon2"
+)
+
+// Password length limits, in bytes; the upper one bounds hashing work
+const (
+	MinPasswordLength = 8
+	MaxPasswordLength = 128
+)
+
+// Argon2id parameters, the second recommended option of RFC 9106. They are
+// stored with every hash, so changing them leaves older hashes valid.
+const (
+	argonTime    = 3
+	argonMemory  = 64 * 1024 // KiB
+	argonThreads = 4
+	argonKeyLen  = 32
+	argonSaltLen = 16
+)
+
+// validatePassword checks a password against the length limits
+func validatePassword(password string) error {
+	if len(password) < MinPasswordLength {
+		return NewValidationError(fmt.Sprintf("password must be at least %d characters", MinPasswordLength))
+	}
+	if len(password) > MaxPasswordLength {
+		return NewValidationError(fmt.Sprintf("password must be at most %d characters", MaxPasswordLength))
+	}
+	return nil
+}
+
+// HashPassword hashes a password with argon2id and a random salt, encoded
+// in the PHC string format:
+//
+//	$argon2id$v=19$m=65536,t=3,p=4$<salt>$<hash>
+func HashPassword(password string) (string, error) {
+	salt := make([]byte, argonSaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("failed to generate salt: %w", err)
+	}
+
+	key := argon2.IDKey([]byte(password), salt, argonTime, argonMemory, argonThreads, argonKeyLen)
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, argonMemory, argonTime, argonThreads,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+// CheckPassword checks a password against a hash from HashPassword. Empty
+// and malformed hashes match no password.
+func CheckPassword(hash, password string) bool {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return false
+	}
+
+	var version int
+	var memory, time uint32
+	var threads uint8
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return false
+	}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &memory, &time, &threads); err != nil {
+		return false
+	}
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return false
+	}
+	want, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(want) == 0 {
+		return false
+	}
+
+	got := argon2.IDKey([]byte(password), salt, time, memory, threads, uint32(len(want)))
+	return subtle.ConstantTimeCompare(got, want) == 1
+}
+
+var (
+	dummyHashOnce sync.Once
+	dummyHash     string
+)
+
+// checkNoPassword spends the time checking a password takes, so logins for
+// unknown emails can't be told apart from wrong passwords by their timing
+func checkNoPassword(password string) {
+	dummyHashOnce.Do(func() {
+		dummyHash, _ = HashPassword("no password")
+	})
+	CheckPassword(dummyHash, password)
+}
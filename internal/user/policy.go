@@ -0,0 +1,22 @@
+package user
+
+import (
+	"learning/internal/auth"
+	pb "learning/pkg/user/pb"
+)
+
+// Policy says who may call each RPC of the user service. USERs may only
+// see and update their own user; the service enforces that itself. Logging
+// in and out is open to anyone.
+var Policy = auth.Policy{
+	pb.UserService_CreateUser_FullMethodName:    auth.Require(auth.RoleManager),
+	pb.UserService_GetUser_FullMethodName:       auth.Require(auth.RoleUser),
+	pb.UserService_BatchGetUsers_FullMethodName: auth.Require(auth.RoleUser),
+	pb.UserService_UpdateUser_FullMethodName:    auth.Require(auth.RoleUser),
+	pb.UserService_DeleteUser_FullMethodName:    auth.Require(auth.RoleAdmin),
+	pb.UserService_ListUsers_FullMethodName:     auth.Require(auth.RoleManager),
+	pb.UserService_Register_FullMethodName:      auth.Public,
+	pb.UserService_Login_FullMethodName:         auth.Public,
+	pb.UserService_RefreshToken_FullMethodName:  auth.Public,
+	pb.UserService_Logout_FullMethodName:        auth.Public,
+}
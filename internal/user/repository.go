@@ -3,10 +3,14 @@ package user
 import (
 	"context"
 	"errors"
+	"fmt"
 	"sync"
 	"time"
 
 	"github.com/google/uuid"
+
+	"learning/internal/auth"
+	"learning/internal/common"
 )
 
 var (
@@ -16,28 +20,83 @@ var (
 
 // User domain model
 type User struct {
-	ID        string
-	Name      string
-	Email     string
-	Phone     string
-	CreatedAt time.Time
-	UpdatedAt time.Time
+	ID    string
+	Name  string
+	Email string
+	Phone string
+	Role  auth.Role
+	// PasswordHash is the user's hashed password; empty for users that
+	// can't log in
+	PasswordHash string
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+// roles returns the roles the user's access tokens carry. Users stored
+// before roles existed are USERs.
+func (u *User) roles() []auth.Role {
+	if !u.Role.IsValid() {
+		return []auth.Role{auth.RoleUser}
+	}
+	return []auth.Role{u.Role}
+}
+
+// Cursor returns the user's position in lists
+func (u *User) Cursor() common.Cursor {
+	return common.Cursor{CreatedAt: u.CreatedAt, ID: u.ID}
+}
+
+// Fields of a user that an update can be limited to
+const (
+	FieldName  = "name"
+	FieldEmail = "email"
+	FieldPhone = "phone"
+)
+
+// UpdatableFields lists every field an update can be limited to
+var UpdatableFields = []string{FieldName, FieldEmail, FieldPhone}
+
+// applyFields copies the listed fields from src to dst
+func applyFields(dst, src *User, fields []string) error {
+	for _, field := range fields {
+		switch field {
+		case FieldName:
+			dst.Name = src.Name
+		case FieldEmail:
+			dst.Email = src.Email
+		case FieldPhone:
+			dst.Phone = src.Phone
+		default:
+			return unknownFieldError(field)
+		}
+	}
+	return nil
+}
+
+// unknownFieldError reports a field that updates can't be limited to
+func unknownFieldError(field string) error {
+	return NewValidationError(fmt.Sprintf("unknown field %q", field))
 }
 
 // Repository interface for user operations
 type Repository interface {
 	Create(ctx context.Context, user *User) (*User, error)
 	GetByID(ctx context.Context, id string) (*User, error)
+	GetByIDs(ctx context.Context, ids []string) ([]*User, []string, error)
 	GetByEmail(ctx context.Context, email string) (*User, error)
 	Update(ctx context.Context, user *User) (*User, error)
+	Patch(ctx context.Context, user *User, fields []string) (*User, error)
 	Delete(ctx context.Context, id string) error
-	List(ctx context.Context, offset, limit int) ([]*User, int, error)
+	List(ctx context.Context, query common.PageQuery) ([]*User, common.PageInfo, error)
 }
 
 // InMemoryRepository implements Repository interface using in-memory storage
 type InMemoryRepository struct {
 	users map[string]*User
 	mutex sync.RWMutex
+
+	// journal persists changes for file-backed repositories; nil otherwise
+	journal *common.Journal
 }
 
 // NewInMemoryRepository creates a new in-memory repository
@@ -70,7 +129,11 @@ func (r *InMemoryRepository) Create(ctx context.Context, user *User) (*User, err
 	user.UpdatedAt = now
 
 	// Store user
+	if err := r.persist(journalRecord{Op: opPut, User: user}); err != nil {
+		return nil, err
+	}
 	r.users[user.ID] = user
+	r.compact()
 
 	return user, nil
 }
@@ -102,6 +165,31 @@ func (r *InMemoryRepository) GetByEmail(ctx context.Context, email string) (*Use
 	return nil, ErrUserNotFound
 }
 
+// GetByIDs retrieves the users with the given IDs in the order the IDs were
+// requested, each at most once, along with the IDs that matched no user
+func (r *InMemoryRepository) GetByIDs(ctx context.Context, ids []string) ([]*User, []string, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	found := make([]*User, 0, len(ids))
+	var notFound []string
+	seen := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		if user, exists := r.users[id]; exists {
+			found = append(found, user)
+		} else {
+			notFound = append(notFound, id)
+		}
+	}
+
+	return found, notFound, nil
+}
+
 // Update updates an existing user
 func (r *InMemoryRepository) Update(ctx context.Context, user *User) (*User, error) {
 	r.mutex.Lock()
@@ -121,16 +209,58 @@ func (r *InMemoryRepository) Update(ctx context.Context, user *User) (*User, err
 		}
 	}
 
-	// Update fields
+	// Update fields; updates leave the role and password as they are
+	user.Role = existingUser.Role
+	user.PasswordHash = existingUser.PasswordHash
 	user.CreatedAt = existingUser.CreatedAt
 	user.UpdatedAt = time.Now()
 
 	// Store updated user
+	if err := r.persist(journalRecord{Op: opPut, User: user}); err != nil {
+		return nil, err
+	}
 	r.users[user.ID] = user
+	r.compact()
 
 	return user, nil
 }
 
+// Patch changes only the listed fields of a stored user to their values in user
+func (r *InMemoryRepository) Patch(ctx context.Context, user *User, fields []string) (*User, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	existingUser, exists := r.users[user.ID]
+	if !exists {
+		return nil, ErrUserNotFound
+	}
+
+	updatedUser := *existingUser
+	if err := applyFields(&updatedUser, user, fields); err != nil {
+		return nil, err
+	}
+
+	// Check if email is being changed and already exists
+	if updatedUser.Email != existingUser.Email {
+		for _, otherUser := range r.users {
+			if otherUser.ID != user.ID && otherUser.Email == updatedUser.Email {
+				return nil, ErrUserAlreadyExists
+			}
+		}
+	}
+
+	updatedUser.UpdatedAt = time.Now()
+
+	// Store updated user
+	if err := r.persist(journalRecord{Op: opPut, User: &updatedUser}); err != nil {
+		return nil, err
+	}
+	r.users[user.ID] = &updatedUser
+	r.compact()
+
+	return &updatedUser, nil
+}
+
 // Delete deletes a user by ID
 func (r *InMemoryRepository) Delete(ctx context.Context, id string) error {
 	r.mutex.Lock()
@@ -140,37 +270,26 @@ func (r *InMemoryRepository) Delete(ctx context.Context, id string) error {
 		return ErrUserNotFound
 	}
 
+	if err := r.persist(journalRecord{Op: opDelete, ID: id}); err != nil {
+		return err
+	}
 	delete(r.users, id)
+	r.compact()
+
 	return nil
 }
 
-// List retrieves users with pagination
-func (r *InMemoryRepository) List(ctx context.Context, offset, limit int) ([]*User, int, error) {
+// List retrieves users with pagination, oldest first
+func (r *InMemoryRepository) List(ctx context.Context, query common.PageQuery) ([]*User, common.PageInfo, error) {
 	r.mutex.RLock()
 	defer r.mutex.RUnlock()
 
 	// Convert map to slice
-	var users []*User
+	users := make([]*User, 0, len(r.users))
 	for _, user := range r.users {
 		users = append(users, user)
 	}
 
-	total := len(users)
-
-	// Apply pagination
-	start := offset
-	if start > total {
-		start = total
-	}
-
-	end := start + limit
-	if end > total {
-		end = total
-	}
-
-	if start >= total {
-		return []*User{}, total, nil
-	}
-
-	return users[start:end], total, nil
+	page, info := common.Paginate(users, (*User).Cursor, query)
+	return page, info, nil
 }
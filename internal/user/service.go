@@ -2,9 +2,17 @@ package user
 
 import (
 	"context"
+	"fmt"
+	"slices"
 	"strings"
+
+	"learning/internal/auth"
+	"learning/internal/common"
 )
 
+// MaxBatchGetIDs caps the number of users fetched by one batch lookup
+const MaxBatchGetIDs = 1000
+
 // Service handles business logic for user operations
 type Service struct {
 	repo Repository
@@ -19,31 +27,89 @@ func NewService(repo Repository) *Service {
 
 // CreateUser creates a new user with validation
 func (s *Service) CreateUser(ctx context.Context, name, email, phone string) (*User, error) {
+	return s.CreateAccount(ctx, name, email, phone, "", auth.RoleUser)
+}
+
+// CreateAccount creates a new user with the given role, USER when empty,
+// and a password to log in with unless password is empty. Callers can't
+// grant a role above their own.
+func (s *Service) CreateAccount(ctx context.Context, name, email, phone, password string, role auth.Role) (*User, error) {
 	// Validate input
 	if err := s.validateUser(name, email, phone); err != nil {
 		return nil, err
 	}
+	if role == "" {
+		role = auth.RoleUser
+	}
+	if !role.IsValid() {
+		return nil, NewValidationError(fmt.Sprintf("invalid role %q", role))
+	}
+	if claims, ok := auth.FromContext(ctx); ok && !claims.HasRole(role) {
+		return nil, auth.ErrPermissionDenied
+	}
 
 	user := &User{
 		Name:  strings.TrimSpace(name),
 		Email: strings.ToLower(strings.TrimSpace(email)),
 		Phone: strings.TrimSpace(phone),
+		Role:  role,
+	}
+
+	if password != "" {
+		if err := validatePassword(password); err != nil {
+			return nil, err
+		}
+		hash, err := HashPassword(password)
+		if err != nil {
+			return nil, err
+		}
+		user.PasswordHash = hash
 	}
 
 	return s.repo.Create(ctx, user)
 }
 
-// GetUser retrieves a user by ID
+// GetUser retrieves a user by ID. Callers below MANAGER only find their
+// own user.
 func (s *Service) GetUser(ctx context.Context, id string) (*User, error) {
-	if id == "" {
+	if id == "" || !auth.CanActFor(ctx, id, auth.RoleManager) {
 		return nil, ErrUserNotFound
 	}
 	return s.repo.GetByID(ctx, id)
 }
 
-// UpdateUser updates an existing user
+// BatchGetUsers retrieves several users by ID, in request order, along
+// with the IDs that matched no user. Callers below MANAGER only find their
+// own user.
+func (s *Service) BatchGetUsers(ctx context.Context, ids []string) ([]*User, []string, error) {
+	if len(ids) > MaxBatchGetIDs {
+		return nil, nil, NewValidationError(fmt.Sprintf("at most %d IDs are allowed", MaxBatchGetIDs))
+	}
+
+	self := auth.RestrictedTo(ctx, auth.RoleManager)
+	if self == "" {
+		return s.repo.GetByIDs(ctx, ids)
+	}
+
+	var visible, hidden []string
+	for _, id := range ids {
+		if id == self {
+			visible = append(visible, id)
+		} else {
+			hidden = append(hidden, id)
+		}
+	}
+	users, notFound, err := s.repo.GetByIDs(ctx, visible)
+	if err != nil {
+		return nil, nil, err
+	}
+	return users, append(notFound, hidden...), nil
+}
+
+// UpdateUser updates an existing user. Callers below MANAGER may only
+// update their own user.
 func (s *Service) UpdateUser(ctx context.Context, id, name, email, phone string) (*User, error) {
-	if id == "" {
+	if id == "" || !auth.CanActFor(ctx, id, auth.RoleManager) {
 		return nil, ErrUserNotFound
 	}
 
@@ -62,6 +128,33 @@ func (s *Service) UpdateUser(ctx context.Context, id, name, email, phone string)
 	return s.repo.Update(ctx, user)
 }
 
+// PatchUser updates only the listed fields of an existing user, leaving the
+// others as they are. Only the listed fields are validated.
+func (s *Service) PatchUser(ctx context.Context, id, name, email, phone string, fields []string) (*User, error) {
+	if id == "" || !auth.CanActFor(ctx, id, auth.RoleManager) {
+		return nil, ErrUserNotFound
+	}
+
+	fields, err := normalizeFields(fields)
+	if err != nil {
+		return nil, err
+	}
+
+	user := &User{
+		ID:    id,
+		Name:  strings.TrimSpace(name),
+		Email: strings.ToLower(strings.TrimSpace(email)),
+		Phone: strings.TrimSpace(phone),
+	}
+
+	// Validate input
+	if err := s.validateFields(user, fields); err != nil {
+		return nil, err
+	}
+
+	return s.repo.Patch(ctx, user, fields)
+}
+
 // DeleteUser deletes a user by ID
 func (s *Service) DeleteUser(ctx context.Context, id string) error {
 	if id == "" {
@@ -70,44 +163,65 @@ func (s *Service) DeleteUser(ctx context.Context, id string) error {
 	return s.repo.Delete(ctx, id)
 }
 
-// ListUsers retrieves users with pagination
-func (s *Service) ListUsers(ctx context.Context, page, pageSize int) ([]*User, int, error) {
+// ListUsers retrieves users with pagination, oldest first
+func (s *Service) ListUsers(ctx context.Context, query common.PageQuery) ([]*User, common.PageInfo, error) {
 	// Set default page size if not provided
-	if pageSize <= 0 {
-		pageSize = 10
-	}
-	if pageSize > 100 {
-		pageSize = 100 // Max page size
-	}
+	query.Limit = common.PageSize(query.Limit)
 
-	// Set default page if not provided
-	if page <= 0 {
-		page = 1
-	}
-
-	offset := (page - 1) * pageSize
-	return s.repo.List(ctx, offset, pageSize)
+	return s.repo.List(ctx, query)
 }
 
 // validateUser validates user input
 func (s *Service) validateUser(name, email, phone string) error {
-	if strings.TrimSpace(name) == "" {
-		return NewValidationError("name is required")
-	}
+	return s.validateFields(&User{Name: name, Email: email, Phone: phone}, UpdatableFields)
+}
 
-	if strings.TrimSpace(email) == "" {
-		return NewValidationError("email is required")
+// validateFields validates the listed fields of user input
+func (s *Service) validateFields(user *User, fields []string) error {
+	for _, field := range fields {
+		switch field {
+		case FieldName:
+			if strings.TrimSpace(user.Name) == "" {
+				return NewValidationError("name is required")
+			}
+		case FieldEmail:
+			if strings.TrimSpace(user.Email) == "" {
+				return NewValidationError("email is required")
+			}
+			if !isValidEmail(user.Email) {
+				return NewValidationError("invalid email format")
+			}
+		case FieldPhone:
+			if strings.TrimSpace(user.Phone) == "" {
+				return NewValidationError("phone is required")
+			}
+		default:
+			return unknownFieldError(field)
+		}
 	}
 
-	if !isValidEmail(email) {
-		return NewValidationError("invalid email format")
-	}
+	return nil
+}
 
-	if strings.TrimSpace(phone) == "" {
-		return NewValidationError("phone is required")
+// normalizeFields checks that fields lists at least one field, all known, and
+// drops repeats
+func normalizeFields(fields []string) ([]string, error) {
+	if len(fields) == 0 {
+		return nil, NewValidationError("at least one field to update is required")
 	}
 
-	return nil
+	seen := make(map[string]bool, len(fields))
+	normalized := make([]string, 0, len(fields))
+	for _, field := range fields {
+		if !slices.Contains(UpdatableFields, field) {
+			return nil, unknownFieldError(field)
+		}
+		if !seen[field] {
+			seen[field] = true
+			normalized = append(normalized, field)
+		}
+	}
+	return normalized, nil
 }
 
 // isValidEmail performs basic email validation
@@ -0,0 +1,218 @@
+package user
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"learning/internal/common"
+)
+
+// ErrSessionNotFound is returned for sessions that don't exist, or whose
+// refresh token was replaced in the meantime
+var ErrSessionNotFound = errors.New("session not found")
+
+// Session is a login, kept alive by trading its refresh token for a new one.
+// Only a hash of the current refresh token is stored.
+type Session struct {
+	ID        string
+	UserID    string
+	TokenHash string
+	ExpiresAt time.Time
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// SessionStore persists login sessions
+type SessionStore interface {
+	// Create stores a new session, dropping expired ones along the way
+	Create(ctx context.Context, session *Session) error
+	Get(ctx context.Context, id string) (*Session, error)
+	// Rotate replaces the token hash of a session and extends it, provided
+	// its token hash is still tokenHash
+	Rotate(ctx context.Context, id, tokenHash, newTokenHash string, expiresAt time.Time) error
+	// Delete removes a session; deleting an unknown session is not an error
+	Delete(ctx context.Context, id string) error
+}
+
+// InMemorySessionStore implements SessionStore using in-memory storage
+type InMemorySessionStore struct {
+	sessions map[string]*Session
+	mutex    sync.Mutex
+
+	// journal persists changes for file-backed stores; nil otherwise
+	journal *common.Journal
+}
+
+// sessionRecord is a single logged change to the session set.
+// Records carry the whole session so replaying one twice is harmless.
+type sessionRecord struct {
+	Op      string   `json:"op"`
+	Session *Session `json:"session,omitempty"`
+	ID      string   `json:"id,omitempty"`
+}
+
+// NewInMemorySessionStore creates a new in-memory session store
+func NewInMemorySessionStore() *InMemorySessionStore {
+	return &InMemorySessionStore{
+		sessions: make(map[string]*Session),
+	}
+}
+
+// NewFileBackedSessionStore creates an in-memory session store whose
+// contents are persisted to a snapshot and append-only log inside dataDir
+func NewFileBackedSessionStore(dataDir string) (*InMemorySessionStore, error) {
+	journal, err := common.OpenJournal(dataDir, "sessions", common.DefaultCompactEvery)
+	if err != nil {
+		return nil, err
+	}
+
+	store := NewInMemorySessionStore()
+	err = journal.Replay(
+		func(data []byte) error {
+			return json.Unmarshal(data, &store.sessions)
+		},
+		func(data []byte) error {
+			var record sessionRecord
+			if err := json.Unmarshal(data, &record); err != nil {
+				return err
+			}
+			switch record.Op {
+			case opPut:
+				if record.Session == nil {
+					return fmt.Errorf("put record without session")
+				}
+				store.sessions[record.Session.ID] = record.Session
+			case opDelete:
+				delete(store.sessions, record.ID)
+			default:
+				return fmt.Errorf("unknown journal op %q", record.Op)
+			}
+			return nil
+		},
+	)
+	if err != nil {
+		journal.Close()
+		return nil, err
+	}
+
+	store.journal = journal
+	return store, nil
+}
+
+// Close closes the journal of a file-backed store
+func (s *InMemorySessionStore) Close() error {
+	if s.journal == nil {
+		return nil
+	}
+	return s.journal.Close()
+}
+
+// Create stores a copy of a new session
+func (s *InMemorySessionStore) Create(ctx context.Context, session *Session) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := time.Now()
+	for id, existing := range s.sessions {
+		if !now.Before(existing.ExpiresAt) {
+			if err := s.persist(sessionRecord{Op: opDelete, ID: id}); err != nil {
+				return err
+			}
+			delete(s.sessions, id)
+		}
+	}
+
+	stored := *session
+	if err := s.persist(sessionRecord{Op: opPut, Session: &stored}); err != nil {
+		return err
+	}
+	s.sessions[stored.ID] = &stored
+	s.compact()
+
+	return nil
+}
+
+// Get returns a copy of a session
+func (s *InMemorySessionStore) Get(ctx context.Context, id string) (*Session, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	session, exists := s.sessions[id]
+	if !exists {
+		return nil, ErrSessionNotFound
+	}
+	found := *session
+	return &found, nil
+}
+
+// Rotate replaces the token hash of a session and extends it
+func (s *InMemorySessionStore) Rotate(ctx context.Context, id, tokenHash, newTokenHash string, expiresAt time.Time) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	session, exists := s.sessions[id]
+	if !exists || session.TokenHash != tokenHash {
+		return ErrSessionNotFound
+	}
+
+	rotated := *session
+	rotated.TokenHash = newTokenHash
+	rotated.ExpiresAt = expiresAt
+	rotated.UpdatedAt = time.Now()
+	if err := s.persist(sessionRecord{Op: opPut, Session: &rotated}); err != nil {
+		return err
+	}
+	s.sessions[id] = &rotated
+	s.compact()
+
+	return nil
+}
+
+// Delete removes a session
+func (s *InMemorySessionStore) Delete(ctx context.Context, id string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, exists := s.sessions[id]; !exists {
+		return nil
+	}
+
+	if err := s.persist(sessionRecord{Op: opDelete, ID: id}); err != nil {
+		return err
+	}
+	delete(s.sessions, id)
+	s.compact()
+
+	return nil
+}
+
+// persist logs a change before it is applied to the map.
+// The caller must hold the lock.
+func (s *InMemorySessionStore) persist(record sessionRecord) error {
+	if s.journal == nil {
+		return nil
+	}
+
+	if err := s.journal.Append(record); err != nil {
+		return fmt.Errorf("failed to persist session: %w", err)
+	}
+	return nil
+}
+
+// compact writes a fresh snapshot once the log is long enough.
+// The caller must hold the lock and have applied the latest change.
+func (s *InMemorySessionStore) compact() {
+	if s.journal == nil || !s.journal.NeedsCompaction() {
+		return
+	}
+
+	if err := s.journal.Compact(s.sessions); err != nil {
+		// The log still holds every change, so this only delays compaction
+		log.Printf("Failed to compact session journal: %v", err)
+	}
+}
@@ -0,0 +1,223 @@
+package user
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"learning/internal/common"
+)
+
+const userColumns = "id, name, email, phone, role, password_hash, created_at, updated_at"
+
+// SQLRepository implements Repository interface using a SQL database
+type SQLRepository struct {
+	db *common.Database
+}
+
+// NewSQLRepository creates a new SQL repository.
+// The schema is managed by the migrations package.
+func NewSQLRepository(db *common.Database) *SQLRepository {
+	return &SQLRepository{db: db}
+}
+
+// Create creates a new user
+func (r *SQLRepository) Create(ctx context.Context, user *User) (*User, error) {
+	// Generate ID if not provided
+	if user.ID == "" {
+		user.ID = uuid.New().String()
+	}
+
+	// Set timestamps
+	now := time.Now().UTC()
+	user.CreatedAt = now
+	user.UpdatedAt = now
+
+	_, err := r.db.ExecContext(ctx, r.db.Rebind(
+		"INSERT INTO users ("+userColumns+") VALUES (?, ?, ?, ?, ?, ?, ?, ?)"),
+		user.ID, user.Name, user.Email, user.Phone, user.Role, user.PasswordHash, user.CreatedAt, user.UpdatedAt,
+	)
+	if err != nil {
+		if common.IsUniqueViolation(err) {
+			return nil, ErrUserAlreadyExists
+		}
+		return nil, fmt.Errorf("failed to insert user: %w", err)
+	}
+
+	return user, nil
+}
+
+// GetByID retrieves a user by ID
+func (r *SQLRepository) GetByID(ctx context.Context, id string) (*User, error) {
+	row := r.db.QueryRowContext(ctx, r.db.Rebind(
+		"SELECT "+userColumns+" FROM users WHERE id = ?"), id)
+	return scanUser(row)
+}
+
+// GetByIDs retrieves the users with the given IDs in the order the IDs were
+// requested, each at most once, along with the IDs that matched no user
+func (r *SQLRepository) GetByIDs(ctx context.Context, ids []string) ([]*User, []string, error) {
+	if len(ids) == 0 {
+		return []*User{}, nil, nil
+	}
+
+	args := make([]any, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+
+	rows, err := r.db.QueryContext(ctx, r.db.Rebind(
+		"SELECT "+userColumns+" FROM users WHERE id IN ("+common.Placeholders(len(ids))+")"),
+		args...,
+	)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get users: %w", err)
+	}
+	defer rows.Close()
+
+	byID := make(map[string]*User, len(ids))
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, nil, err
+		}
+		byID[user.ID] = user
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("failed to get users: %w", err)
+	}
+
+	// Rows come back in no particular order; restore the requested one
+	found := make([]*User, 0, len(byID))
+	var notFound []string
+	seen := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		if user, exists := byID[id]; exists {
+			found = append(found, user)
+		} else {
+			notFound = append(notFound, id)
+		}
+	}
+
+	return found, notFound, nil
+}
+
+// GetByEmail retrieves a user by email
+func (r *SQLRepository) GetByEmail(ctx context.Context, email string) (*User, error) {
+	row := r.db.QueryRowContext(ctx, r.db.Rebind(
+		"SELECT "+userColumns+" FROM users WHERE email = ?"), email)
+	return scanUser(row)
+}
+
+// Update updates an existing user
+func (r *SQLRepository) Update(ctx context.Context, user *User) (*User, error) {
+	user.UpdatedAt = time.Now().UTC()
+
+	row := r.db.QueryRowContext(ctx, r.db.Rebind(
+		"UPDATE users SET name = ?, email = ?, phone = ?, updated_at = ? WHERE id = ? RETURNING "+userColumns),
+		user.Name, user.Email, user.Phone, user.UpdatedAt, user.ID,
+	)
+
+	updated, err := scanUser(row)
+	if err != nil {
+		if common.IsUniqueViolation(err) {
+			return nil, ErrUserAlreadyExists
+		}
+		return nil, err
+	}
+
+	return updated, nil
+}
+
+// Patch changes only the listed fields of a stored user to their values in user
+func (r *SQLRepository) Patch(ctx context.Context, user *User, fields []string) (*User, error) {
+	var set []string
+	var args []any
+	for _, field := range fields {
+		switch field {
+		case FieldName:
+			args = append(args, user.Name)
+		case FieldEmail:
+			args = append(args, user.Email)
+		case FieldPhone:
+			args = append(args, user.Phone)
+		default:
+			return nil, unknownFieldError(field)
+		}
+		set = append(set, field+" = ?")
+	}
+
+	row := r.db.QueryRowContext(ctx, r.db.Rebind(
+		"UPDATE users SET "+strings.Join(append(set, "updated_at = ?"), ", ")+" WHERE id = ? RETURNING "+userColumns),
+		append(args, time.Now().UTC(), user.ID)...,
+	)
+
+	updated, err := scanUser(row)
+	if err != nil {
+		if common.IsUniqueViolation(err) {
+			return nil, ErrUserAlreadyExists
+		}
+		return nil, err
+	}
+
+	return updated, nil
+}
+
+// Delete deletes a user by ID
+func (r *SQLRepository) Delete(ctx context.Context, id string) error {
+	result, err := r.db.ExecContext(ctx, r.db.Rebind("DELETE FROM users WHERE id = ?"), id)
+	if err != nil {
+		return fmt.Errorf("failed to delete user: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to delete user: %w", err)
+	}
+	if affected == 0 {
+		return ErrUserNotFound
+	}
+
+	return nil
+}
+
+// List retrieves users with pagination, oldest first
+func (r *SQLRepository) List(ctx context.Context, query common.PageQuery) ([]*User, common.PageInfo, error) {
+	users := []*User{}
+	info, err := r.db.ListPage(ctx, "users", userColumns, "", nil, query, func(rows *sql.Rows) error {
+		user, err := scanUser(rows)
+		if err != nil {
+			return err
+		}
+		users = append(users, user)
+		return nil
+	})
+	if err != nil {
+		return nil, common.PageInfo{}, err
+	}
+
+	return users, info, nil
+}
+
+// scanUser reads a user from a row selected with userColumns
+func scanUser(row interface{ Scan(...any) error }) (*User, error) {
+	var user User
+	err := row.Scan(&user.ID, &user.Name, &user.Email, &user.Phone, &user.Role, &user.PasswordHash, &user.CreatedAt, &user.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	return &user, nil
+}
@@ -0,0 +1,83 @@
+package user
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"learning/internal/common"
+)
+
+// SQLSessionStore implements SessionStore using a SQL database
+type SQLSessionStore struct {
+	db *common.Database
+}
+
+// NewSQLSessionStore creates a new SQL session store.
+// The schema is managed by the migrations package.
+func NewSQLSessionStore(db *common.Database) *SQLSessionStore {
+	return &SQLSessionStore{db: db}
+}
+
+// Create stores a new session
+func (s *SQLSessionStore) Create(ctx context.Context, session *Session) error {
+	if _, err := s.db.ExecContext(ctx, s.db.Rebind(
+		"DELETE FROM user_sessions WHERE expires_at <= ?"), time.Now().UTC()); err != nil {
+		return fmt.Errorf("failed to delete expired sessions: %w", err)
+	}
+
+	_, err := s.db.ExecContext(ctx, s.db.Rebind(
+		"INSERT INTO user_sessions (id, user_id, token_hash, expires_at, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?)"),
+		session.ID, session.UserID, session.TokenHash,
+		session.ExpiresAt.UTC(), session.CreatedAt.UTC(), session.UpdatedAt.UTC(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert session: %w", err)
+	}
+	return nil
+}
+
+// Get retrieves a session by ID
+func (s *SQLSessionStore) Get(ctx context.Context, id string) (*Session, error) {
+	var session Session
+	err := s.db.QueryRowContext(ctx, s.db.Rebind(
+		"SELECT id, user_id, token_hash, expires_at, created_at, updated_at FROM user_sessions WHERE id = ?"), id,
+	).Scan(&session.ID, &session.UserID, &session.TokenHash, &session.ExpiresAt, &session.CreatedAt, &session.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrSessionNotFound
+		}
+		return nil, fmt.Errorf("failed to get session: %w", err)
+	}
+	return &session, nil
+}
+
+// Rotate replaces the token hash of a session and extends it
+func (s *SQLSessionStore) Rotate(ctx context.Context, id, tokenHash, newTokenHash string, expiresAt time.Time) error {
+	result, err := s.db.ExecContext(ctx, s.db.Rebind(
+		"UPDATE user_sessions SET token_hash = ?, expires_at = ?, updated_at = ? WHERE id = ? AND token_hash = ?"),
+		newTokenHash, expiresAt.UTC(), time.Now().UTC(), id, tokenHash,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to rotate session: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to rotate session: %w", err)
+	}
+	if affected == 0 {
+		return ErrSessionNotFound
+	}
+	return nil
+}
+
+// Delete removes a session
+func (s *SQLSessionStore) Delete(ctx context.Context, id string) error {
+	if _, err := s.db.ExecContext(ctx, s.db.Rebind("DELETE FROM user_sessions WHERE id = ?"), id); err != nil {
+		return fmt.Errorf("failed to delete session: %w", err)
+	}
+	return nil
+}